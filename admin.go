@@ -0,0 +1,178 @@
+package ginx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/middleware"
+	"github.com/gaoxin19/ginx/upgrader"
+)
+
+// setupAdmin 配置了 Admin 时创建独立的管理服务并随引擎启动与关闭，
+// 返回挂载管理接口的路由，未配置时返回业务路由
+func (e *Engine) setupAdmin() (gin.IRouter, error) {
+	conf := e.options.Admin
+	if conf == nil || conf.Addr == "" {
+		return e.Engine, nil
+	}
+
+	handlers, err := e.accessControl("admin", conf.AllowIPs, conf.Users)
+	if err != nil {
+		return nil, err
+	}
+	r := gin.New()
+	// 管理端口不经过反向代理，不采信任何转发头，避免伪造 X-Forwarded-For 绕过 AllowIPs
+	if err := r.SetTrustedProxies(nil); err != nil {
+		return nil, fmt.Errorf("failed to set admin trusted proxies: %w", err)
+	}
+	r.Use(middleware.Recovery(e.logger))
+	r.Use(handlers...)
+
+	e.admin = r
+	registerRuntime(r.Group("/runtime"), conf.DumpDir)
+	r.GET("/status", func(c *gin.Context) {
+		c.JSON(http.StatusOK, e.status())
+	})
+	r.GET("/config", func(c *gin.Context) {
+		c.JSON(http.StatusOK, e.opts().Dump())
+	})
+	r.GET("/admin/routes", func(c *gin.Context) {
+		c.JSON(http.StatusOK, e.RoutesInfo())
+	})
+	if len(conf.Users) > 0 {
+		e.registerControl(r.Group("/admin"))
+	} else {
+		e.logger.Warn("Admin upgrade and shutdown endpoints are disabled because no admin users are configured")
+	}
+	e.AddServer("admin", conf.Addr, &http.Server{
+		Handler:     r,
+		ReadTimeout: e.options.ReadTimeout,
+	})
+	return r, nil
+}
+
+// Admin 返回管理端口的路由，可用于注册自定义管理接口；未配置 Options.Admin 时返回 nil
+func (e *Engine) Admin() *gin.Engine {
+	return e.admin
+}
+
+// accessControl 按 IP 白名单与 Basic 认证配置生成访问控制中间件
+func (e *Engine) accessControl(realm string, allowIPs []string, users map[string]string) ([]gin.HandlerFunc, error) {
+	var handlers []gin.HandlerFunc
+	if len(allowIPs) > 0 {
+		filter, err := middleware.NewIPFilter(middleware.IPFilterConfig{Allow: allowIPs, Logger: e.logger})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s ip filter: %w", realm, err)
+		}
+		handlers = append(handlers, filter.Middleware())
+	}
+	if len(users) > 0 {
+		handlers = append(handlers, middleware.BasicAuth(middleware.BasicAuthConfig{
+			Realm:    realm,
+			Provider: middleware.BcryptCredentials(users),
+			Logger:   e.logger,
+		}))
+	}
+	return handlers, nil
+}
+
+// ErrUpgradeUnsupported 当前运行方式不支持平滑升级
+var ErrUpgradeUnsupported = errors.New("upgrade is not supported by the current run mode")
+
+// setControl 记录当前生命周期的关闭、升级、UDP 监听与进程交接入口
+func (e *Engine) setControl(stop context.CancelFunc, opts serveOptions) {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	e.stop, e.upgrade, e.upgradeBinary = stop, opts.upgrade, opts.upgradeBinary
+	e.listenPacket, e.handoff = opts.listenPacket, opts.handoff
+}
+
+// Upgrade 触发一次平滑升级，与收到 SIGHUP 的效果相同；服务未运行或以 GracefulServe 运行时返回 ErrUpgradeUnsupported
+func (e *Engine) Upgrade() error {
+	e.hooksMu.Lock()
+	upgrade := e.upgrade
+	e.hooksMu.Unlock()
+	if upgrade == nil {
+		return ErrUpgradeUnsupported
+	}
+	return upgrade()
+}
+
+// UpgradeTo 校验 path 指定的可执行文件（checksum 为其 SHA-256，必填）后升级到该文件，
+// 用于新版本下载到旧版本旁边的部署方式
+func (e *Engine) UpgradeTo(path, checksum string) error {
+	e.hooksMu.Lock()
+	upgrade := e.upgradeBinary
+	e.hooksMu.Unlock()
+	if upgrade == nil {
+		return ErrUpgradeUnsupported
+	}
+	if err := upgrader.VerifyBinary(path, checksum); err != nil {
+		return err
+	}
+	e.logger.Info("Upgrading into new binary", zap.String("path", path))
+	return upgrade(path)
+}
+
+// Shutdown 触发优雅关闭，与收到 SIGTERM 的效果相同，服务未运行时为空操作
+func (e *Engine) Shutdown() {
+	e.hooksMu.Lock()
+	stop := e.stop
+	e.hooksMu.Unlock()
+	if stop != nil {
+		stop()
+	}
+}
+
+// registerControl 注册升级与关闭接口，仅在管理端口启用了认证时注册
+func (e *Engine) registerControl(g *gin.RouterGroup) {
+	g.POST("/upgrade", func(c *gin.Context) {
+		// 请求体可选，指定 binary 时升级到该可执行文件
+		var req struct {
+			Binary string `json:"binary"`
+			SHA256 string `json:"sha256"`
+		}
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		e.logger.Info("Upgrade requested via admin endpoint",
+			zap.String("client_ip", middleware.ClientIP(c)),
+			zap.String("binary", req.Binary),
+		)
+		var err error
+		if req.Binary != "" {
+			err = e.UpgradeTo(req.Binary, req.SHA256)
+		} else {
+			err = e.Upgrade()
+		}
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrUpgradeUnsupported) || errors.Is(err, upgrader.ErrBinaryUnsupported) {
+				status = http.StatusNotImplemented
+			} else if errors.Is(err, upgrader.ErrChecksumRequired) {
+				status = http.StatusBadRequest
+			} else if errors.Is(err, upgrader.ErrUpgradeInProgress) {
+				status = http.StatusConflict
+			}
+			e.logger.Error("Upgrade failed", zap.Error(err))
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "upgraded", "pid": os.Getpid()})
+	})
+	g.POST("/shutdown", func(c *gin.Context) {
+		e.logger.Info("Shutdown requested via admin endpoint", zap.String("client_ip", middleware.ClientIP(c)))
+		c.JSON(http.StatusAccepted, gin.H{"status": "shutting down", "pid": os.Getpid()})
+		e.Shutdown()
+	})
+}
@@ -0,0 +1,36 @@
+package ginx
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gaoxin19/ginx/config"
+)
+
+func TestAdminRoutes(t *testing.T) {
+	e := newTestEngine(t, func(o *config.Options) {
+		o.Admin = &config.AdminOptions{Addr: "127.0.0.1:0", AllowIPs: []string{"127.0.0.1"}}
+	})
+	e.GET("/users/:id", func(c *gin.Context) {})
+
+	if w := serveFrom(e.Admin(), http.MethodGet, "/admin/routes", "203.0.113.5:4000"); w.Code != http.StatusForbidden {
+		t.Fatalf("remote status = %d, want 403", w.Code)
+	}
+	w := serveFrom(e.Admin(), http.MethodGet, "/admin/routes", "127.0.0.1:4000")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var routes []RouteInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &routes); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range routes {
+		if r.Method == http.MethodGet && r.Path == "/users/:id" {
+			return
+		}
+	}
+	t.Fatalf("GET /users/:id missing from %s", w.Body.String())
+}
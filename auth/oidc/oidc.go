@@ -0,0 +1,268 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+)
+
+// SessionKey 登录会话在 gin.Context 中的键
+const SessionKey = "oidc_session"
+
+// Config OIDC 登录配置
+type Config struct {
+	// Issuer 身份提供方地址，用于服务发现（/.well-known/openid-configuration）
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL 回调地址的完整 URL，路径需与 CallbackPath 一致
+	RedirectURL string
+	// Scopes 额外申请的 scope，openid 总是包含在内
+	Scopes []string
+
+	// CookieSecret 会话 cookie 的 HMAC 签名密钥，至少 32 字节
+	CookieSecret []byte
+	// CookieName 会话 cookie 名称，默认 ginx_oidc
+	CookieName string
+	// CookieMaxAge 会话有效期，默认 8 小时
+	CookieMaxAge time.Duration
+	// Secure 是否仅通过 HTTPS 发送 cookie
+	Secure bool
+
+	// LoginPath、CallbackPath、LogoutPath 注册的路由，默认 /auth/login、/auth/callback、/auth/logout
+	LoginPath    string
+	CallbackPath string
+	LogoutPath   string
+}
+
+// Session 登录会话
+type Session struct {
+	Subject string    `json:"sub"`
+	Email   string    `json:"email,omitempty"`
+	Name    string    `json:"name,omitempty"`
+	Expiry  time.Time `json:"exp"`
+}
+
+// loginState 授权请求期间保存在 cookie 中的状态
+type loginState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Redirect string `json:"redirect"`
+}
+
+// Provider OIDC 授权码登录
+type Provider struct {
+	conf     Config
+	oauth2   oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+// New 通过服务发现创建 Provider
+func New(ctx context.Context, conf Config) (*Provider, error) {
+	if len(conf.CookieSecret) < 32 {
+		return nil, errors.New("oidc: CookieSecret must be at least 32 bytes")
+	}
+	if conf.CookieName == "" {
+		conf.CookieName = "ginx_oidc"
+	}
+	if conf.CookieMaxAge <= 0 {
+		conf.CookieMaxAge = 8 * time.Hour
+	}
+	if conf.LoginPath == "" {
+		conf.LoginPath = "/auth/login"
+	}
+	if conf.CallbackPath == "" {
+		conf.CallbackPath = "/auth/callback"
+	}
+	if conf.LogoutPath == "" {
+		conf.LogoutPath = "/auth/logout"
+	}
+
+	provider, err := gooidc.NewProvider(ctx, conf.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover oidc provider: %w", err)
+	}
+
+	return &Provider{
+		conf: conf,
+		oauth2: oauth2.Config{
+			ClientID:     conf.ClientID,
+			ClientSecret: conf.ClientSecret,
+			RedirectURL:  conf.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       append([]string{gooidc.ScopeOpenID}, conf.Scopes...),
+		},
+		verifier: provider.Verifier(&gooidc.Config{ClientID: conf.ClientID}),
+	}, nil
+}
+
+// Register 在路由上注册登录、回调和登出路由，通常传入 *ginx.Engine
+func (p *Provider) Register(r gin.IRouter) {
+	r.GET(p.conf.LoginPath, p.login)
+	r.GET(p.conf.CallbackPath, p.callback)
+	r.GET(p.conf.LogoutPath, p.logout)
+}
+
+// Require 返回要求登录的中间件，未登录时重定向到登录页（非 GET 请求返回 401）
+func (p *Provider) Require() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if sess := p.session(c); sess != nil {
+			c.Set(SessionKey, sess)
+			c.Next()
+			return
+		}
+
+		if c.Request.Method != http.MethodGet {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Redirect(http.StatusFound, p.conf.LoginPath+"?redirect="+url.QueryEscape(c.Request.URL.RequestURI()))
+		c.Abort()
+	}
+}
+
+// GetSession 获取当前请求的登录会话，需挂载在 Require 之后
+func GetSession(c *gin.Context) *Session {
+	if v, ok := c.Get(SessionKey); ok {
+		if sess, ok := v.(*Session); ok {
+			return sess
+		}
+	}
+	return nil
+}
+
+func (p *Provider) session(c *gin.Context) *Session {
+	value, err := c.Cookie(p.conf.CookieName)
+	if err != nil {
+		return nil
+	}
+	var sess Session
+	if err := verifyValue(p.conf.CookieSecret, value, &sess); err != nil {
+		return nil
+	}
+	if time.Now().After(sess.Expiry) {
+		return nil
+	}
+	return &sess
+}
+
+// isLocalRedirect 只允许站内绝对路径，防止开放重定向；浏览器会把反斜杠视为 / 并忽略制表与换行符，因此一并拒绝
+func isLocalRedirect(target string) bool {
+	if strings.ContainsAny(target, "\\\t\r\n") {
+		return false
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.User != nil {
+		return false
+	}
+	return strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "//")
+}
+
+func (p *Provider) login(c *gin.Context) {
+	redirect := c.Query("redirect")
+	if !isLocalRedirect(redirect) {
+		redirect = "/"
+	}
+
+	st := loginState{State: randomString(), Nonce: randomString(), Redirect: redirect}
+	value, err := signValue(p.conf.CookieSecret, st)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	p.setCookie(c, p.conf.CookieName+"_state", value, 10*time.Minute)
+
+	c.Redirect(http.StatusFound, p.oauth2.AuthCodeURL(st.State, gooidc.Nonce(st.Nonce)))
+}
+
+func (p *Provider) callback(c *gin.Context) {
+	raw, err := c.Cookie(p.conf.CookieName + "_state")
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	p.setCookie(c, p.conf.CookieName+"_state", "", -1)
+
+	var st loginState
+	if err := verifyValue(p.conf.CookieSecret, raw, &st); err != nil || st.State != c.Query("state") {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	if errCode := c.Query("error"); errCode != "" {
+		c.AbortWithError(http.StatusUnauthorized, fmt.Errorf("oidc: %s: %s", errCode, c.Query("error_description")))
+		return
+	}
+
+	ctx := c.Request.Context()
+	token, err := p.oauth2.Exchange(ctx, c.Query("code"))
+	if err != nil {
+		c.AbortWithError(http.StatusUnauthorized, fmt.Errorf("oidc: failed to exchange code: %w", err))
+		return
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		c.AbortWithError(http.StatusUnauthorized, errors.New("oidc: id_token missing from token response"))
+		return
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		c.AbortWithError(http.StatusUnauthorized, fmt.Errorf("oidc: failed to verify id_token: %w", err))
+		return
+	}
+	if idToken.Nonce != st.Nonce {
+		c.AbortWithError(http.StatusUnauthorized, errors.New("oidc: nonce mismatch"))
+		return
+	}
+
+	var claims struct {
+		Email string `json:"email"`
+		Name  string `json:"name"`
+	}
+	_ = idToken.Claims(&claims)
+
+	sess := Session{
+		Subject: idToken.Subject,
+		Email:   claims.Email,
+		Name:    claims.Name,
+		Expiry:  time.Now().Add(p.conf.CookieMaxAge),
+	}
+	value, err := signValue(p.conf.CookieSecret, sess)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	p.setCookie(c, p.conf.CookieName, value, p.conf.CookieMaxAge)
+
+	c.Redirect(http.StatusFound, st.Redirect)
+}
+
+func (p *Provider) logout(c *gin.Context) {
+	p.setCookie(c, p.conf.CookieName, "", -1)
+	c.Redirect(http.StatusFound, "/")
+}
+
+// setCookie 写入 cookie，maxAge 小于 0 时删除
+func (p *Provider) setCookie(c *gin.Context, name, value string, maxAge time.Duration) {
+	seconds := int(maxAge / time.Second)
+	if maxAge < 0 {
+		seconds = -1
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(name, value, seconds, "/", "", p.conf.Secure, true)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,300 @@
+// Package breaker 提供基于错误率与慢调用率的熔断器，可用于路由中间件与出站 HTTP 客户端
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gaoxin19/ginx/metrics"
+)
+
+// ErrOpen 熔断器处于打开状态，请求被拒绝
+var ErrOpen = errors.New("breaker: circuit open")
+
+// State 熔断器状态
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half_open"
+	}
+	return "unknown"
+}
+
+// Options 熔断器配置
+type Options struct {
+	// Window 统计窗口，默认 10s
+	Window time.Duration
+	// Buckets 窗口划分的桶数，默认 10
+	Buckets int
+	// MinRequests 窗口内请求数达到该值才会判断是否熔断，默认 20
+	MinRequests int
+	// ErrorRate 触发熔断的错误率，默认 0.5
+	ErrorRate float64
+	// SlowThreshold 超过该耗时的调用视为慢调用，为 0 时不统计
+	SlowThreshold time.Duration
+	// SlowRate 触发熔断的慢调用率，默认 0.5
+	SlowRate float64
+	// OpenTimeout 打开后等待多久进入半开状态，默认 30s
+	OpenTimeout time.Duration
+	// HalfOpenRequests 半开状态允许的探测请求数，全部成功后关闭，默认 1
+	HalfOpenRequests int
+	// OnStateChange 状态变化回调
+	OnStateChange func(name string, from, to State)
+}
+
+func (o *Options) setDefaults() {
+	if o.Window <= 0 {
+		o.Window = 10 * time.Second
+	}
+	if o.Buckets <= 0 {
+		o.Buckets = 10
+	}
+	if o.MinRequests <= 0 {
+		o.MinRequests = 20
+	}
+	if o.ErrorRate <= 0 {
+		o.ErrorRate = 0.5
+	}
+	if o.SlowRate <= 0 {
+		o.SlowRate = 0.5
+	}
+	if o.OpenTimeout <= 0 {
+		o.OpenTimeout = 30 * time.Second
+	}
+	if o.HalfOpenRequests <= 0 {
+		o.HalfOpenRequests = 1
+	}
+}
+
+type bucket struct {
+	start    time.Time
+	total    int
+	failures int
+	slow     int
+}
+
+// Breaker 熔断器
+type Breaker struct {
+	name string
+	opts Options
+
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	openedAt   time.Time
+	buckets    []bucket
+	probes     int
+	successes  int
+	// changes 锁内产生的状态变化，释放锁后再回调，避免回调中访问熔断器时死锁
+	changes []stateChange
+}
+
+type stateChange struct {
+	from, to State
+}
+
+// New 创建熔断器，name 用于指标与回调
+func New(name string, opts Options) *Breaker {
+	opts.setDefaults()
+	b := &Breaker{
+		name:    name,
+		opts:    opts,
+		buckets: make([]bucket, opts.Buckets),
+	}
+	metrics.BreakerState.WithLabelValues(name).Set(float64(Closed))
+	return b
+}
+
+// Name 返回熔断器名称
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State 返回当前状态
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	b.refresh(time.Now())
+	state := b.state
+	b.unlock()
+	return state
+}
+
+// Done 报告调用结果，failed 表示调用失败
+type Done func(failed bool)
+
+// Allow 判断是否允许请求，允许时必须调用返回的 Done 报告结果；打开状态下返回 ErrOpen
+func (b *Breaker) Allow() (Done, error) {
+	now := time.Now()
+
+	b.mu.Lock()
+	b.refresh(now)
+	switch b.state {
+	case Open:
+		b.unlock()
+		metrics.BreakerRejected.WithLabelValues(b.name).Inc()
+		return nil, ErrOpen
+	case HalfOpen:
+		if b.probes >= b.opts.HalfOpenRequests {
+			b.unlock()
+			metrics.BreakerRejected.WithLabelValues(b.name).Inc()
+			return nil, ErrOpen
+		}
+		b.probes++
+	}
+	generation := b.generation
+	b.unlock()
+
+	var once sync.Once
+	return func(failed bool) {
+		once.Do(func() {
+			slow := b.opts.SlowThreshold > 0 && time.Since(now) > b.opts.SlowThreshold
+			b.record(generation, failed, slow)
+		})
+	}, nil
+}
+
+// Do 在熔断器保护下执行 fn，fn 返回错误视为失败
+func (b *Breaker) Do(fn func() error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	done(err != nil)
+	return err
+}
+
+func (b *Breaker) record(generation uint64, failed, slow bool) {
+	now := time.Now()
+
+	b.mu.Lock()
+	defer b.unlock()
+	b.refresh(now)
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case Closed:
+		bk := b.bucket(now)
+		bk.total++
+		if failed {
+			bk.failures++
+		}
+		if slow {
+			bk.slow++
+		}
+		if b.tripped(now) {
+			b.setState(Open, now)
+		}
+	case HalfOpen:
+		if failed || slow {
+			b.setState(Open, now)
+			return
+		}
+		b.successes++
+		if b.successes >= b.opts.HalfOpenRequests {
+			b.setState(Closed, now)
+		}
+	}
+}
+
+// refresh 在打开超时后进入半开状态
+func (b *Breaker) refresh(now time.Time) {
+	if b.state == Open && now.Sub(b.openedAt) >= b.opts.OpenTimeout {
+		b.setState(HalfOpen, now)
+	}
+}
+
+func (b *Breaker) setState(to State, now time.Time) {
+	from := b.state
+	b.state = to
+	b.generation++
+	b.probes, b.successes = 0, 0
+	switch to {
+	case Open:
+		b.openedAt = now
+	case Closed:
+		clear(b.buckets)
+	}
+
+	metrics.BreakerState.WithLabelValues(b.name).Set(float64(to))
+	if b.opts.OnStateChange != nil {
+		b.changes = append(b.changes, stateChange{from: from, to: to})
+	}
+}
+
+// unlock 释放锁并按顺序回调期间产生的状态变化
+func (b *Breaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+
+	for _, c := range changes {
+		b.opts.OnStateChange(b.name, c.from, c.to)
+	}
+}
+
+// bucket 返回当前时间所在的桶，过期的桶会被重置
+func (b *Breaker) bucket(now time.Time) *bucket {
+	width := b.opts.Window / time.Duration(b.opts.Buckets)
+	start := now.Truncate(width)
+	bk := &b.buckets[int(start.UnixNano()/int64(width))%len(b.buckets)]
+	if !bk.start.Equal(start) {
+		*bk = bucket{start: start}
+	}
+	return bk
+}
+
+func (b *Breaker) tripped(now time.Time) bool {
+	var total, failures, slow int
+	for _, bk := range b.buckets {
+		if now.Sub(bk.start) < b.opts.Window {
+			total += bk.total
+			failures += bk.failures
+			slow += bk.slow
+		}
+	}
+	if total < b.opts.MinRequests {
+		return false
+	}
+	return float64(failures)/float64(total) >= b.opts.ErrorRate ||
+		(b.opts.SlowThreshold > 0 && float64(slow)/float64(total) >= b.opts.SlowRate)
+}
+
+// Group 按名称管理一组配置相同的熔断器
+type Group struct {
+	opts     Options
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewGroup 创建熔断器组
+func NewGroup(opts Options) *Group {
+	return &Group{opts: opts, breakers: make(map[string]*Breaker)}
+}
+
+// Get 返回指定名称的熔断器，不存在时创建
+func (g *Group) Get(name string) *Breaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b, ok := g.breakers[name]
+	if !ok {
+		b = New(name, g.opts)
+		g.breakers[name] = b
+	}
+	return b
+}
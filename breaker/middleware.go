@@ -0,0 +1,65 @@
+package breaker
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware 返回按路由熔断的中间件，以 c.FullPath() 区分熔断器，5xx 响应计为失败；
+// 熔断打开时立即返回 503
+func Middleware(g *Group) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			c.Next()
+			return
+		}
+
+		b := g.Get(c.Request.Method + " " + route)
+		done, err := b.Allow()
+		if err != nil {
+			c.Header("Retry-After", strconv.Itoa(int(b.opts.OpenTimeout.Seconds())))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"code":    http.StatusServiceUnavailable,
+				"message": "Service Unavailable",
+			})
+			return
+		}
+
+		failed := true
+		defer func() {
+			done(failed)
+		}()
+		c.Next()
+		failed = c.Writer.Status() >= http.StatusInternalServerError
+	}
+}
+
+// Transport 返回按目标主机熔断的 http.RoundTripper，网络错误与 5xx 响应计为失败，
+// 熔断打开时返回包装 ErrOpen 的错误；next 为空时使用 http.DefaultTransport
+func Transport(g *Group, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{group: g, next: next}
+}
+
+type transport struct {
+	group *Group
+	next  http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b := t.group.Get(req.URL.Host)
+	done, err := b.Allow()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", req.URL.Host, err)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	done(err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	return resp, err
+}
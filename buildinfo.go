@@ -0,0 +1,85 @@
+package ginx
+
+import (
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BuildInfo 构建与进程信息
+type BuildInfo struct {
+	Version   string    `json:"version"`
+	Commit    string    `json:"commit"`
+	Date      string    `json:"date"`
+	GoVersion string    `json:"go_version"`
+	PID       int       `json:"pid"`
+	StartTime time.Time `json:"start_time"`
+	Uptime    string    `json:"uptime"`
+}
+
+var (
+	buildMu   sync.RWMutex
+	buildInfo = defaultBuildInfo()
+	startTime = time.Now()
+)
+
+// defaultBuildInfo 从编译时嵌入的模块与 VCS 信息中读取默认值
+func defaultBuildInfo() BuildInfo {
+	info := BuildInfo{Version: "dev"}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if v := bi.Main.Version; v != "" && v != "(devel)" {
+		info.Version = v
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Commit = s.Value
+		case "vcs.time":
+			info.Date = s.Value
+		}
+	}
+	return info
+}
+
+// SetBuildInfo 设置构建信息，通常由 -ldflags 注入的变量传入，空值保留默认值
+func SetBuildInfo(version, commit, date string) {
+	buildMu.Lock()
+	defer buildMu.Unlock()
+	if version != "" {
+		buildInfo.Version = version
+	}
+	if commit != "" {
+		buildInfo.Commit = commit
+	}
+	if date != "" {
+		buildInfo.Date = date
+	}
+}
+
+// GetBuildInfo 返回当前进程的构建信息与运行时长
+func GetBuildInfo() BuildInfo {
+	buildMu.RLock()
+	info := buildInfo
+	buildMu.RUnlock()
+
+	info.GoVersion = runtime.Version()
+	info.PID = os.Getpid()
+	info.StartTime = startTime
+	info.Uptime = time.Since(startTime).Round(time.Second).String()
+	return info
+}
+
+// BuildInfoHandler 以 JSON 返回构建信息，可用于确认平滑升级后运行的版本
+func BuildInfoHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, GetBuildInfo())
+	}
+}
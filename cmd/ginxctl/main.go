@@ -0,0 +1,241 @@
+// ginxctl 通过控制套接字或管理端口操作运行中的 ginx 服务
+//
+//	ginxctl [-socket path | -admin url] <command> [args]
+//
+// 支持的命令：status、connections、routes、runtime、config、loglevel [level]、upgrade [binary sha256]、shutdown、version
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func main() {
+	var (
+		socket   = flag.String("socket", envOr("GINX_SOCKET", "/var/run/ginx.sock"), "control socket path")
+		admin    = flag.String("admin", os.Getenv("GINX_ADMIN"), "admin API base URL, e.g. http://127.0.0.1:9090; takes precedence over -socket")
+		user     = flag.String("user", os.Getenv("GINX_ADMIN_USER"), "admin API basic auth user")
+		password = flag.String("password", os.Getenv("GINX_ADMIN_PASSWORD"), "admin API basic auth password")
+		levelURL = flag.String("loglevel-path", "/loglevel", "log level path on the admin API")
+		timeout  = flag.Duration("timeout", time.Minute, "request timeout")
+	)
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var (
+		out []byte
+		err error
+	)
+	if *admin != "" {
+		c := &adminClient{
+			base:      strings.TrimRight(*admin, "/"),
+			user:      *user,
+			password:  *password,
+			levelPath: *levelURL,
+			client:    &http.Client{Timeout: *timeout},
+		}
+		out, err = c.do(flag.Arg(0), flag.Args()[1:])
+	} else {
+		out, err = socketCommand(*socket, *timeout, flag.Args())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ginxctl:", err)
+		os.Exit(1)
+	}
+	if flag.Arg(0) == "routes" {
+		printRoutes(out)
+		return
+	}
+	printJSON(out)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: ginxctl [flags] <command> [args]
+
+Commands:
+  status            show build info, connections, log level and upgrade stats
+  connections       show connection counts by state
+  routes            list registered routes with their handlers and middlewares
+  runtime           show goroutine, memory and GC stats
+  config            show the effective configuration with secrets masked
+  version           show build info (admin API only)
+  loglevel [level]  show or change the log level
+  upgrade [binary sha256]
+                    trigger a graceful upgrade, optionally into another binary verified by its sha256
+  shutdown          trigger a graceful shutdown
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// controlResponse 与服务端 ginx.ControlResponse 对应
+type controlResponse struct {
+	OK    bool            `json:"ok"`
+	Data  json.RawMessage `json:"data"`
+	Error string          `json:"error"`
+}
+
+// socketCommand 通过控制套接字发送一条命令并返回数据部分
+func socketCommand(path string, timeout time.Duration, args []string) ([]byte, error) {
+	conn, err := net.DialTimeout("unix", path, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect control socket: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err := fmt.Fprintln(conn, strings.Join(args, " ")); err != nil {
+		return nil, fmt.Errorf("failed to send command: %w", err)
+	}
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	var resp controlResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if !resp.OK {
+		return nil, errors.New(resp.Error)
+	}
+	return resp.Data, nil
+}
+
+// adminClient 通过管理端口 HTTP 接口执行命令
+type adminClient struct {
+	base      string
+	user      string
+	password  string
+	levelPath string
+	client    *http.Client
+}
+
+func (a *adminClient) do(cmd string, args []string) ([]byte, error) {
+	switch cmd {
+	case "status":
+		return a.request(http.MethodGet, "/status", nil)
+	case "version":
+		return a.request(http.MethodGet, "/version", nil)
+	case "runtime":
+		return a.request(http.MethodGet, "/runtime/stats", nil)
+	case "config":
+		return a.request(http.MethodGet, "/config", nil)
+	case "loglevel":
+		if len(args) == 0 {
+			return a.request(http.MethodGet, a.levelPath, nil)
+		}
+		body, _ := json.Marshal(map[string]string{"level": args[0]})
+		return a.request(http.MethodPut, a.levelPath, body)
+	case "upgrade":
+		var body []byte
+		switch len(args) {
+		case 0:
+		case 2:
+			body, _ = json.Marshal(map[string]string{"binary": args[0], "sha256": args[1]})
+		default:
+			return nil, errors.New("usage: upgrade [binary sha256]")
+		}
+		return a.request(http.MethodPost, "/admin/upgrade", body)
+	case "shutdown":
+		return a.request(http.MethodPost, "/admin/shutdown", nil)
+	case "routes":
+		return a.request(http.MethodGet, "/admin/routes", nil)
+	case "connections":
+		return nil, fmt.Errorf("command %q is only available through the control socket", cmd)
+	}
+	return nil, fmt.Errorf("unknown command %q", cmd)
+}
+
+func (a *adminClient) request(method, path string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, a.base+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if a.user != "" {
+		req.SetBasicAuth(a.user, a.password)
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// printJSON 格式化输出 JSON，字符串直接输出
+func printJSON(data []byte) {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		fmt.Println(s)
+		return
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		os.Stdout.Write(data)
+		return
+	}
+	buf.WriteByte('\n')
+	buf.WriteTo(os.Stdout)
+}
+
+// printRoutes 以表格输出路由，函数名省略包路径
+func printRoutes(data []byte) {
+	var routes []struct {
+		Method      string   `json:"method"`
+		Path        string   `json:"path"`
+		Handler     string   `json:"handler"`
+		Middlewares []string `json:"middlewares"`
+	}
+	if err := json.Unmarshal(data, &routes); err != nil {
+		printJSON(data)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tHANDLER\tMIDDLEWARES")
+	for _, r := range routes {
+		mws := make([]string, len(r.Middlewares))
+		for i, m := range r.Middlewares {
+			mws[i] = shortName(m)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Method, r.Path, shortName(r.Handler), strings.Join(mws, ", "))
+	}
+	w.Flush()
+}
+
+func shortName(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
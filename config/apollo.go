@@ -0,0 +1,143 @@
+package config
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ApolloConfig Apollo 配置来源，Namespace 应为 yaml、yml 或 json 格式，如 application.yaml
+type ApolloConfig struct {
+	// Addr Apollo Config Service 地址，如 http://127.0.0.1:8080
+	Addr    string
+	AppID   string
+	Cluster string
+	// Namespace 为空时为 application.yaml
+	Namespace string
+	// Secret 访问密钥，为空时不签名
+	Secret string
+	// Client 为 nil 时使用 http.DefaultClient，长轮询最长约 60 秒，Timeout 需大于该值
+	Client *http.Client
+}
+
+type apolloProvider struct {
+	conf ApolloConfig
+}
+
+// Apollo 返回 Apollo 的 Provider，通过通知接口长轮询监听变化
+func Apollo(conf ApolloConfig) Provider {
+	if conf.Client == nil {
+		conf.Client = http.DefaultClient
+	}
+	if conf.Cluster == "" {
+		conf.Cluster = "default"
+	}
+	if conf.Namespace == "" {
+		conf.Namespace = "application.yaml"
+	}
+	conf.Addr = strings.TrimRight(conf.Addr, "/")
+	return &apolloProvider{conf: conf}
+}
+
+func (p *apolloProvider) Load(ctx context.Context) ([]byte, error) {
+	path := fmt.Sprintf("/configs/%s/%s/%s", url.PathEscape(p.conf.AppID), url.PathEscape(p.conf.Cluster), url.PathEscape(p.conf.Namespace))
+	var out struct {
+		Configurations map[string]string `json:"configurations"`
+	}
+	if err := p.get(ctx, path, &out); err != nil {
+		return nil, err
+	}
+	// yaml 与 json 格式的 namespace 内容保存在 content 中
+	content, ok := out.Configurations["content"]
+	if !ok {
+		return nil, fmt.Errorf("apollo namespace %s has no content, use a yaml or json namespace", p.conf.Namespace)
+	}
+	return []byte(content), nil
+}
+
+func (p *apolloProvider) Watch(ctx context.Context, fn func(data []byte)) error {
+	id := -1
+	for {
+		next, err := p.notifications(ctx, id)
+		if err != nil {
+			if !sleep(ctx, retryDelay) {
+				return nil
+			}
+			continue
+		}
+		if next == id {
+			continue
+		}
+		if id != -1 {
+			data, err := p.Load(ctx)
+			if err != nil {
+				continue
+			}
+			fn(data)
+		}
+		id = next
+	}
+}
+
+// notifications 长轮询 namespace 的通知 ID，无变化时返回原 ID
+func (p *apolloProvider) notifications(ctx context.Context, id int) (int, error) {
+	n, _ := json.Marshal([]map[string]any{{"namespaceName": p.conf.Namespace, "notificationId": id}})
+	q := url.Values{"appId": {p.conf.AppID}, "cluster": {p.conf.Cluster}, "notifications": {string(n)}}
+
+	var out []struct {
+		NotificationID int `json:"notificationId"`
+	}
+	err := p.get(ctx, "/notifications/v2?"+q.Encode(), &out)
+	if errors.Is(err, errNotModified) {
+		return id, nil
+	}
+	if err != nil {
+		return id, err
+	}
+	if len(out) == 0 {
+		return id, nil
+	}
+	return out[0].NotificationID, nil
+}
+
+// errNotModified 通知接口在超时无变化时返回 304
+var errNotModified = errors.New("not modified")
+
+func (p *apolloProvider) get(ctx context.Context, pathWithQuery string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.conf.Addr+pathWithQuery, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create apollo request: %w", err)
+	}
+	if p.conf.Secret != "" {
+		ts := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		mac := hmac.New(sha1.New, []byte(p.conf.Secret))
+		mac.Write([]byte(ts + "\n" + pathWithQuery))
+		req.Header.Set("Authorization", fmt.Sprintf("Apollo %s:%s", p.conf.AppID, base64.StdEncoding.EncodeToString(mac.Sum(nil))))
+		req.Header.Set("Timestamp", ts)
+	}
+	resp, err := p.conf.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request apollo: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return errNotModified
+	default:
+		return fmt.Errorf("failed to request apollo %s: unexpected status %d", pathWithQuery, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode apollo response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ConsulConfig Consul KV 配置来源
+type ConsulConfig struct {
+	// Addr Consul HTTP 地址，如 http://127.0.0.1:8500
+	Addr string
+	// Key 配置所在的 KV 键
+	Key string
+	// Token ACL token，为空时不携带
+	Token string
+	// Datacenter 为空时使用 agent 所在数据中心
+	Datacenter string
+	// Client 为 nil 时使用 http.DefaultClient
+	Client *http.Client
+}
+
+type consulProvider struct {
+	conf ConsulConfig
+}
+
+// Consul 返回 Consul KV 的 Provider，通过阻塞查询监听变化
+func Consul(conf ConsulConfig) Provider {
+	if conf.Client == nil {
+		conf.Client = http.DefaultClient
+	}
+	conf.Addr = strings.TrimRight(conf.Addr, "/")
+	return &consulProvider{conf: conf}
+}
+
+func (p *consulProvider) Load(ctx context.Context) ([]byte, error) {
+	data, _, err := p.get(ctx, "")
+	return data, err
+}
+
+func (p *consulProvider) Watch(ctx context.Context, fn func(data []byte)) error {
+	var index string
+	for {
+		data, next, err := p.get(ctx, index)
+		if err != nil {
+			if !sleep(ctx, retryDelay) {
+				return nil
+			}
+			continue
+		}
+		if index != "" && next != index {
+			fn(data)
+		}
+		index = next
+	}
+}
+
+// get 读取键值，index 不为空时进行阻塞查询，直到键值变化或超时
+func (p *consulProvider) get(ctx context.Context, index string) ([]byte, string, error) {
+	q := url.Values{"raw": {""}}
+	if p.conf.Datacenter != "" {
+		q.Set("dc", p.conf.Datacenter)
+	}
+	if index != "" {
+		q.Set("index", index)
+		q.Set("wait", "5m")
+	}
+	u := fmt.Sprintf("%s/v1/kv/%s?%s", p.conf.Addr, strings.TrimLeft(p.conf.Key, "/"), q.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create consul request: %w", err)
+	}
+	if p.conf.Token != "" {
+		req.Header.Set("X-Consul-Token", p.conf.Token)
+	}
+	resp, err := p.conf.Client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read consul key: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to read consul key %s: unexpected status %d", p.conf.Key, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read consul key: %w", err)
+	}
+	return data, resp.Header.Get("X-Consul-Index"), nil
+}
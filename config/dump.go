@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Mask 脱敏后的替换值
+const Mask = "***"
+
+// Dump 以配置文件中的字段名返回生效的配置，标记为 secret 的字段与由密钥引用解析得到的值替换为 Mask，
+// 可直接序列化为 JSON，用于确认合并环境变量、命令行参数与配置文件之后的实际配置
+func (o *Options) Dump() map[string]any {
+	m, _ := dumpValue(reflect.ValueOf(o).Elem(), "", false, o.secrets).(map[string]any)
+	return m
+}
+
+func dumpValue(v reflect.Value, path string, secret bool, secrets map[string]bool) any {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return nil
+		}
+		return t.Format(time.RFC3339)
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return dumpValue(v.Elem(), path, secret, secrets)
+	case reflect.Struct:
+		out := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := fieldName(f)
+			out[name] = dumpValue(v.Field(i), joinPath(path, name), secret || f.Tag.Get("secret") == "true", secrets)
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = dumpValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), secret, secrets)
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]any, v.Len())
+		for _, k := range v.MapKeys() {
+			key := fmt.Sprint(k)
+			out[key] = dumpValue(v.MapIndex(k), joinPath(path, key), secret, secrets)
+		}
+		return out
+	case reflect.String:
+		if s := v.String(); s != "" && (secret || secrets[path]) {
+			return Mask
+		}
+		return v.String()
+	default:
+		return v.Interface()
+	}
+}
+
+// MergeSecrets 合并 other 中由密钥引用解析得到的字段，用于热加载后保持 Dump 的脱敏
+func (o *Options) MergeSecrets(other *Options) {
+	if len(other.secrets) == 0 {
+		return
+	}
+	secrets := make(map[string]bool, len(o.secrets)+len(other.secrets))
+	for k := range o.secrets {
+		secrets[k] = true
+	}
+	for k := range other.secrets {
+		secrets[k] = true
+	}
+	o.secrets = secrets
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// EtcdConfig etcd v3 配置来源，通过 etcd 的 HTTP/JSON 网关访问
+type EtcdConfig struct {
+	// Endpoint etcd 地址，如 http://127.0.0.1:2379
+	Endpoint string
+	// Key 配置所在的键
+	Key string
+	// Username 与 Password 启用认证时使用
+	Username string
+	Password string
+	// Client 为 nil 时使用 http.DefaultClient
+	Client *http.Client
+}
+
+type etcdProvider struct {
+	conf EtcdConfig
+}
+
+// Etcd 返回 etcd v3 的 Provider，通过 watch 接口监听变化
+func Etcd(conf EtcdConfig) Provider {
+	if conf.Client == nil {
+		conf.Client = http.DefaultClient
+	}
+	conf.Endpoint = strings.TrimRight(conf.Endpoint, "/")
+	return &etcdProvider{conf: conf}
+}
+
+type etcdKV struct {
+	Value       string `json:"value"`
+	ModRevision string `json:"mod_revision"`
+}
+
+func (p *etcdProvider) Load(ctx context.Context) ([]byte, error) {
+	var resp struct {
+		KVs []etcdKV `json:"kvs"`
+	}
+	if err := p.post(ctx, "/v3/kv/range", map[string]string{"key": b64(p.conf.Key)}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.KVs) == 0 {
+		return nil, fmt.Errorf("etcd key %s not found", p.conf.Key)
+	}
+	return base64.StdEncoding.DecodeString(resp.KVs[0].Value)
+}
+
+func (p *etcdProvider) Watch(ctx context.Context, fn func(data []byte)) error {
+	for {
+		p.watch(ctx, fn)
+		if !sleep(ctx, retryDelay) {
+			return nil
+		}
+		// 重连期间可能错过变更，重新读取一次
+		if data, err := p.Load(ctx); err == nil {
+			fn(data)
+		}
+	}
+}
+
+// watch 建立一次 watch 流，逐行读取事件直到连接断开
+func (p *etcdProvider) watch(ctx context.Context, fn func(data []byte)) error {
+	body, _ := json.Marshal(map[string]any{
+		"create_request": map[string]string{"key": b64(p.conf.Key)},
+	})
+	req, err := p.request(ctx, "/v3/watch", body)
+	if err != nil {
+		return err
+	}
+	resp, err := p.conf.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to watch etcd key: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to watch etcd key %s: unexpected status %d", p.conf.Key, resp.StatusCode)
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(resp.Body))
+	for {
+		var msg struct {
+			Result struct {
+				Events []struct {
+					Type string `json:"type"`
+					KV   etcdKV `json:"kv"`
+				} `json:"events"`
+			} `json:"result"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			return fmt.Errorf("failed to read etcd watch stream: %w", err)
+		}
+		for _, ev := range msg.Result.Events {
+			// 删除事件不改变当前配置
+			if ev.Type == "DELETE" {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(ev.KV.Value)
+			if err != nil {
+				continue
+			}
+			fn(data)
+		}
+	}
+}
+
+func (p *etcdProvider) post(ctx context.Context, path string, in, out any) error {
+	body, _ := json.Marshal(in)
+	req, err := p.request(ctx, path, body)
+	if err != nil {
+		return err
+	}
+	return p.do(req, path, out)
+}
+
+func (p *etcdProvider) do(req *http.Request, path string, out any) error {
+	resp, err := p.conf.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request etcd: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to request etcd %s: unexpected status %d", path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode etcd response: %w", err)
+	}
+	return nil
+}
+
+// request 创建请求，启用认证时先获取 token
+func (p *etcdProvider) request(ctx context.Context, path string, body []byte) (*http.Request, error) {
+	req, err := newJSONRequest(ctx, p.conf.Endpoint+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if p.conf.Username == "" {
+		return req, nil
+	}
+
+	const authPath = "/v3/auth/authenticate"
+	creds, _ := json.Marshal(map[string]string{"name": p.conf.Username, "password": p.conf.Password})
+	authReq, err := newJSONRequest(ctx, p.conf.Endpoint+authPath, creds)
+	if err != nil {
+		return nil, err
+	}
+	var auth struct {
+		Token string `json:"token"`
+	}
+	if err := p.do(authReq, authPath, &auth); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", auth.Token)
+	return req, nil
+}
+
+func newJSONRequest(ctx context.Context, url string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
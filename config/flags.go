@@ -0,0 +1,220 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix 覆盖配置项的环境变量前缀，如 GINX_PORT、GINX_LOGGER_LEVEL
+const EnvPrefix = "GINX_"
+
+// flagAliases 常用配置项的简写参数
+var flagAliases = map[string]string{
+	"log-level": "logger.level",
+}
+
+// Flags BindFlags 绑定的命令行参数
+type Flags struct {
+	fs      *flag.FlagSet
+	config  string
+	profile string
+	fields  []*flagField
+}
+
+// flagField 一个可由命令行参数与环境变量设置的配置项
+type flagField struct {
+	path  string
+	env   string
+	index []int
+	typ   reflect.Type
+	value string
+}
+
+func (f *flagField) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+func (f *flagField) Set(s string) error {
+	if _, err := parseField(f.typ, s); err != nil {
+		return err
+	}
+	f.value = s
+	return nil
+}
+
+func (f *flagField) IsBoolFlag() bool {
+	return f.typ.Kind() == reflect.Bool
+}
+
+// BindFlags 在 fs 上注册 --config、--env 以及 Options 中每个标量配置项对应的参数，
+// 参数名为配置文件字段路径（如 --port、--read-timeout、--logger.level），--log-level 为 --logger.level 的简写；
+// fs.Parse 之后调用 Flags.Options 得到按 参数 > 环境变量 > 配置文件 > 默认值 合并的配置
+func BindFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{fs: fs}
+	fs.StringVar(&f.config, "config", os.Getenv(EnvPrefix+"CONFIG"), "config file in YAML or JSON (env "+EnvPrefix+"CONFIG)")
+	fs.StringVar(&f.profile, "env", Profile(), "environment profile such as dev or prod (env "+EnvProfile+")")
+
+	byPath := map[string]*flagField{}
+	collectFields(reflect.TypeOf(Options{}), "", nil, func(field *flagField) {
+		f.fields = append(f.fields, field)
+		byPath[field.path] = field
+		fs.Var(field, flagName(field.path), fmt.Sprintf("override %s (env %s)", field.path, field.env))
+	})
+	for alias, path := range flagAliases {
+		if field, ok := byPath[path]; ok {
+			fs.Var(field, alias, "shorthand for --"+flagName(path))
+		}
+	}
+	return f
+}
+
+// Options 读取配置文件（--config 未设置时使用默认配置），再依次应用环境变量与命令行参数，最后解析密钥引用
+func (f *Flags) Options() (*Options, error) {
+	var opts *Options
+	if f.config != "" {
+		var err error
+		if opts, err = LoadProfile(f.config, f.profile); err != nil {
+			return nil, err
+		}
+	} else {
+		opts = ProfileDefaults(f.profile)
+	}
+
+	for _, field := range f.fields {
+		if v, ok := os.LookupEnv(field.env); ok {
+			if err := setField(opts, field, v); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", field.env, err)
+			}
+		}
+	}
+	var err error
+	f.fs.Visit(func(fl *flag.Flag) {
+		field, ok := fl.Value.(*flagField)
+		if !ok || err != nil {
+			return
+		}
+		if serr := setField(opts, field, field.value); serr != nil {
+			err = fmt.Errorf("invalid -%s: %w", fl.Name, serr)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ResolveSecrets(context.Background(), opts); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// collectFields 遍历 Options 的标量字段，嵌套结构以 . 连接路径，map 不支持
+func collectFields(t reflect.Type, prefix string, index []int, fn func(*flagField)) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		path := joinPath(prefix, fieldName(sf))
+		idx := append(append([]int(nil), index...), i)
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer && ft.Elem().Kind() == reflect.Struct {
+			collectFields(ft.Elem(), path, idx, fn)
+			continue
+		}
+		if _, err := parseField(ft, ""); errors.Is(err, errUnsupported) {
+			continue
+		}
+		env := EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+		fn(&flagField{path: path, env: env, index: idx, typ: ft})
+	}
+}
+
+func flagName(path string) string {
+	return strings.ReplaceAll(path, "_", "-")
+}
+
+// setField 按字段路径设置值，路径上为 nil 的结构体指针会被创建
+func setField(opts *Options, field *flagField, s string) error {
+	v, err := parseField(field.typ, s)
+	if err != nil {
+		return err
+	}
+	cur := reflect.ValueOf(opts).Elem()
+	for _, i := range field.index[:len(field.index)-1] {
+		cur = cur.Field(i)
+		if cur.IsNil() {
+			cur.Set(reflect.New(cur.Type().Elem()))
+		}
+		cur = cur.Elem()
+	}
+	cur.Field(field.index[len(field.index)-1]).Set(v)
+	return nil
+}
+
+var errUnsupported = errors.New("unsupported field type")
+
+// parseField 将字符串解析为 t 类型的值，切片以逗号分隔
+func parseField(t reflect.Type, s string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	if t == reflect.TypeOf(time.Duration(0)) {
+		if s == "" {
+			return v, nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(int64(d))
+		return v, nil
+	}
+
+	var err error
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if s != "" {
+			b, err = strconv.ParseBool(s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		var n int64
+		if s != "" {
+			n, err = strconv.ParseInt(s, 10, 64)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		var n float64
+		if s != "" {
+			n, err = strconv.ParseFloat(s, 64)
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.String {
+			return v, errUnsupported
+		}
+		if s == "" {
+			return v, nil
+		}
+		parts := strings.Split(s, ",")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		v = reflect.ValueOf(parts)
+	default:
+		return v, errUnsupported
+	}
+	return v, err
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load 读取 YAML 或 JSON 格式的配置文件，并按 GINX_ENV 合并对应环境的覆盖文件，见 LoadProfile
+func Load(path string) (*Options, error) {
+	return LoadProfile(path, Profile())
+}
+
+// LoadProfile 读取基础配置文件，profile 不为空且存在覆盖文件（如 config.prod.yaml）时深度合并：
+// 覆盖文件中出现的字段替换基础配置，嵌套结构与 map 逐项合并，切片整体替换；
+// 未出现的字段保留 ProfileDefaults 中的默认值，时长可写为 "30s"、"5m" 等形式，密钥引用由 ResolveSecrets 解析
+func LoadProfile(path, profile string) (*Options, error) {
+	data, err := layeredFile(path, profile)
+	if err != nil {
+		return nil, err
+	}
+	opts := ProfileDefaults(profile)
+	if err := Parse(data, opts); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if err := ResolveSecrets(context.Background(), opts); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// layeredFile 读取基础配置与环境覆盖文件，拼接为多文档 YAML，由 Parse 依次合并
+func layeredFile(path, profile string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if profile == "" {
+		return data, nil
+	}
+	overlay, err := os.ReadFile(ProfileFile(path, profile))
+	if errors.Is(err, os.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return slices.Concat(data, []byte("\n---\n"), overlay), nil
+}
+
+// Parse 将 YAML 或 JSON 格式的配置合并到 opts，多个 YAML 文档按顺序合并，未知字段视为错误
+func Parse(data []byte, opts *Options) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	for {
+		if err := dec.Decode(opts); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NacosConfig Nacos 配置来源
+type NacosConfig struct {
+	// Addr Nacos 地址，如 http://127.0.0.1:8848
+	Addr string
+	// DataID 与 Group 定位配置，Group 为空时为 DEFAULT_GROUP
+	DataID string
+	Group  string
+	// Namespace 命名空间 ID，为空时为 public
+	Namespace string
+	// Username 与 Password 启用认证时使用
+	Username string
+	Password string
+	// Client 为 nil 时使用 http.DefaultClient
+	Client *http.Client
+}
+
+type nacosProvider struct {
+	conf NacosConfig
+}
+
+// Nacos 返回 Nacos 的 Provider，通过长轮询监听变化
+func Nacos(conf NacosConfig) Provider {
+	if conf.Client == nil {
+		conf.Client = http.DefaultClient
+	}
+	if conf.Group == "" {
+		conf.Group = "DEFAULT_GROUP"
+	}
+	conf.Addr = strings.TrimRight(conf.Addr, "/")
+	return &nacosProvider{conf: conf}
+}
+
+func (p *nacosProvider) Load(ctx context.Context) ([]byte, error) {
+	q := url.Values{"dataId": {p.conf.DataID}, "group": {p.conf.Group}}
+	if p.conf.Namespace != "" {
+		q.Set("tenant", p.conf.Namespace)
+	}
+	resp, err := p.do(ctx, http.MethodGet, "/nacos/v1/cs/configs", q, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read nacos config %s: unexpected status %d", p.conf.DataID, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nacos config: %w", err)
+	}
+	return data, nil
+}
+
+func (p *nacosProvider) Watch(ctx context.Context, fn func(data []byte)) error {
+	var sum string
+	if data, err := p.Load(ctx); err == nil {
+		sum = md5sum(data)
+	}
+	for {
+		changed, err := p.listen(ctx, sum)
+		if err != nil {
+			if !sleep(ctx, retryDelay) {
+				return nil
+			}
+			continue
+		}
+		if !changed {
+			continue
+		}
+		data, err := p.Load(ctx)
+		if err != nil {
+			continue
+		}
+		sum = md5sum(data)
+		fn(data)
+	}
+}
+
+// listen 长轮询配置变化，配置的 MD5 与 sum 不同时返回 true
+func (p *nacosProvider) listen(ctx context.Context, sum string) (bool, error) {
+	// 格式为 dataId^2group^2md5[^2tenant]^1
+	parts := []string{p.conf.DataID, p.conf.Group, sum}
+	if p.conf.Namespace != "" {
+		parts = append(parts, p.conf.Namespace)
+	}
+	form := url.Values{"Listening-Configs": {strings.Join(parts, "\x02") + "\x01"}}
+
+	resp, err := p.do(ctx, http.MethodPost, "/nacos/v1/cs/configs/listener", nil, form)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("failed to listen nacos config %s: unexpected status %d", p.conf.DataID, resp.StatusCode)
+	}
+	return strings.TrimSpace(string(body)) != "", nil
+}
+
+func (p *nacosProvider) do(ctx context.Context, method, path string, q, form url.Values) (*http.Response, error) {
+	if q == nil {
+		q = url.Values{}
+	}
+	if p.conf.Username != "" {
+		token, err := p.login(ctx)
+		if err != nil {
+			return nil, err
+		}
+		q.Set("accessToken", token)
+	}
+
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.conf.Addr+path+"?"+q.Encode(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create nacos request: %w", err)
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		// 服务端最多挂起 30 秒
+		req.Header.Set("Long-Pulling-Timeout", "30000")
+	}
+	resp, err := p.conf.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request nacos: %w", err)
+	}
+	return resp, nil
+}
+
+func (p *nacosProvider) login(ctx context.Context) (string, error) {
+	form := url.Values{"username": {p.conf.Username}, "password": {p.conf.Password}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.conf.Addr+"/nacos/v1/auth/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create nacos login request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := p.conf.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to login nacos: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to login nacos: unexpected status %d", resp.StatusCode)
+	}
+	var out struct {
+		AccessToken string `json:"accessToken"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to decode nacos login response: %w", err)
+	}
+	return out.AccessToken, nil
+}
+
+func md5sum(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -6,37 +6,386 @@ import (
 
 // Options 引擎配置选项
 type Options struct {
+	// Profile 当前环境，如 dev、staging、prod，由 LoadProfile 设置
+	Profile string `yaml:"profile"`
+	// Debug 启用 gin 调试模式，输出路由注册等调试信息
+	Debug bool `yaml:"debug"`
+
 	// 服务配置
-	Port         int
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	Port         int           `yaml:"port"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// TrustedProxies 可信代理的 IP 或 CIDR，设置后仅采信来自这些代理的 X-Forwarded-For 等转发头，未设置时不采信任何转发头
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	// ShutdownTimeout 优雅关闭时等待请求处理完成的最长时间
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// ShutdownHookTimeout 关闭钩子的总超时时间，在服务关闭完成后单独计时，
+	// 不受 ShutdownTimeout 剩余时间影响，关闭总耗时最多为两者之和；为 0 时为 10 秒
+	ShutdownHookTimeout time.Duration `yaml:"shutdown_hook_timeout"`
+	// DrainDelay 收到退出信号后先将就绪检查置为 503 并等待该时长，再停止接收请求，
+	// 以便负载均衡器摘除流量
+	DrainDelay time.Duration `yaml:"drain_delay"`
+	// UpgradeStrategy 平滑升级方式：tableflip（默认）、graceful、inprocess，
+	// 平台不支持文件描述符继承时 tableflip 退化为 inprocess
+	UpgradeStrategy string `yaml:"upgrade_strategy"`
+	// UpgradeTimeout 平滑升级时旧进程等待新进程就绪的最长时间，超时后终止新进程并继续服务，为 0 时为一分钟
+	UpgradeTimeout time.Duration `yaml:"upgrade_timeout"`
+	// Signals 信号与升级、关闭、日志操作的映射，为空时使用默认映射
+	Signals *SignalOptions `yaml:"signals"`
+	// UpgradeRetry 平滑升级失败时的重试策略，为空时只尝试一次
+	UpgradeRetry *UpgradeRetryOptions `yaml:"upgrade_retry"`
+	// PIDFile 进程就绪后写入 PID 的文件，平滑升级时由新进程原子地覆盖，便于部署脚本向当前主进程发送信号
+	PIDFile string `yaml:"pid_file"`
+	// Drain 连接排空策略，为 nil 时使用默认策略
+	Drain *DrainOptions `yaml:"drain"`
+	// Update 自动更新配置，为 nil 时不启用
+	Update *UpdateOptions `yaml:"update"`
 
 	// 日志配置
-	Logger *LogOptions
+	Logger *LogOptions `yaml:"logger"`
+	// 访问日志配置，为 nil 时使用默认字段与 json 格式
+	AccessLog *AccessLogOptions `yaml:"access_log"`
+	// SlowRequestThreshold 慢请求阈值，超过时输出 warn 日志并计入 ginx_slow_requests_total，为 0 时不检测
+	SlowRequestThreshold time.Duration `yaml:"slow_request_threshold"`
+	// LogLevelPath 运行时查看/修改日志级别的接口路径（GET/PUT），为空时不注册
+	LogLevelPath string `yaml:"log_level_path"`
+
+	// MetricsPath Prometheus 指标接口路径，为空时不注册
+	MetricsPath string `yaml:"metrics_path"`
+	// VersionPath 构建信息接口路径，为空时不注册，配置了 Admin 时默认 /version
+	VersionPath string `yaml:"version_path"`
+
+	// Admin 独立的管理端口配置，设置后指标、pprof、日志级别等管理接口只在该端口提供，不再挂载到业务路由
+	Admin *AdminOptions `yaml:"admin"`
+	// ControlSocket 控制套接字路径（如 /var/run/ginx.sock），支持 status、upgrade、shutdown、loglevel 等命令，为空时不启用
+	ControlSocket string `yaml:"control_socket"`
+
+	// EnablePprof 注册 net/http/pprof 与 expvar 调试接口
+	EnablePprof bool `yaml:"enable_pprof"`
+	// Pprof 调试接口配置，为 nil 时挂载到管理端口或业务路由的 /debug 下且只允许本机访问
+	Pprof *PprofOptions `yaml:"pprof"`
+
+	// BareResponse 为 true 时 ginx.OK 等响应助手直接返回数据，不使用 code/msg/data 包装
+	BareResponse bool `yaml:"bare_response"`
+
+	// JSONEngine 响应与请求体绑定使用的 JSON 引擎：std（默认）、jsoniter、sonic（仅 amd64/arm64）
+	JSONEngine string `yaml:"json_engine"`
+
+	// 健康检查配置
+	Health *HealthOptions `yaml:"health"`
+
+	// 跨域配置，为 nil 时不启用
+	CORS *CORSOptions `yaml:"cors"`
+
+	// 按客户端 IP 的全局限流，为 nil 时不启用
+	RateLimit *RateLimitOptions `yaml:"rate_limit"`
+
+	// 请求体大小限制，为 nil 时不启用
+	BodyLimit *BodyLimitOptions `yaml:"body_limit"`
+
+	// 安全响应头配置，为 nil 时不启用
+	SecureHeaders *SecureHeadersOptions `yaml:"secure_headers"`
+
+	// 请求/响应体捕获，为 nil 时不启用
+	BodyCapture *BodyCaptureOptions `yaml:"body_capture"`
+
+	// Sentry 错误上报配置，为 nil 或 DSN 为空时不启用
+	Sentry *SentryOptions `yaml:"sentry"`
+
+	// API 版本路由配置，为 nil 时版本挂载在 /api 下且不支持版本协商
+	API *APIOptions `yaml:"api"`
+	// OpenAPI 由 ginx.Handle 注册的接口生成 OpenAPI 文档，为 nil 时不提供
+	OpenAPI *OpenAPIOptions `yaml:"openapi"`
+
+	// Database 数据库连接池配置，通过 ginx.OpenDB 或 Container.ProvideDB 使用
+	Database *DatabaseOptions `yaml:"database"`
+	// Redis Redis 客户端配置，通过 Engine.OpenRedis 或 Container.ProvideRedis 使用
+	Redis *RedisOptions `yaml:"redis"`
 
 	// 中间件配置
-	EnableRecovery bool
-	EnableLogger   bool
+	EnableRecovery  bool `yaml:"enable_recovery"`
+	EnableLogger    bool `yaml:"enable_logger"`
+	EnableRequestID bool `yaml:"enable_request_id"`
+	// EnableErrorHandler 将 c.Errors 中的错误统一转换为 problem+json 响应
+	EnableErrorHandler bool `yaml:"enable_error_handler"`
+
+	// secrets 由密钥引用解析得到的字段路径，Dump 时脱敏
+	secrets map[string]bool
+}
+
+// DrainOptions 关闭或升级时的连接排空策略
+type DrainOptions struct {
+	// CloseIdle 收到退出信号后立即关闭空闲的 keep-alive 连接并禁用 keep-alive，
+	// 使客户端在 DrainDelay 期间尽快切换到其它实例
+	CloseIdle bool `yaml:"close_idle"`
+	// HijackedTimeout 等待被劫持连接（如 WebSocket）自行关闭的最长时间，超时后强制关闭；
+	// 为 0 时等待至 ShutdownTimeout
+	HijackedTimeout time.Duration `yaml:"hijacked_timeout"`
+	// SoftTimeout 等待处理中的请求完成的最长时间，超时后记录未完成的请求并强制关闭所有连接；
+	// 为 0 时等待至 ShutdownTimeout
+	SoftTimeout time.Duration `yaml:"soft_timeout"`
 }
 
 // LogOptions 日志配置选项
 type LogOptions struct {
-	Level      string
-	Filename   string
-	MaxSize    int
-	MaxAge     int
-	MaxBackups int
-	Compress   bool
-	LocalTime  bool
-	Console    bool
+	Level      string `yaml:"level"`
+	Filename   string `yaml:"filename"`
+	MaxSize    int    `yaml:"max_size"`
+	MaxAge     int    `yaml:"max_age"`
+	MaxBackups int    `yaml:"max_backups"`
+	Compress   bool   `yaml:"compress"`
+	LocalTime  bool   `yaml:"local_time"`
+	Console    bool   `yaml:"console"`
+	// Color 控制台日志按级别着色
+	Color bool `yaml:"color"`
+	// RedactFields 日志中需要脱敏的字段名
+	RedactFields []string `yaml:"redact_fields"`
+	// RedactPatterns 日志中需要脱敏的内容正则
+	RedactPatterns []string `yaml:"redact_patterns"`
+}
+
+// HealthOptions 健康检查配置选项
+type HealthOptions struct {
+	Enabled       bool   `yaml:"enabled"`
+	LivenessPath  string `yaml:"liveness_path"`
+	ReadinessPath string `yaml:"readiness_path"`
+	// Timeout 单次就绪检查的超时时间
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// SignalOptions 信号映射，信号名如 "SIGUSR2" 或 "USR2"，为 nil 的项使用默认值，空切片表示不监听
+type SignalOptions struct {
+	// Upgrade 触发平滑升级的信号，默认 SIGHUP
+	Upgrade []string `yaml:"upgrade"`
+	// Shutdown 触发优雅关闭的信号，默认 SIGINT、SIGTERM、SIGQUIT
+	Shutdown []string `yaml:"shutdown"`
+	// LogLevel 在 debug 与配置的日志级别之间切换的信号，默认 SIGUSR1
+	LogLevel []string `yaml:"log_level"`
+	// LogRotate 切分日志文件的信号，默认不监听
+	LogRotate []string `yaml:"log_rotate"`
+}
+
+// UpgradeRetryOptions 平滑升级重试策略
+type UpgradeRetryOptions struct {
+	// MaxAttempts 最多尝试次数
+	MaxAttempts int `yaml:"max_attempts"`
+	// Backoff 首次重试前的等待时间，之后每次翻倍，MaxBackoff 为上限
+	Backoff    time.Duration `yaml:"backoff"`
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+	// StableWindow 新进程就绪后需持续运行的时长，期间退出视为升级失败，旧进程继续服务；仅 graceful 升级方式支持
+	StableWindow time.Duration `yaml:"stable_window"`
+}
+
+// AdminOptions 管理端口配置
+type AdminOptions struct {
+	// Addr 监听地址，通常只监听本机，如 127.0.0.1:9090
+	Addr string `yaml:"addr"`
+	// AllowIPs 允许访问的 IP 或 CIDR，为空时不限制
+	AllowIPs []string `yaml:"allow_ips"`
+	// Users 用户名 -> bcrypt 哈希，非空时启用 Basic 认证，并注册 POST /admin/upgrade 与 /admin/shutdown
+	Users map[string]string `yaml:"users" secret:"true"`
+	// DumpDir goroutine/heap 转储文件的写入目录，为空时转储内容直接在响应中返回
+	DumpDir string `yaml:"dump_dir"`
+}
+
+// PprofOptions pprof 与 expvar 调试接口配置
+type PprofOptions struct {
+	// Prefix 路由前缀，默认 /debug，即 /debug/pprof/ 与 /debug/vars
+	Prefix string `yaml:"prefix"`
+	// Addr 不为空时在该地址单独监听（如 127.0.0.1:6060），否则挂载到管理端口或业务路由
+	Addr string `yaml:"addr"`
+	// AllowIPs 允许访问的 IP 或 CIDR；与 Users 均为空时只允许本机（127.0.0.1、::1）访问，
+	// 挂载在已配置访问控制的管理端口上时沿用管理端口的限制
+	AllowIPs []string `yaml:"allow_ips"`
+	// Users 用户名 -> bcrypt 哈希，非空时启用 Basic 认证
+	Users map[string]string `yaml:"users" secret:"true"`
+}
+
+// CORSOptions 跨域配置选项
+type CORSOptions struct {
+	AllowOrigins        []string      `yaml:"allow_origins"`
+	AllowOriginPatterns []string      `yaml:"allow_origin_patterns"`
+	AllowMethods        []string      `yaml:"allow_methods"`
+	AllowHeaders        []string      `yaml:"allow_headers"`
+	ExposeHeaders       []string      `yaml:"expose_headers"`
+	AllowCredentials    bool          `yaml:"allow_credentials"`
+	MaxAge              time.Duration `yaml:"max_age"`
+}
+
+// RateLimitOptions 限流配置选项
+type RateLimitOptions struct {
+	// Rate 每秒允许的请求数
+	Rate float64 `yaml:"rate"`
+	// Burst 允许的突发请求数
+	Burst int `yaml:"burst"`
+}
+
+// BodyLimitOptions 请求体大小限制配置选项
+type BodyLimitOptions struct {
+	// Limit 默认最大字节数
+	Limit int64 `yaml:"limit"`
+	// Routes 按路由模式覆盖限制
+	Routes map[string]int64 `yaml:"routes"`
+}
+
+// SecureHeadersOptions 安全响应头配置选项
+type SecureHeadersOptions struct {
+	HSTSMaxAge            time.Duration `yaml:"hsts_max_age"`
+	HSTSIncludeSubdomains bool          `yaml:"hsts_include_subdomains"`
+	HSTSPreload           bool          `yaml:"hsts_preload"`
+	ContentTypeNosniff    bool          `yaml:"content_type_nosniff"`
+	FrameOptions          string        `yaml:"frame_options"`
+	ReferrerPolicy        string        `yaml:"referrer_policy"`
+	// ContentSecurityPolicy 支持 {nonce} 占位符
+	ContentSecurityPolicy string `yaml:"content_security_policy"`
+}
+
+// AccessLogOptions 访问日志配置选项
+type AccessLogOptions struct {
+	// Format json 或 combined
+	Format string `yaml:"format"`
+	// Fields 输出字段，可选值见 middleware.Field* 常量
+	Fields []string `yaml:"fields"`
+	// SkipPaths 不记录日志的请求路径
+	SkipPaths []string `yaml:"skip_paths"`
+	// SampleRate 成功请求的采样率，错误与慢请求始终记录
+	SampleRate float64 `yaml:"sample_rate"`
+}
+
+// BodyCaptureOptions 请求/响应体捕获配置选项
+type BodyCaptureOptions struct {
+	// Enabled 调试开关，为 true 时捕获所有请求
+	Enabled bool `yaml:"enabled"`
+	// Header 请求携带该头时捕获当前请求
+	Header       string   `yaml:"header"`
+	MaxSize      int      `yaml:"max_size"`
+	ContentTypes []string `yaml:"content_types"`
+	RedactFields []string `yaml:"redact_fields"`
+}
+
+// UpdateOptions 自动更新配置：定期拉取发布清单，发现新版本时下载、验证签名并平滑升级到新的可执行文件，
+// 当前版本取自 ginx.SetBuildInfo 设置的版本号
+type UpdateOptions struct {
+	// ManifestURL JSON 发布清单地址，支持 {os} 与 {arch} 占位符
+	ManifestURL string `yaml:"manifest_url"`
+	// PublicKey base64 编码的 Ed25519 公钥，用于验证清单中的签名
+	PublicKey string `yaml:"public_key"`
+	// Interval 检查间隔，为 0 时为十分钟
+	Interval time.Duration `yaml:"interval"`
+	// Dir 新版本的下载目录，为空时使用当前可执行文件所在目录
+	Dir string `yaml:"dir"`
+}
+
+// SentryOptions Sentry 错误上报配置选项
+type SentryOptions struct {
+	DSN         string  `yaml:"dsn" secret:"true"`
+	Release     string  `yaml:"release"`
+	Environment string  `yaml:"environment"`
+	SampleRate  float64 `yaml:"sample_rate"`
+}
+
+// APIOptions API 版本路由配置
+type APIOptions struct {
+	// Prefix 版本路由组的前缀，为空时为 /api
+	Prefix string `yaml:"prefix"`
+	// Header 指定版本的请求头，如 Accept-Version，为空时不支持按请求头协商
+	Header string `yaml:"header"`
+	// Query 指定版本的查询参数，如 version，为空时不支持按查询参数协商
+	Query string `yaml:"query"`
+	// Deprecated 已弃用的版本，响应会带上 Deprecation、Sunset 与 Link 头
+	Deprecated map[string]DeprecationOptions `yaml:"deprecated"`
+}
+
+// DeprecationOptions 版本弃用信息
+type DeprecationOptions struct {
+	// Date 弃用时间，为零值时 Deprecation 头为 true
+	Date time.Time `yaml:"date"`
+	// Sunset 计划下线时间，为零值时不输出 Sunset 头
+	Sunset time.Time `yaml:"sunset"`
+	// Link 迁移说明文档地址
+	Link string `yaml:"link"`
+}
+
+// OpenAPIOptions OpenAPI 文档配置
+type OpenAPIOptions struct {
+	// Path 文档路径，为空时为 /openapi.json
+	Path string `yaml:"path"`
+	// Title 文档标题，为空时为 API
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	// Version 接口版本，为空时使用构建信息中的版本号
+	Version string `yaml:"version"`
+	// UIPath 文档页面路径，为空时不提供
+	UIPath string `yaml:"ui_path"`
+	// UI 文档页面类型：swagger（默认）或 redoc，redoc 需要应用设置 ginx.RedocBundle，否则使用 Swagger UI
+	UI string `yaml:"ui"`
+	// AllowIPs 允许访问文档与文档页面的 IP 或 CIDR，为空时不限制
+	AllowIPs []string `yaml:"allow_ips"`
+	// Users 用户名 -> bcrypt 哈希，非空时启用 Basic 认证
+	Users map[string]string `yaml:"users" secret:"true"`
+}
+
+// DatabaseOptions 数据库连接池配置
+type DatabaseOptions struct {
+	// Name 连接池名称，用于指标与健康检查，为空时为 default
+	Name string `yaml:"name"`
+	// Driver 已注册的 database/sql 驱动名称，如 pgx、mysql
+	Driver string `yaml:"driver"`
+	DSN    string `yaml:"dsn" secret:"true"`
+	// MaxOpenConns 最大连接数，0 表示不限制
+	MaxOpenConns int `yaml:"max_open_conns"`
+	// MaxIdleConns 最大空闲连接数，0 时为 2
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	// PingTimeout 启动与就绪检查时 Ping 的超时，为 0 时为 5s
+	PingTimeout time.Duration `yaml:"ping_timeout"`
+}
+
+// RedisOptions Redis 客户端配置
+type RedisOptions struct {
+	// Name 客户端名称，用于指标与健康检查，为空时为 default
+	Name string `yaml:"name"`
+	// Addrs 节点地址，设置 MasterName 时为哨兵地址
+	Addrs []string `yaml:"addrs"`
+	// MasterName 不为空时通过 Sentinel 连接
+	MasterName string `yaml:"master_name"`
+	// Cluster 使用 Cluster 模式
+	Cluster          bool          `yaml:"cluster"`
+	Username         string        `yaml:"username"`
+	Password         string        `yaml:"password" secret:"true"`
+	SentinelUsername string        `yaml:"sentinel_username"`
+	SentinelPassword string        `yaml:"sentinel_password" secret:"true"`
+	DB               int           `yaml:"db"`
+	PoolSize         int           `yaml:"pool_size"`
+	MinIdleConns     int           `yaml:"min_idle_conns"`
+	DialTimeout      time.Duration `yaml:"dial_timeout"`
+	ReadTimeout      time.Duration `yaml:"read_timeout"`
+	WriteTimeout     time.Duration `yaml:"write_timeout"`
+	// SlowThreshold 超过该耗时的命令记录日志，为 0 时不记录
+	SlowThreshold time.Duration `yaml:"slow_threshold"`
+	// TLS 为 nil 时不启用
+	TLS *RedisTLSOptions `yaml:"tls"`
+}
+
+// RedisTLSOptions Redis TLS 配置
+type RedisTLSOptions struct {
+	// CAFile 为空时使用系统根证书
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
 }
 
 // DefaultOptions 返回默认配置
 func DefaultOptions() *Options {
 	return &Options{
-		Port:         8080,
-		ReadTimeout:  time.Second * 30,
-		WriteTimeout: time.Second * 30,
+		Port:                8080,
+		ReadTimeout:         time.Second * 30,
+		WriteTimeout:        time.Second * 30,
+		ShutdownTimeout:     time.Second * 30,
+		ShutdownHookTimeout: time.Second * 10,
 		Logger: &LogOptions{
 			Level:      "info",
 			MaxSize:    100,
@@ -46,7 +395,15 @@ func DefaultOptions() *Options {
 			LocalTime:  true,
 			Console:    true,
 		},
-		EnableRecovery: true,
-		EnableLogger:   true,
+		Health: &HealthOptions{
+			Enabled:       true,
+			LivenessPath:  "/healthz",
+			ReadinessPath: "/readyz",
+			Timeout:       time.Second * 5,
+		},
+		EnableRecovery:     true,
+		EnableLogger:       true,
+		EnableRequestID:    true,
+		EnableErrorHandler: true,
 	}
 }
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvProfile 选择环境配置的环境变量，如 GINX_ENV=prod
+const EnvProfile = "GINX_ENV"
+
+// Profile 返回 GINX_ENV 指定的环境，未设置时为空
+func Profile() string {
+	return os.Getenv(EnvProfile)
+}
+
+// IsDev 是否为开发环境（dev、development 或 local）
+func IsDev(profile string) bool {
+	switch strings.ToLower(profile) {
+	case "dev", "development", "local":
+		return true
+	}
+	return false
+}
+
+// ProfileFile 返回 path 对应环境的覆盖文件路径，如 config.yaml + prod -> config.prod.yaml
+func ProfileFile(path, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
+// ProfileDefaults 返回环境的默认配置：开发环境在 DefaultOptions 基础上启用彩色控制台日志、
+// gin 调试模式与 pprof
+func ProfileDefaults(profile string) *Options {
+	opts := DefaultOptions()
+	opts.Profile = profile
+	if IsDev(profile) {
+		opts.Debug = true
+		opts.EnablePprof = true
+		opts.Logger.Level = "debug"
+		opts.Logger.Console = true
+		opts.Logger.Color = true
+	}
+	return opts
+}
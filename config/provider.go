@@ -0,0 +1,116 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Provider 配置来源，返回 YAML 或 JSON 格式的配置内容
+type Provider interface {
+	// Load 读取当前配置
+	Load(ctx context.Context) ([]byte, error)
+	// Watch 持续监听配置变化，每次变化后以新内容调用 fn，直到 ctx 被取消；
+	// 出错时由实现自行重试，只有无法继续监听时才返回
+	Watch(ctx context.Context, fn func(data []byte)) error
+}
+
+// LoadFrom 从 p 读取配置并解析密钥引用，未出现的字段保留 GINX_ENV 对应的 ProfileDefaults 中的默认值
+func LoadFrom(ctx context.Context, p Provider) (*Options, error) {
+	data, err := p.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts := ProfileDefaults(Profile())
+	if err := Parse(data, opts); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := ResolveSecrets(ctx, opts); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// retryDelay 远程配置中心请求失败后的重试间隔
+const retryDelay = 5 * time.Second
+
+// sleep 等待 d 或 ctx 被取消，返回 ctx 是否仍有效
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// fileProvider 本地配置文件及其环境覆盖文件
+type fileProvider struct {
+	path    string
+	profile string
+}
+
+// File 返回本地配置文件的 Provider，按 GINX_ENV 合并环境覆盖文件；通过 fsnotify 监听文件所在目录，
+// 以便处理编辑器与 Kubernetes ConfigMap 通过重命名替换文件的情况
+func File(path string) Provider {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return &fileProvider{path: path, profile: Profile()}
+}
+
+func (f *fileProvider) Load(context.Context) ([]byte, error) {
+	return layeredFile(f.path, f.profile)
+}
+
+func (f *fileProvider) Watch(ctx context.Context, fn func(data []byte)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(f.path)); err != nil {
+		return fmt.Errorf("failed to watch config file: %w", err)
+	}
+
+	// 合并短时间内的多次写入
+	const debounce = 100 * time.Millisecond
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	names := []string{filepath.Base(f.path), "..data"}
+	if f.profile != "" {
+		names = append(names, filepath.Base(ProfileFile(f.path, f.profile)))
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if slices.Contains(names, filepath.Base(ev.Name)) {
+				timer.Reset(debounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("config watcher error: %w", err)
+		case <-timer.C:
+			data, err := f.Load(ctx)
+			if err != nil {
+				// 文件可能正被替换，等待下一次事件
+				continue
+			}
+			fn(data)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// SecretResolver 解析配置中形如 scheme://... 的密钥引用
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref *url.URL) (string, error)
+}
+
+// SecretResolverFunc 函数形式的 SecretResolver
+type SecretResolverFunc func(ctx context.Context, ref *url.URL) (string, error)
+
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]SecretResolver{
+		"env":   SecretResolverFunc(resolveEnv),
+		"file":  SecretResolverFunc(resolveFile),
+		"vault": Vault(VaultConfig{}),
+	}
+)
+
+// RegisterSecretResolver 注册或替换 scheme 对应的解析器，内置 env、file 与 vault
+func RegisterSecretResolver(scheme string, r SecretResolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = r
+}
+
+func secretResolver(value string) (SecretResolver, bool) {
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return nil, false
+	}
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	r, ok := resolvers[scheme]
+	return r, ok
+}
+
+// ResolveSecrets 将 opts 中所有字符串（包括切片与 map 的值）里的密钥引用替换为实际值，
+// 如 env://DB_PASSWORD、file:///run/secrets/db、vault://kv/app#db_password；
+// 未注册的 scheme（如 http://）保持原样
+func ResolveSecrets(ctx context.Context, opts *Options) error {
+	if opts.secrets == nil {
+		opts.secrets = make(map[string]bool)
+	}
+	return resolveValue(ctx, reflect.ValueOf(opts).Elem(), "", opts.secrets)
+}
+
+// resolveValue 递归解析密钥引用，path 为配置文件中的字段路径，解析过的字段记录到 secrets 中
+func resolveValue(ctx context.Context, v reflect.Value, path string, secrets map[string]bool) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return resolveValue(ctx, v.Elem(), path, secrets)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if err := resolveValue(ctx, v.Field(i), joinPath(path, fieldName(f)), secrets); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := resolveValue(ctx, v.Index(i), fmt.Sprintf("%s[%d]", path, i), secrets); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		for _, k := range v.MapKeys() {
+			key := joinPath(path, fmt.Sprint(k))
+			s, ok, err := resolveString(ctx, v.MapIndex(k).String(), key)
+			if err != nil {
+				return err
+			}
+			if ok {
+				v.SetMapIndex(k, reflect.ValueOf(s).Convert(v.Type().Elem()))
+				secrets[key] = true
+			}
+		}
+	case reflect.String:
+		s, ok, err := resolveString(ctx, v.String(), path)
+		if err != nil {
+			return err
+		}
+		if ok {
+			v.SetString(s)
+			secrets[path] = true
+		}
+	}
+	return nil
+}
+
+// resolveString 解析单个值，返回值不是密钥引用时 ok 为 false
+func resolveString(ctx context.Context, value, path string) (secret string, ok bool, err error) {
+	r, ok := secretResolver(value)
+	if !ok {
+		return value, false, nil
+	}
+	ref, err := url.Parse(value)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid secret reference in %s: %w", path, err)
+	}
+	if secret, err = r.Resolve(ctx, ref); err != nil {
+		// 错误中只包含引用的 scheme 与字段，不包含密钥内容
+		return "", false, fmt.Errorf("failed to resolve %s secret for %s: %w", ref.Scheme, path, err)
+	}
+	return secret, true, nil
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// fieldName 返回字段在配置文件中的名称
+func fieldName(f reflect.StructField) string {
+	if name, _, _ := strings.Cut(f.Tag.Get("yaml"), ","); name != "" {
+		return name
+	}
+	return strings.ToLower(f.Name)
+}
+
+// resolveEnv 解析 env://NAME，变量不存在时视为错误
+func resolveEnv(_ context.Context, ref *url.URL) (string, error) {
+	name := ref.Host + ref.Path
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return v, nil
+}
+
+// resolveFile 解析 file:///path，去掉末尾的换行
+func resolveFile(_ context.Context, ref *url.URL) (string, error) {
+	data, err := os.ReadFile(ref.Host + ref.Path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// VaultConfig HashiCorp Vault KV 解析器配置
+type VaultConfig struct {
+	// Addr Vault 地址，为空时使用环境变量 VAULT_ADDR
+	Addr string
+	// Token 访问令牌，为空时使用环境变量 VAULT_TOKEN
+	Token string
+	// KVVersion KV 引擎版本，为 0 时为 2
+	KVVersion int
+	// Client 为 nil 时使用 http.DefaultClient
+	Client *http.Client
+}
+
+// Vault 返回读取 Vault KV 的解析器，引用格式为 vault://<mount>/<path>#<field>
+func Vault(conf VaultConfig) SecretResolver {
+	return SecretResolverFunc(func(ctx context.Context, ref *url.URL) (string, error) {
+		addr, token := conf.Addr, conf.Token
+		if addr == "" {
+			addr = os.Getenv("VAULT_ADDR")
+		}
+		if token == "" {
+			token = os.Getenv("VAULT_TOKEN")
+		}
+		if addr == "" {
+			return "", fmt.Errorf("vault address is not configured")
+		}
+		if ref.Fragment == "" {
+			return "", fmt.Errorf("vault reference must specify a field after #")
+		}
+		client := conf.Client
+		if client == nil {
+			client = http.DefaultClient
+		}
+
+		// KV v2 的读取路径为 <mount>/data/<path>
+		path := ref.Host + ref.Path
+		if conf.KVVersion != 1 {
+			path = ref.Host + "/data" + ref.Path
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(addr, "/")+"/v1/"+path, nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("X-Vault-Token", token)
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("unexpected status %d from vault", resp.StatusCode)
+		}
+
+		var out struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			return "", fmt.Errorf("failed to decode vault response: %w", err)
+		}
+		data := out.Data
+		if conf.KVVersion != 1 {
+			data, _ = data["data"].(map[string]any)
+		}
+		v, ok := data[ref.Fragment]
+		if !ok {
+			return "", fmt.Errorf("field %s not found", ref.Fragment)
+		}
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		return fmt.Sprint(v), nil
+	})
+}
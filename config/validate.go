@@ -0,0 +1,306 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// FieldError 单个配置项的问题，Field 为配置文件中的字段路径
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError Validate 发现的所有问题
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return "invalid options: " + strings.Join(msgs, "; ")
+}
+
+// validator 收集问题，不在第一个问题处停止
+type validator struct {
+	errs ValidationError
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) duration(field string, d time.Duration) {
+	if d < 0 {
+		v.add(field, "must not be negative")
+	}
+}
+
+func (v *validator) path(field, p string) {
+	if p != "" && !strings.HasPrefix(p, "/") {
+		v.add(field, "must start with /")
+	}
+}
+
+func (v *validator) addr(field, addr string) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		v.add(field, "invalid address %q", addr)
+	}
+}
+
+func (v *validator) ipRanges(field string, values []string) {
+	for i, s := range values {
+		if _, err := netip.ParsePrefix(s); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(s); err != nil {
+			v.add(fmt.Sprintf("%s[%d]", field, i), "invalid IP or CIDR %q", s)
+		}
+	}
+}
+
+func (v *validator) users(field string, users map[string]string) {
+	for _, name := range slices.Sorted(maps.Keys(users)) {
+		if !strings.HasPrefix(users[name], "$2") {
+			v.add(fmt.Sprintf("%s.%s", field, name), "must be a bcrypt hash")
+		}
+	}
+}
+
+func (v *validator) rate(field string, r float64) {
+	if r < 0 || r > 1 {
+		v.add(field, "must be between 0 and 1")
+	}
+}
+
+// Validate 检查配置，一次性返回所有问题（ValidationError），New 会在创建引擎前调用
+func (o *Options) Validate() error {
+	v := &validator{}
+
+	if o.Port < 0 || o.Port > 65535 {
+		v.add("port", "must be between 0 and 65535")
+	}
+	v.duration("read_timeout", o.ReadTimeout)
+	v.duration("write_timeout", o.WriteTimeout)
+	v.duration("shutdown_timeout", o.ShutdownTimeout)
+	v.duration("shutdown_hook_timeout", o.ShutdownHookTimeout)
+	v.duration("drain_delay", o.DrainDelay)
+	v.duration("upgrade_timeout", o.UpgradeTimeout)
+	v.duration("slow_request_threshold", o.SlowRequestThreshold)
+	v.ipRanges("trusted_proxies", o.TrustedProxies)
+
+	switch o.UpgradeStrategy {
+	case "", "tableflip", "graceful", "inprocess":
+	default:
+		v.add("upgrade_strategy", "must be one of tableflip, graceful, inprocess")
+	}
+	if r := o.UpgradeRetry; r != nil {
+		if r.MaxAttempts < 0 {
+			v.add("upgrade_retry.max_attempts", "must not be negative")
+		}
+		v.duration("upgrade_retry.backoff", r.Backoff)
+		v.duration("upgrade_retry.max_backoff", r.MaxBackoff)
+		v.duration("upgrade_retry.stable_window", r.StableWindow)
+		if r.StableWindow > 0 && o.UpgradeStrategy != "graceful" {
+			v.add("upgrade_retry.stable_window", "only supported with upgrade_strategy graceful")
+		}
+	}
+	if d := o.Drain; d != nil {
+		v.duration("drain.hijacked_timeout", d.HijackedTimeout)
+		v.duration("drain.soft_timeout", d.SoftTimeout)
+		if o.ShutdownTimeout > 0 && d.SoftTimeout > o.ShutdownTimeout {
+			v.add("drain.soft_timeout", "must not exceed shutdown_timeout")
+		}
+		if o.ShutdownTimeout > 0 && d.HijackedTimeout > o.ShutdownTimeout {
+			v.add("drain.hijacked_timeout", "must not exceed shutdown_timeout")
+		}
+	}
+
+	if o.Logger == nil {
+		v.add("logger", "is required")
+	} else {
+		if _, err := zapcore.ParseLevel(o.Logger.Level); err != nil {
+			v.add("logger.level", "invalid level %q", o.Logger.Level)
+		}
+		if o.Logger.MaxSize < 0 || o.Logger.MaxAge < 0 || o.Logger.MaxBackups < 0 {
+			v.add("logger", "max_size, max_age and max_backups must not be negative")
+		}
+	}
+	if a := o.AccessLog; a != nil {
+		switch a.Format {
+		case "", "json", "combined":
+		default:
+			v.add("access_log.format", "must be json or combined")
+		}
+		v.rate("access_log.sample_rate", a.SampleRate)
+	}
+
+	v.path("log_level_path", o.LogLevelPath)
+	v.path("metrics_path", o.MetricsPath)
+	v.path("version_path", o.VersionPath)
+	seen := map[string]string{}
+	for _, p := range [][2]string{{"log_level_path", o.LogLevelPath}, {"metrics_path", o.MetricsPath}, {"version_path", o.VersionPath}} {
+		field, path := p[0], p[1]
+		if path == "" {
+			continue
+		}
+		if other, ok := seen[path]; ok {
+			v.add(field, "conflicts with %s (%s)", other, path)
+		}
+		seen[path] = field
+	}
+
+	if a := o.Admin; a != nil {
+		v.addr("admin.addr", a.Addr)
+		if _, port, err := net.SplitHostPort(a.Addr); err == nil && port == fmt.Sprint(o.Port) {
+			v.add("admin.addr", "must not use the same port as port")
+		}
+		v.ipRanges("admin.allow_ips", a.AllowIPs)
+		v.users("admin.users", a.Users)
+	}
+	if p := o.Pprof; p != nil {
+		v.path("pprof.prefix", p.Prefix)
+		if p.Addr != "" {
+			v.addr("pprof.addr", p.Addr)
+			if o.Admin != nil && p.Addr == o.Admin.Addr {
+				v.add("pprof.addr", "conflicts with admin.addr")
+			}
+		}
+		v.ipRanges("pprof.allow_ips", p.AllowIPs)
+		v.users("pprof.users", p.Users)
+		if !o.EnablePprof {
+			v.add("pprof", "is set but enable_pprof is false")
+		}
+	}
+
+	if h := o.Health; h != nil && h.Enabled {
+		v.path("health.liveness_path", h.LivenessPath)
+		v.path("health.readiness_path", h.ReadinessPath)
+		v.duration("health.timeout", h.Timeout)
+	}
+	if c := o.CORS; c != nil {
+		v.duration("cors.max_age", c.MaxAge)
+		if c.AllowCredentials {
+			for _, origin := range c.AllowOrigins {
+				if origin == "*" {
+					v.add("cors.allow_origins", "must not contain * when allow_credentials is true")
+				}
+			}
+		}
+	}
+	if r := o.RateLimit; r != nil {
+		if r.Rate <= 0 {
+			v.add("rate_limit.rate", "must be positive")
+		}
+		if r.Burst <= 0 {
+			v.add("rate_limit.burst", "must be positive")
+		}
+	}
+	if b := o.BodyLimit; b != nil {
+		if b.Limit < 0 {
+			v.add("body_limit.limit", "must not be negative")
+		}
+		for route, limit := range b.Routes {
+			if limit < 0 {
+				v.add("body_limit.routes."+route, "must not be negative")
+			}
+		}
+	}
+	if s := o.SecureHeaders; s != nil {
+		v.duration("secure_headers.hsts_max_age", s.HSTSMaxAge)
+	}
+	if b := o.BodyCapture; b != nil && b.MaxSize < 0 {
+		v.add("body_capture.max_size", "must not be negative")
+	}
+	if u := o.Update; u != nil {
+		if u.ManifestURL == "" {
+			v.add("update.manifest_url", "is required")
+		}
+		if key, err := base64.StdEncoding.DecodeString(u.PublicKey); err != nil || len(key) != 32 {
+			v.add("update.public_key", "must be a base64 encoded ed25519 public key")
+		}
+		v.duration("update.interval", u.Interval)
+	}
+	if s := o.Sentry; s != nil {
+		v.rate("sentry.sample_rate", s.SampleRate)
+	}
+	if d := o.OpenAPI; d != nil {
+		v.path("openapi.path", d.Path)
+		v.path("openapi.ui_path", d.UIPath)
+		switch d.UI {
+		case "", "swagger", "redoc":
+		default:
+			v.add("openapi.ui", "must be swagger or redoc")
+		}
+		v.ipRanges("openapi.allow_ips", d.AllowIPs)
+		v.users("openapi.users", d.Users)
+	}
+	if d := o.Database; d != nil {
+		if d.Driver == "" {
+			v.add("database.driver", "is required")
+		}
+		if d.DSN == "" {
+			v.add("database.dsn", "is required")
+		}
+		if d.MaxOpenConns < 0 {
+			v.add("database.max_open_conns", "must not be negative")
+		}
+		if d.MaxIdleConns < 0 {
+			v.add("database.max_idle_conns", "must not be negative")
+		}
+		v.duration("database.conn_max_lifetime", d.ConnMaxLifetime)
+		v.duration("database.conn_max_idle_time", d.ConnMaxIdleTime)
+		v.duration("database.ping_timeout", d.PingTimeout)
+	}
+	if r := o.Redis; r != nil {
+		if len(r.Addrs) == 0 {
+			v.add("redis.addrs", "is required")
+		}
+		if r.Cluster && r.MasterName != "" {
+			v.add("redis.cluster", "cannot be combined with master_name")
+		}
+		if r.Cluster && r.DB != 0 {
+			v.add("redis.db", "is not supported in cluster mode")
+		}
+		if r.DB < 0 {
+			v.add("redis.db", "must not be negative")
+		}
+		v.duration("redis.dial_timeout", r.DialTimeout)
+		v.duration("redis.read_timeout", r.ReadTimeout)
+		v.duration("redis.write_timeout", r.WriteTimeout)
+		v.duration("redis.slow_threshold", r.SlowThreshold)
+		if t := r.TLS; t != nil && (t.CertFile == "") != (t.KeyFile == "") {
+			v.add("redis.tls", "cert_file and key_file must be set together")
+		}
+	}
+	if a := o.API; a != nil {
+		v.path("api.prefix", a.Prefix)
+		for _, version := range slices.Sorted(maps.Keys(a.Deprecated)) {
+			d := a.Deprecated[version]
+			if version == "" || strings.Contains(version, "/") {
+				v.add("api.deprecated", "invalid version %q", version)
+			}
+			if !d.Date.IsZero() && !d.Sunset.IsZero() && d.Sunset.Before(d.Date) {
+				v.add("api.deprecated."+version+".sunset", "must not be before date")
+			}
+		}
+	}
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
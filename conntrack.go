@@ -0,0 +1,145 @@
+package ginx
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gaoxin19/ginx/metrics"
+)
+
+// ConnStats 各状态的连接数
+type ConnStats struct {
+	New      int `json:"new"`
+	Active   int `json:"active"`
+	Idle     int `json:"idle"`
+	Hijacked int `json:"hijacked"`
+}
+
+// connTracker 通过 http.Server.ConnState 与监听器包装跟踪连接状态，
+// 被劫持的连接不再回调 ConnState，需依赖包装后的 Close 感知关闭
+type connTracker struct {
+	mu    sync.Mutex
+	conns map[net.Conn]http.ConnState
+}
+
+func newConnTracker() *connTracker {
+	return &connTracker{conns: make(map[net.Conn]http.ConnState)}
+}
+
+// listener 包装监听器以感知连接关闭
+func (t *connTracker) listener(ln net.Listener) net.Listener {
+	return &trackedListener{Listener: ln, tracker: t}
+}
+
+// hook 返回 ConnState 回调，next 为 server 原有的回调
+func (t *connTracker) hook(next func(net.Conn, http.ConnState)) func(net.Conn, http.ConnState) {
+	return func(c net.Conn, state http.ConnState) {
+		t.set(c, state)
+		if next != nil {
+			next(c, state)
+		}
+	}
+}
+
+func (t *connTracker) set(c net.Conn, state http.ConnState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev, ok := t.conns[c]
+	if ok {
+		if prev == state {
+			return
+		}
+		metrics.Connections.WithLabelValues(prev.String()).Dec()
+	}
+	if state == http.StateClosed {
+		delete(t.conns, c)
+		return
+	}
+	t.conns[c] = state
+	metrics.Connections.WithLabelValues(state.String()).Inc()
+}
+
+// Stats 返回当前连接统计
+func (t *connTracker) Stats() ConnStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var s ConnStats
+	for _, state := range t.conns {
+		switch state {
+		case http.StateNew:
+			s.New++
+		case http.StateActive:
+			s.Active++
+		case http.StateIdle:
+			s.Idle++
+		case http.StateHijacked:
+			s.Hijacked++
+		}
+	}
+	return s
+}
+
+// waitHijacked 等待被劫持的连接关闭，超时后强制关闭剩余连接
+func (t *connTracker) waitHijacked(ctx context.Context, timeout time.Duration) int {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for t.Stats().Hijacked > 0 {
+		select {
+		case <-ctx.Done():
+			return t.closeHijacked()
+		case <-ticker.C:
+		}
+	}
+	return 0
+}
+
+func (t *connTracker) closeHijacked() int {
+	t.mu.Lock()
+	var conns []net.Conn
+	for c, state := range t.conns {
+		if state == http.StateHijacked {
+			conns = append(conns, c)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, c := range conns {
+		c.Close()
+	}
+	return len(conns)
+}
+
+type trackedListener struct {
+	net.Listener
+	tracker *connTracker
+}
+
+func (l *trackedListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &trackedConn{Conn: c, tracker: l.tracker}, nil
+}
+
+type trackedConn struct {
+	net.Conn
+	tracker *connTracker
+	once    sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() { c.tracker.set(c, http.StateClosed) })
+	return c.Conn.Close()
+}
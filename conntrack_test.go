@@ -0,0 +1,61 @@
+package ginx
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestConnTrackerBehindMux(t *testing.T) {
+	e := newTestEngine(t, nil)
+	e.AddMuxServer("grpc", &http.Server{})
+
+	hijacked := make(chan struct{})
+	release := make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		close(hijacked)
+		<-release
+		conn.Close()
+	})}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lns := make([]net.Listener, len(e.extraServers()))
+	httpLn := e.httpListener(server, ln, lns)
+	go server.Serve(httpLn)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: test\r\n\r\n")
+
+	select {
+	case <-hijacked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler not reached")
+	}
+	if got := e.Connections(); got.Hijacked != 1 {
+		t.Fatalf("hijacked = %d, want 1", got.Hijacked)
+	}
+
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	for e.Connections() != (ConnStats{}) {
+		if time.Now().After(deadline) {
+			t.Fatalf("connections after close = %+v, want none", e.Connections())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
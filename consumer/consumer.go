@@ -0,0 +1,188 @@
+// Package consumer 提供与服务生命周期绑定的消息队列消费者，支持 Kafka、NATS 与 RabbitMQ。
+//
+// 每条消息交给处理函数处理，失败时按退避策略重试，panic 会被恢复并记录，处理结果记录日志与指标。
+// 消费者实现 ginx.Runner，通过 engine.AddRunner(name, consumer) 接入 ginx：服务就绪后开始消费，
+// 关闭或升级时停止拉取新消息并等待处理中的消息完成。
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/metrics"
+)
+
+// 默认配置
+const (
+	DefaultBackoff    = 100 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// errStopped 重试等待期间消费者被停止
+var errStopped = errors.New("consumer stopped")
+
+// Handler 消息处理函数，ctx 在关闭超时后被取消
+type Handler[M any] func(ctx context.Context, msg M) error
+
+// Options 消费者配置
+type Options struct {
+	// Name 消费者名称，用于日志与指标，默认为 topic、subject 或队列名
+	Name string
+	// Retries 处理失败后的重试次数
+	Retries int
+	// Backoff 首次重试前的等待时间，之后每次翻倍，默认 100ms
+	Backoff time.Duration
+	// MaxBackoff 重试等待时间上限，默认 30s
+	MaxBackoff time.Duration
+	// Timeout 单次处理的超时，为 0 时不限制
+	Timeout time.Duration
+	// Logger 为空时不记录日志，通常传入 engine.Logger()
+	Logger *zap.Logger
+}
+
+// base 各消费者共用的停止与处理逻辑
+type base struct {
+	opts    Options
+	started atomic.Bool
+	stopped chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newBase(opts Options, name string) *base {
+	if opts.Name == "" {
+		opts.Name = name
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	opts.Logger = opts.Logger.With(zap.String("consumer", opts.Name))
+	return &base{opts: opts, stopped: make(chan struct{}), done: make(chan struct{})}
+}
+
+// Name 返回消费者名称
+func (b *base) Name() string {
+	return b.opts.Name
+}
+
+// start 标记开始运行，重复调用 Run 时返回错误
+func (b *base) start() error {
+	if !b.started.CompareAndSwap(false, true) {
+		return fmt.Errorf("consumer %s already running", b.opts.Name)
+	}
+	b.opts.Logger.Info("Consumer started")
+	return nil
+}
+
+// Stop 停止拉取新消息并等待处理中的消息完成，ctx 结束时返回 ctx 的错误
+func (b *base) Stop(ctx context.Context) error {
+	b.once.Do(func() { close(b.stopped) })
+	if !b.started.Load() {
+		return nil
+	}
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		b.opts.Logger.Warn("Consumer did not stop before shutdown timeout")
+		return fmt.Errorf("consumer %s: %w", b.opts.Name, ctx.Err())
+	}
+}
+
+// stopContext 返回在 Stop 被调用时取消的 ctx，用于中断阻塞的拉取
+func (b *base) stopContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-b.stopped:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// handle 处理一条消息，失败时按退避策略重试，Stop 之后或 ctx 取消后不再重试
+func handle[M any](ctx context.Context, b *base, h Handler[M], msg M) error {
+	start := time.Now()
+	defer func() {
+		metrics.ConsumerDuration.WithLabelValues(b.opts.Name).Observe(time.Since(start).Seconds())
+	}()
+
+	var err error
+	for attempt := 0; attempt <= b.opts.Retries; attempt++ {
+		if attempt > 0 {
+			backoff := b.opts.Backoff << (attempt - 1)
+			if backoff > b.opts.MaxBackoff || backoff <= 0 {
+				backoff = b.opts.MaxBackoff
+			}
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-b.stopped:
+				timer.Stop()
+				return errors.Join(err, errStopped)
+			case <-ctx.Done():
+				timer.Stop()
+				return errors.Join(err, ctx.Err())
+			}
+		}
+
+		if err = handleOnce(ctx, b, h, msg); err == nil {
+			metrics.ConsumerMessages.WithLabelValues(b.opts.Name, "succeeded").Inc()
+			return nil
+		}
+		if attempt < b.opts.Retries {
+			metrics.ConsumerMessages.WithLabelValues(b.opts.Name, "retried").Inc()
+			b.opts.Logger.Warn("Message handling failed, retrying", zap.Int("attempt", attempt+1), zap.Error(err))
+		}
+	}
+	return err
+}
+
+// report 记录处理结果，返回 true 表示处理因停止而中断，消息应重新投递而不是确认
+func (b *base) report(ctx context.Context, err error, fields ...zap.Field) (redeliver bool) {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, errStopped) || ctx.Err() != nil:
+		b.opts.Logger.Warn("Message handling interrupted by shutdown", append(fields, zap.Error(err))...)
+		return true
+	default:
+		metrics.ConsumerMessages.WithLabelValues(b.opts.Name, "failed").Inc()
+		b.opts.Logger.Error("Message handling failed", append(fields, zap.Error(err))...)
+		return false
+	}
+}
+
+// handleOnce 执行一次处理函数并恢复 panic
+func handleOnce[M any](ctx context.Context, b *base, h Handler[M], msg M) (err error) {
+	if b.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.opts.Timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			b.opts.Logger.Error("Message handler panic",
+				zap.Any("panic", r),
+				zap.ByteString("stack", debug.Stack()),
+			)
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h(ctx, msg)
+}
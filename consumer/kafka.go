@@ -0,0 +1,58 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// Kafka 基于 kafka-go Reader 的消费者，处理完成后提交偏移量；
+// 重试后仍失败的消息记录日志后同样提交，避免阻塞整个分区，因停止而中断的消息不提交
+type Kafka struct {
+	*base
+	reader  *kafka.Reader
+	handler Handler[kafka.Message]
+}
+
+// NewKafka 创建 Kafka 消费者，reader 需配置 GroupID 以便提交偏移量，停止时会被关闭
+func NewKafka(reader *kafka.Reader, h Handler[kafka.Message], opts Options) *Kafka {
+	return &Kafka{
+		base:    newBase(opts, reader.Config().Topic),
+		reader:  reader,
+		handler: h,
+	}
+}
+
+// Run 持续拉取并处理消息，直到 Stop 被调用
+func (c *Kafka) Run(ctx context.Context) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	defer close(c.done)
+	defer c.reader.Close()
+
+	fetchCtx, cancel := c.stopContext(ctx)
+	defer cancel()
+	for {
+		msg, err := c.reader.FetchMessage(fetchCtx)
+		if err != nil {
+			if fetchCtx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to fetch kafka message: %w", err)
+		}
+
+		err = handle(ctx, c.base, c.handler, msg)
+		if c.report(ctx, err, zap.String("topic", msg.Topic), zap.Int("partition", msg.Partition), zap.Int64("offset", msg.Offset)) {
+			// 不提交，由接管分区的实例重新消费
+			return nil
+		}
+		// 处理函数执行期间收到 Stop 时仍提交已处理的消息
+		if err := c.reader.CommitMessages(ctx, msg); err != nil && !errors.Is(err, context.Canceled) {
+			return fmt.Errorf("failed to commit kafka offset: %w", err)
+		}
+	}
+}
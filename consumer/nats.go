@@ -0,0 +1,76 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// NATS 基于 NATS 订阅的消费者，设置队列组时同组实例之间负载均衡；
+// 消息带有 JetStream 元数据时，处理成功后 Ack、失败后 Nak 以便重新投递
+type NATS struct {
+	*base
+	conn    *nats.Conn
+	subject string
+	queue   string
+	handler Handler[*nats.Msg]
+}
+
+// NewNATS 创建 NATS 消费者，queue 为空时每个实例都会收到所有消息
+func NewNATS(conn *nats.Conn, subject, queue string, h Handler[*nats.Msg], opts Options) *NATS {
+	return &NATS{
+		base:    newBase(opts, subject),
+		conn:    conn,
+		subject: subject,
+		queue:   queue,
+		handler: h,
+	}
+}
+
+// Run 订阅 subject 并处理消息，直到 Stop 被调用；停止时取消订阅，不关闭连接
+func (c *NATS) Run(ctx context.Context) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	defer close(c.done)
+
+	sub, err := c.conn.QueueSubscribeSync(c.subject, c.queue)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe nats subject %s: %w", c.subject, err)
+	}
+	defer sub.Unsubscribe()
+
+	fetchCtx, cancel := c.stopContext(ctx)
+	defer cancel()
+	for {
+		msg, err := sub.NextMsgWithContext(fetchCtx)
+		if err != nil {
+			if fetchCtx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to receive nats message: %w", err)
+		}
+
+		err = handle(ctx, c.base, c.handler, msg)
+		c.report(ctx, err, zap.String("subject", msg.Subject))
+		if _, merr := msg.Metadata(); merr == nil {
+			c.ack(msg, err)
+		}
+	}
+}
+
+// ack 确认 JetStream 消息，处理函数已自行确认时忽略重复确认的错误
+func (c *NATS) ack(msg *nats.Msg, handleErr error) {
+	var err error
+	if handleErr == nil {
+		err = msg.Ack()
+	} else {
+		err = msg.Nak()
+	}
+	if err != nil && !errors.Is(err, nats.ErrMsgAlreadyAckd) {
+		c.opts.Logger.Warn("Failed to acknowledge nats message", zap.String("subject", msg.Subject), zap.Error(err))
+	}
+}
@@ -0,0 +1,86 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// RabbitMQ 基于 amqp091 的消费者，处理成功后 Ack，重试后仍失败时 Nack 且不重新入队，
+// 需要保留失败消息时为队列配置死信交换机；因停止而中断的消息重新入队
+type RabbitMQ struct {
+	*base
+	ch      *amqp.Channel
+	queue   string
+	tag     string
+	handler Handler[amqp.Delivery]
+}
+
+// NewRabbitMQ 创建 RabbitMQ 消费者，并发度由 ch.Qos 的预取数量控制，消息按顺序逐条处理
+func NewRabbitMQ(ch *amqp.Channel, queue string, h Handler[amqp.Delivery], opts Options) *RabbitMQ {
+	b := newBase(opts, queue)
+	return &RabbitMQ{
+		base:    b,
+		ch:      ch,
+		queue:   queue,
+		tag:     fmt.Sprintf("ginx-%s-%p", b.opts.Name, b),
+		handler: h,
+	}
+}
+
+// Run 消费队列并处理消息，直到 Stop 被调用；停止时取消消费，已预取未处理的消息重新入队，不关闭通道
+func (c *RabbitMQ) Run(ctx context.Context) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	defer close(c.done)
+
+	deliveries, err := c.ch.Consume(c.queue, c.tag, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume rabbitmq queue %s: %w", c.queue, err)
+	}
+
+	for {
+		select {
+		case <-c.stopped:
+			return c.cancel(deliveries)
+		case d, ok := <-deliveries:
+			if !ok {
+				select {
+				case <-c.stopped:
+					return nil
+				default:
+				}
+				return fmt.Errorf("rabbitmq delivery channel of queue %s closed", c.queue)
+			}
+			c.process(ctx, d)
+		}
+	}
+}
+
+func (c *RabbitMQ) process(ctx context.Context, d amqp.Delivery) {
+	err := handle(ctx, c.base, c.handler, d)
+	if err == nil {
+		if aerr := d.Ack(false); aerr != nil {
+			c.opts.Logger.Warn("Failed to ack rabbitmq message", zap.Uint64("delivery_tag", d.DeliveryTag), zap.Error(aerr))
+		}
+		return
+	}
+	requeue := c.report(ctx, err, zap.String("queue", c.queue), zap.Uint64("delivery_tag", d.DeliveryTag))
+	if nerr := d.Nack(false, requeue); nerr != nil {
+		c.opts.Logger.Warn("Failed to nack rabbitmq message", zap.Uint64("delivery_tag", d.DeliveryTag), zap.Error(nerr))
+	}
+}
+
+// cancel 取消消费并将已预取的消息重新入队
+func (c *RabbitMQ) cancel(deliveries <-chan amqp.Delivery) error {
+	if err := c.ch.Cancel(c.tag, false); err != nil {
+		return fmt.Errorf("failed to cancel rabbitmq consumer: %w", err)
+	}
+	for d := range deliveries {
+		d.Nack(false, true)
+	}
+	return nil
+}
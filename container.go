@@ -0,0 +1,212 @@
+package ginx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/config"
+	"github.com/gaoxin19/ginx/health"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Container 轻量的依赖注入容器：构造函数的参数按类型从容器中获取，返回值按类型注册，
+// 每个类型只构造一次；通过 ProvideGroup 注册的构造函数返回值以切片类型（如 []Module）注入
+type Container struct {
+	mu        sync.Mutex
+	providers map[reflect.Type]*provider
+	groups    map[reflect.Type][]*provider
+}
+
+// provider 一个构造函数及其构造结果
+type provider struct {
+	fn       reflect.Value
+	values   []reflect.Value
+	built    bool
+	building bool
+}
+
+// NewContainer 创建容器，并预置由 *Engine 派生的 *zap.Logger 与 *health.Checker
+func NewContainer() *Container {
+	c := &Container{
+		providers: make(map[reflect.Type]*provider),
+		groups:    make(map[reflect.Type][]*provider),
+	}
+	_ = c.Provide(
+		func(e *Engine) *zap.Logger { return e.Logger() },
+		func(e *Engine) *health.Checker { return e.Health() },
+	)
+	return c
+}
+
+// Provide 注册构造函数，构造函数可返回多个值，最后一个返回值可以是 error；
+// 同一类型只能由一个构造函数提供
+func (c *Container) Provide(constructors ...any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ctor := range constructors {
+		p, types, err := newProvider(ctor)
+		if err != nil {
+			return err
+		}
+		for _, t := range types {
+			if _, ok := c.providers[t]; ok {
+				return fmt.Errorf("type %s already provided", t)
+			}
+		}
+		for _, t := range types {
+			c.providers[t] = p
+		}
+	}
+	return nil
+}
+
+// ProvideGroup 注册返回单个值的构造函数，同一类型的所有构造结果以切片注入，如 []Module
+func (c *Container) ProvideGroup(constructors ...any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ctor := range constructors {
+		p, types, err := newProvider(ctor)
+		if err != nil {
+			return err
+		}
+		if len(types) != 1 {
+			return fmt.Errorf("group constructor %s must return exactly one value", p.fn.Type())
+		}
+		c.groups[types[0]] = append(c.groups[types[0]], p)
+	}
+	return nil
+}
+
+// Supply 注册已构造好的值，如 *config.Options 或测试中的替身
+func (c *Container) Supply(values ...any) error {
+	for _, v := range values {
+		rv := reflect.ValueOf(v)
+		fn := reflect.MakeFunc(reflect.FuncOf(nil, []reflect.Type{rv.Type()}, false), func([]reflect.Value) []reflect.Value {
+			return []reflect.Value{rv}
+		})
+		if err := c.Provide(fn.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invoke 按类型解析 fn 的参数后调用 fn，fn 的最后一个返回值为 error 时返回该错误
+func (c *Container) Invoke(fn any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func {
+		return fmt.Errorf("invoke target must be a function, got %T", fn)
+	}
+	_, err := c.call(fv)
+	return err
+}
+
+// Run 构造 *Engine，注册容器中的所有 Module 并运行，直到 ctx 被取消或收到退出信号
+func (c *Container) Run(ctx context.Context) error {
+	return c.Invoke(func(e *Engine, modules []Module) error {
+		if err := e.Register(modules...); err != nil {
+			return err
+		}
+		return e.RunContext(ctx)
+	})
+}
+
+// ProvideEngine 注册以 *config.Options 构造 *Engine 的构造函数
+func (c *Container) ProvideEngine() error {
+	return c.Provide(func(opts *config.Options) (*Engine, error) { return New(opts) })
+}
+
+func newProvider(ctor any) (*provider, []reflect.Type, error) {
+	fv := reflect.ValueOf(ctor)
+	if fv.Kind() != reflect.Func {
+		return nil, nil, fmt.Errorf("constructor must be a function, got %T", ctor)
+	}
+	var types []reflect.Type
+	for i := 0; i < fv.Type().NumOut(); i++ {
+		t := fv.Type().Out(i)
+		if t == errorType && i == fv.Type().NumOut()-1 {
+			continue
+		}
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		return nil, nil, fmt.Errorf("constructor %s provides no values", fv.Type())
+	}
+	return &provider{fn: fv}, types, nil
+}
+
+// call 解析参数并调用 fn，返回去掉 error 之后的返回值
+func (c *Container) call(fn reflect.Value) ([]reflect.Value, error) {
+	ft := fn.Type()
+	args := make([]reflect.Value, ft.NumIn())
+	for i := range args {
+		v, err := c.resolve(ft.In(i))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ft, err)
+		}
+		args[i] = v
+	}
+
+	out := fn.Call(args)
+	if n := len(out); n > 0 && ft.Out(n-1) == errorType {
+		if err, _ := out[n-1].Interface().(error); err != nil {
+			return nil, err
+		}
+		out = out[:n-1]
+	}
+	return out, nil
+}
+
+func (c *Container) resolve(t reflect.Type) (reflect.Value, error) {
+	if p, ok := c.providers[t]; ok {
+		values, err := c.build(p)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		for _, v := range values {
+			if v.Type() == t {
+				return v, nil
+			}
+		}
+	}
+	if t.Kind() == reflect.Slice {
+		group := reflect.MakeSlice(t, 0, len(c.groups[t.Elem()]))
+		for _, p := range c.groups[t.Elem()] {
+			values, err := c.build(p)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			group = reflect.Append(group, values[0])
+		}
+		return group, nil
+	}
+	return reflect.Value{}, fmt.Errorf("no provider for %s", t)
+}
+
+// build 构造一次 provider，检测循环依赖
+func (c *Container) build(p *provider) ([]reflect.Value, error) {
+	if p.built {
+		return p.values, nil
+	}
+	if p.building {
+		return nil, errors.New("dependency cycle detected at " + strings.TrimPrefix(p.fn.Type().String(), "func"))
+	}
+	p.building = true
+	defer func() { p.building = false }()
+
+	values, err := c.call(p.fn)
+	if err != nil {
+		return nil, err
+	}
+	p.values, p.built = values, true
+	return values, nil
+}
@@ -0,0 +1,41 @@
+package ginx
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/middleware"
+)
+
+const loggerKey = "ginx.logger"
+
+// Ctx 返回带有请求关联字段（request_id、trace_id、route、ip）的日志实例，
+// 同一请求内多次调用返回同一实例
+func Ctx(c *gin.Context) *zap.Logger {
+	if v, ok := c.Get(loggerKey); ok {
+		if logger, ok := v.(*zap.Logger); ok {
+			return logger
+		}
+	}
+
+	fields := make([]zap.Field, 0, 4)
+	if id := middleware.GetRequestID(c); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if traceID := middleware.TraceID(c); traceID != "" {
+		fields = append(fields, zap.String("trace_id", traceID))
+	}
+	fields = append(fields,
+		zap.String("route", c.FullPath()),
+		zap.String("ip", middleware.ClientIP(c)),
+	)
+
+	logger := L().With(fields...)
+	c.Set(loggerKey, logger)
+	return logger
+}
+
+// TraceID 从 W3C traceparent 请求头中解析 trace id，不存在时返回空字符串
+func TraceID(c *gin.Context) string {
+	return middleware.TraceID(c)
+}
@@ -0,0 +1,248 @@
+package ginx
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ControlResponse 控制套接字的响应，每条命令返回一行 JSON
+type ControlResponse struct {
+	OK    bool   `json:"ok"`
+	Data  any    `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ControlStatus status 命令返回的进程状态
+type ControlStatus struct {
+	Build       BuildInfo     `json:"build"`
+	Connections ConnStats     `json:"connections"`
+	LogLevel    string        `json:"log_level"`
+	Draining    bool          `json:"draining"`
+	Upgrade     UpgradeStatus `json:"upgrade"`
+}
+
+// ControlRoute routes 命令返回的路由信息
+type ControlRoute = RouteInfo
+
+// controlCommands 控制套接字支持的命令
+var controlCommands = []string{"status", "connections", "routes", "runtime", "config", "loglevel [level]", "upgrade [binary sha256]", "shutdown", "help"}
+
+// controlSocket 基于 Unix 套接字的文本命令接口，每行一条命令
+type controlSocket struct {
+	engine *Engine
+	path   string
+	wg     sync.WaitGroup
+
+	// mu 保护 ln 与 info，升级失败后会替换为重新绑定的监听器
+	mu   sync.Mutex
+	ln   *net.UnixListener
+	info os.FileInfo
+}
+
+// setupControlSocket 注册启动与关闭钩子，启动时监听控制套接字，关闭时停止监听
+func (e *Engine) setupControlSocket(path string) {
+	cs := &controlSocket{engine: e, path: path}
+	e.control = cs
+	e.RegisterOnStart("control-socket", cs.start)
+	e.RegisterOnShutdown("control-socket", cs.stop)
+}
+
+func (cs *controlSocket) start(ctx context.Context) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if err := cs.listen(); err != nil {
+		return err
+	}
+	cs.engine.logger.Info("Control socket is listening", zap.String("path", cs.path))
+	return nil
+}
+
+// listen 绑定套接字文件并开始接受连接，调用前需持有 mu
+func (cs *controlSocket) listen() error {
+	// 升级时新进程接管套接字文件，旧进程的监听器继续服务已有连接直到关闭
+	if err := os.Remove(cs.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale control socket: %w", err)
+	}
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: cs.path, Net: "unix"})
+	if err != nil {
+		return fmt.Errorf("failed to listen control socket: %w", err)
+	}
+	ln.SetUnlinkOnClose(false)
+	if err := os.Chmod(cs.path, 0600); err != nil {
+		ln.Close()
+		return fmt.Errorf("failed to chmod control socket: %w", err)
+	}
+	info, err := os.Stat(cs.path)
+	if err != nil {
+		ln.Close()
+		return fmt.Errorf("failed to stat control socket: %w", err)
+	}
+	cs.ln, cs.info = ln, info
+
+	cs.wg.Add(1)
+	go cs.accept(ln)
+	return nil
+}
+
+// owned 判断套接字文件是否仍是当前进程创建的，调用前需持有 mu
+func (cs *controlSocket) owned() bool {
+	info, err := os.Stat(cs.path)
+	return err == nil && os.SameFile(info, cs.info)
+}
+
+// restore 升级失败后重新绑定套接字文件：新进程启动时已替换该文件，退出时又将其删除，
+// 当前进程的监听器虽仍打开但已无法通过路径访问
+func (cs *controlSocket) restore() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.ln == nil || cs.owned() {
+		return
+	}
+	old := cs.ln
+	if err := cs.listen(); err != nil {
+		cs.engine.logger.Error("Failed to restore control socket", zap.String("path", cs.path), zap.Error(err))
+		return
+	}
+	old.Close()
+	cs.engine.logger.Info("Control socket restored after failed upgrade", zap.String("path", cs.path))
+}
+
+func (cs *controlSocket) stop(ctx context.Context) error {
+	cs.mu.Lock()
+	if cs.ln == nil {
+		cs.mu.Unlock()
+		return nil
+	}
+	cs.ln.Close()
+	// 只删除自己创建的套接字文件，避免删掉升级后新进程的套接字
+	if cs.owned() {
+		os.Remove(cs.path)
+	}
+	cs.ln = nil
+	cs.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		cs.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (cs *controlSocket) accept(ln *net.UnixListener) {
+	defer cs.wg.Done()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				cs.engine.logger.Error("Control socket accept error", zap.Error(err))
+			}
+			return
+		}
+		cs.wg.Add(1)
+		go cs.handle(conn)
+	}
+}
+
+// handle 逐行读取命令并写回 JSON 响应，连接空闲超过一分钟时断开
+func (cs *controlSocket) handle(conn net.Conn) {
+	defer cs.wg.Done()
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+	for {
+		conn.SetDeadline(time.Now().Add(time.Minute))
+		if !scanner.Scan() {
+			return
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		data, err := cs.engine.execControl(fields[0], fields[1:])
+		resp := ControlResponse{OK: err == nil, Data: data}
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+		if fields[0] == "shutdown" && err == nil {
+			cs.engine.Shutdown()
+			return
+		}
+	}
+}
+
+// status 返回进程状态，供控制套接字与管理端口使用
+func (e *Engine) status() ControlStatus {
+	return ControlStatus{
+		Build:       GetBuildInfo(),
+		Connections: e.Connections(),
+		LogLevel:    e.logLevel.Level().String(),
+		Draining:    e.health.Draining(),
+		Upgrade:     e.UpgradeStatus(),
+	}
+}
+
+// execControl 执行一条控制命令
+func (e *Engine) execControl(cmd string, args []string) (any, error) {
+	switch cmd {
+	case "status":
+		return e.status(), nil
+	case "connections":
+		return e.Connections(), nil
+	case "routes":
+		return e.RoutesInfo(), nil
+	case "runtime":
+		return ReadRuntimeStats(), nil
+	case "config":
+		return e.opts().Dump(), nil
+	case "loglevel":
+		if len(args) > 0 {
+			if err := e.logLevel.UnmarshalText([]byte(args[0])); err != nil {
+				return nil, fmt.Errorf("invalid log level %q", args[0])
+			}
+			e.logger.Info("Log level changed via control socket", zap.String("level", args[0]))
+		}
+		return e.logLevel.Level().String(), nil
+	case "upgrade":
+		e.logger.Info("Upgrade requested via control socket")
+		var err error
+		switch len(args) {
+		case 0:
+			err = e.Upgrade()
+		case 2:
+			err = e.UpgradeTo(args[0], args[1])
+		default:
+			return nil, errors.New("usage: upgrade [binary sha256]")
+		}
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"pid": os.Getpid()}, nil
+	case "shutdown":
+		e.logger.Info("Shutdown requested via control socket")
+		return map[string]int{"pid": os.Getpid()}, nil
+	case "help":
+		return controlCommands, nil
+	}
+	return nil, fmt.Errorf("unknown command %q", cmd)
+}
@@ -0,0 +1,76 @@
+package ginx
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gaoxin19/ginx/config"
+)
+
+func controlCall(t *testing.T, path, cmd string) ControlResponse {
+	t.Helper()
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("dial control socket: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(cmd + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp ControlResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestControlSocketRestoredAfterFailedUpgrade(t *testing.T) {
+	// Unix 套接字路径有长度限制，不使用 t.TempDir
+	dir, err := os.MkdirTemp("", "ginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ctl.sock")
+
+	e := newTestEngine(t, func(o *config.Options) { o.ControlSocket = path })
+	ctx := context.Background()
+	if err := e.control.start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer e.control.stop(ctx)
+
+	// 模拟新进程：启动时接管套接字文件，在稳定窗口内退出时删除该文件
+	child := &controlSocket{engine: e, path: path}
+	if err := child.start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("socket file still exists after child exit: %v", err)
+	}
+
+	e.onUpgradeFailure(1, errors.New("new process exited within stable window"))
+	if resp := controlCall(t, path, "loglevel"); !resp.OK {
+		t.Fatalf("control socket not restored: %+v", resp)
+	}
+
+	if err := e.control.stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("restored socket file not removed on stop: %v", err)
+	}
+}
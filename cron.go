@@ -0,0 +1,11 @@
+package ginx
+
+import (
+	"github.com/gaoxin19/ginx/cron"
+)
+
+// UseCron 在服务启动后开始调度定时任务，关闭时停止调度并等待执行中的任务结束
+func (e *Engine) UseCron(s *cron.Scheduler) {
+	e.RegisterOnStart("cron", s.Start)
+	e.RegisterOnShutdown("cron", s.Stop)
+}
@@ -0,0 +1,275 @@
+// Package cron 提供与服务生命周期绑定的定时任务。
+//
+// 任务按 cron 表达式调度（支持可选的秒字段、@every 1m、@daily 等描述符与 TZ= 前缀），
+// 上一次执行未结束时跳过本次执行，可为每个任务设置超时，执行结果记录日志与指标。
+// 多副本部署时设置 Options.Lock（NewRedisLocker、NewEtcdLocker），每次执行前按任务名加锁，
+// 同一周期只有一个实例执行；执行期间自动续期，持有锁的实例崩溃后锁过期，由其他实例接管。
+// 通过 engine.UseCron(scheduler) 接入 ginx：服务启动后开始调度，关闭时停止调度并等待执行中的任务结束。
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	robfig "github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/metrics"
+)
+
+// parser 支持 5 段或带秒的 6 段表达式，以及 @every、@daily 等描述符
+var parser = robfig.NewParser(robfig.SecondOptional | robfig.Minute | robfig.Hour | robfig.Dom | robfig.Month | robfig.Dow | robfig.Descriptor)
+
+// Job 定时任务，ctx 在任务超时、关闭超时或分布式锁丢失后被取消
+type Job func(ctx context.Context) error
+
+// Options 调度器配置
+type Options struct {
+	// Location 解析表达式使用的时区，默认 time.Local
+	Location *time.Location
+	// Timeout 任务默认的单次执行超时，为 0 时不限制
+	Timeout time.Duration
+	// Logger 为空时不记录日志
+	Logger *zap.Logger
+	// Lock 分布式锁，为空时每个实例都会执行任务
+	Lock Locker
+	// LockTTL 锁的有效期，持有锁的实例崩溃后最多经过该时间由其他实例接管，默认 30s
+	LockTTL time.Duration
+	// LockPrefix 锁的键前缀，默认 ginx:cron:
+	LockPrefix string
+	// LockHold 任务结束后继续持有锁的时间，吸收各实例之间的时钟偏差，默认 5s，小于 0 时立即释放
+	LockHold time.Duration
+}
+
+// JobOption 单个任务的选项
+type JobOption func(*entry)
+
+// WithTimeout 设置任务单次执行的超时
+func WithTimeout(d time.Duration) JobOption {
+	return func(e *entry) {
+		e.timeout = d
+	}
+}
+
+// AllowOverlap 允许上一次执行未结束时开始新的执行
+func AllowOverlap() JobOption {
+	return func(e *entry) {
+		e.overlap = true
+	}
+}
+
+// WithoutLock 任务不使用分布式锁，每个实例都会执行
+func WithoutLock() JobOption {
+	return func(e *entry) {
+		e.noLock = true
+	}
+}
+
+// Entry 任务的调度状态
+type Entry struct {
+	Name    string    `json:"name"`
+	Spec    string    `json:"spec"`
+	Next    time.Time `json:"next"`
+	Prev    time.Time `json:"prev"`
+	Running bool      `json:"running"`
+}
+
+type entry struct {
+	name    string
+	spec    string
+	job     Job
+	timeout time.Duration
+	overlap bool
+	noLock  bool
+	id      robfig.EntryID
+	running atomic.Int32
+}
+
+// Scheduler 定时任务调度器
+type Scheduler struct {
+	opts Options
+	cron *robfig.Cron
+
+	mu      sync.Mutex
+	entries []*entry
+	started bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New 创建调度器
+func New(opts Options) *Scheduler {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	if opts.LockTTL <= 0 {
+		opts.LockTTL = DefaultLockTTL
+	}
+	if opts.LockPrefix == "" {
+		opts.LockPrefix = DefaultLockPrefix
+	}
+	if opts.LockHold == 0 {
+		opts.LockHold = DefaultLockHold
+	}
+	s := &Scheduler{
+		opts: opts,
+		cron: robfig.New(robfig.WithParser(parser), robfig.WithLocation(opts.Location)),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	return s
+}
+
+// Add 注册任务，name 用于日志与指标，需唯一
+func (s *Scheduler) Add(name, spec string, job Job, opts ...JobOption) error {
+	e := &entry{name: name, spec: spec, job: job, timeout: s.opts.Timeout}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.entries {
+		if existing.name == name {
+			return fmt.Errorf("cron job %q already registered", name)
+		}
+	}
+	id, err := s.cron.AddFunc(spec, func() { s.run(e) })
+	if err != nil {
+		return fmt.Errorf("failed to parse cron spec %q of job %q: %w", spec, name, err)
+	}
+	e.id = id
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+// Entries 返回所有任务的调度状态
+func (s *Scheduler) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		ce := s.cron.Entry(e.id)
+		out = append(out, Entry{
+			Name:    e.name,
+			Spec:    e.spec,
+			Next:    ce.Next,
+			Prev:    ce.Prev,
+			Running: e.running.Load() > 0,
+		})
+	}
+	return out
+}
+
+// Start 开始调度
+func (s *Scheduler) Start(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started {
+		s.started = true
+		s.cron.Start()
+		s.opts.Logger.Info("Cron scheduler started", zap.Int("jobs", len(s.entries)))
+	}
+	return nil
+}
+
+// Stop 停止调度并等待执行中的任务结束；ctx 结束时取消仍在执行的任务并返回 ctx 的错误
+func (s *Scheduler) Stop(ctx context.Context) error {
+	done := s.cron.Stop().Done()
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		s.opts.Logger.Warn("Cron jobs did not finish before shutdown timeout, cancelling")
+		return fmt.Errorf("cron: %w", ctx.Err())
+	}
+}
+
+// run 执行一次任务，上一次未结束且不允许重叠时跳过，设置分布式锁时未获取到锁也跳过
+func (s *Scheduler) run(e *entry) {
+	if !e.overlap && !e.running.CompareAndSwap(0, 1) {
+		metrics.CronRuns.WithLabelValues(e.name, "skipped").Inc()
+		s.opts.Logger.Warn("Cron job still running, skipping", zap.String("job", e.name))
+		return
+	} else if e.overlap {
+		e.running.Add(1)
+	}
+	defer e.running.Add(-1)
+
+	if s.ctx.Err() != nil {
+		return
+	}
+
+	ctx := s.ctx
+	if s.opts.Lock != nil && !e.noLock {
+		lease, err := s.opts.Lock.Acquire(ctx, s.opts.LockPrefix+e.name, s.opts.LockTTL)
+		if err != nil {
+			metrics.CronRuns.WithLabelValues(e.name, "failed").Inc()
+			s.opts.Logger.Error("Failed to acquire cron lock", zap.String("job", e.name), zap.Error(err))
+			return
+		}
+		if lease == nil {
+			metrics.CronRuns.WithLabelValues(e.name, "locked").Inc()
+			s.opts.Logger.Debug("Cron job locked by another instance, skipping", zap.String("job", e.name))
+			return
+		}
+		defer s.releaseLease(e.name, lease)
+
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+		stop := s.keepLease(e.name, lease, func() { cancel(ErrLockLost) })
+		defer stop()
+	}
+
+	start := time.Now()
+	err := s.execute(ctx, e)
+	elapsed := time.Since(start)
+	metrics.CronDuration.WithLabelValues(e.name).Observe(elapsed.Seconds())
+
+	if err != nil {
+		metrics.CronRuns.WithLabelValues(e.name, "failed").Inc()
+		s.opts.Logger.Error("Cron job failed", zap.String("job", e.name), zap.Duration("latency", elapsed), zap.Error(err))
+		return
+	}
+	metrics.CronRuns.WithLabelValues(e.name, "succeeded").Inc()
+	metrics.CronLastSuccess.WithLabelValues(e.name).SetToCurrentTime()
+	s.opts.Logger.Info("Cron job completed", zap.String("job", e.name), zap.Duration("latency", elapsed))
+}
+
+// execute 在超时控制下执行任务并恢复 panic
+func (s *Scheduler) execute(ctx context.Context, e *entry) (err error) {
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s.opts.Logger.Error("Cron job panic",
+				zap.String("job", e.name),
+				zap.Any("panic", r),
+				zap.ByteString("stack", debug.Stack()),
+			)
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	err = e.job(ctx)
+	if err == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", e.timeout)
+	} else if (err == nil || errors.Is(err, context.Canceled)) && errors.Is(context.Cause(ctx), ErrLockLost) {
+		err = ErrLockLost
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package cron
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 分布式锁默认配置
+const (
+	DefaultLockTTL    = 30 * time.Second
+	DefaultLockHold   = 5 * time.Second
+	DefaultLockPrefix = "ginx:cron:"
+)
+
+// ErrLockLost 锁已过期或已被其他实例持有
+var ErrLockLost = errors.New("cron lock lost")
+
+// Locker 分布式锁，多副本部署时保证同一任务同一时刻只在一个实例上执行
+type Locker interface {
+	// Acquire 尝试获取 key 的锁，有效期为 ttl；锁被其他实例持有时返回 nil, nil
+	Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error)
+}
+
+// Lease 已获取的锁，持有锁的实例崩溃后锁在有效期结束时自动释放，由其他实例接管
+type Lease interface {
+	// Refresh 将锁的有效期重置为获取时的 ttl，锁已丢失时返回 ErrLockLost
+	Refresh(ctx context.Context) error
+	// Release 释放锁
+	Release(ctx context.Context) error
+}
+
+// lockToken 返回标识本次加锁的值，包含主机名与进程号便于排查锁的持有者
+func lockToken() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 8)
+	rand.Read(b)
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(b))
+}
+
+// keepLease 在任务执行期间每 ttl/3 续期一次，锁丢失或超过 ttl 未能续期时调用 lost，
+// 返回的函数停止续期
+func (s *Scheduler) keepLease(name string, lease Lease, lost func()) (stop func()) {
+	ttl := s.opts.LockTTL
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		renewed := time.Now()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), ttl/3)
+			err := lease.Refresh(ctx)
+			cancel()
+			switch {
+			case err == nil:
+				renewed = time.Now()
+				continue
+			case errors.Is(err, ErrLockLost) || time.Since(renewed) >= ttl:
+				s.opts.Logger.Warn("Cron lock lost, cancelling job", zap.String("job", name), zap.Error(err))
+				lost()
+				return
+			default:
+				s.opts.Logger.Warn("Failed to refresh cron lock", zap.String("job", name), zap.Error(err))
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// releaseLease 任务结束后继续持有锁 LockHold 再释放，避免各实例时钟偏差导致同一周期重复执行
+func (s *Scheduler) releaseLease(name string, lease Lease) {
+	release := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := lease.Release(ctx); err != nil {
+			s.opts.Logger.Warn("Failed to release cron lock", zap.String("job", name), zap.Error(err))
+		}
+	}
+
+	switch hold := s.opts.LockHold; {
+	case hold < 0:
+		release()
+	case hold < s.opts.LockTTL:
+		time.AfterFunc(hold, release)
+	default:
+		// 由锁的有效期自然释放
+	}
+}
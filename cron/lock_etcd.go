@@ -0,0 +1,169 @@
+package cron
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EtcdLockConfig etcd v3 分布式锁配置，通过 etcd 的 HTTP/JSON 网关访问
+type EtcdLockConfig struct {
+	// Endpoint etcd 地址，如 http://127.0.0.1:2379
+	Endpoint string
+	// Username 与 Password 启用认证时使用
+	Username string
+	Password string
+	// Client 为 nil 时使用 http.DefaultClient
+	Client *http.Client
+}
+
+type etcdLocker struct {
+	conf EtcdLockConfig
+}
+
+// NewEtcdLocker 返回基于 etcd 租约的 Locker：键绑定租约，仅在键不存在时写入，
+// 续期即租约保活，持有者崩溃后租约过期、键被删除
+func NewEtcdLocker(conf EtcdLockConfig) Locker {
+	if conf.Client == nil {
+		conf.Client = http.DefaultClient
+	}
+	conf.Endpoint = strings.TrimRight(conf.Endpoint, "/")
+	return &etcdLocker{conf: conf}
+}
+
+func (l *etcdLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	var grant struct {
+		ID string `json:"ID"`
+	}
+	seconds := int64(math.Ceil(ttl.Seconds()))
+	if err := l.post(ctx, "/v3/lease/grant", map[string]any{"TTL": seconds}, &grant); err != nil {
+		return nil, fmt.Errorf("failed to grant etcd lease: %w", err)
+	}
+	lease := &etcdLease{locker: l, key: key, id: grant.ID}
+
+	var txn struct {
+		Succeeded bool `json:"succeeded"`
+	}
+	err := l.post(ctx, "/v3/kv/txn", map[string]any{
+		"compare": []map[string]any{{
+			"key":             b64(key),
+			"target":          "CREATE",
+			"result":          "EQUAL",
+			"create_revision": "0",
+		}},
+		"success": []map[string]any{{
+			"request_put": map[string]any{"key": b64(key), "value": b64(lockToken()), "lease": grant.ID},
+		}},
+	}, &txn)
+	if err != nil || !txn.Succeeded {
+		lease.Release(context.WithoutCancel(ctx))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire etcd lock %s: %w", key, err)
+	}
+	if !txn.Succeeded {
+		return nil, nil
+	}
+	return lease, nil
+}
+
+type etcdLease struct {
+	locker *etcdLocker
+	key    string
+	id     string
+}
+
+func (l *etcdLease) Refresh(ctx context.Context) error {
+	// keepalive 为流式接口，只读取第一条响应
+	var resp struct {
+		Result struct {
+			TTL string `json:"TTL"`
+		} `json:"result"`
+	}
+	if err := l.locker.post(ctx, "/v3/lease/keepalive", map[string]string{"ID": l.id}, &resp); err != nil {
+		return fmt.Errorf("failed to refresh etcd lock %s: %w", l.key, err)
+	}
+	// 租约已过期时返回的 TTL 为空或 0
+	if resp.Result.TTL == "" || resp.Result.TTL == "0" {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (l *etcdLease) Release(ctx context.Context) error {
+	var resp struct{}
+	if err := l.locker.post(ctx, "/v3/lease/revoke", map[string]string{"ID": l.id}, &resp); err != nil {
+		return fmt.Errorf("failed to release etcd lock %s: %w", l.key, err)
+	}
+	return nil
+}
+
+func (l *etcdLocker) post(ctx context.Context, path string, in, out any) error {
+	body, _ := json.Marshal(in)
+	req, err := l.request(ctx, path, body)
+	if err != nil {
+		return err
+	}
+	return l.do(req, path, out)
+}
+
+func (l *etcdLocker) do(req *http.Request, path string, out any) error {
+	resp, err := l.conf.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request etcd: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to request etcd %s: unexpected status %d", path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(bufio.NewReader(resp.Body)).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode etcd response: %w", err)
+	}
+	return nil
+}
+
+// request 创建请求，启用认证时先获取 token
+func (l *etcdLocker) request(ctx context.Context, path string, body []byte) (*http.Request, error) {
+	req, err := newJSONRequest(ctx, l.conf.Endpoint+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if l.conf.Username == "" {
+		return req, nil
+	}
+
+	const authPath = "/v3/auth/authenticate"
+	creds, _ := json.Marshal(map[string]string{"name": l.conf.Username, "password": l.conf.Password})
+	authReq, err := newJSONRequest(ctx, l.conf.Endpoint+authPath, creds)
+	if err != nil {
+		return nil, err
+	}
+	var auth struct {
+		Token string `json:"token"`
+	}
+	if err := l.do(authReq, authPath, &auth); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", auth.Token)
+	return req, nil
+}
+
+func newJSONRequest(ctx context.Context, url string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
@@ -0,0 +1,73 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// refreshScript 仅在锁仍由本实例持有时续期
+var refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript 仅在锁仍由本实例持有时删除
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+type redisLocker struct {
+	client redis.Cmdable
+}
+
+// NewRedisLocker 返回基于 Redis SET NX PX 的 Locker，锁的值为持有者标识，续期与释放前校验持有者
+func NewRedisLocker(client redis.Cmdable) Locker {
+	return &redisLocker{client: client}
+}
+
+func (l *redisLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	token := lockToken()
+	ok, err := l.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire redis lock %s: %w", key, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return &redisLease{client: l.client, key: key, token: token, ttl: ttl}, nil
+}
+
+type redisLease struct {
+	client redis.Cmdable
+	key    string
+	token  string
+	ttl    time.Duration
+}
+
+func (l *redisLease) Refresh(ctx context.Context) error {
+	n, err := refreshScript.Run(ctx, l.client, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("failed to refresh redis lock %s: %w", l.key, err)
+	}
+	if n == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (l *redisLease) Release(ctx context.Context) error {
+	err := releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to release redis lock %s: %w", l.key, err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package ginx
+
+import (
+	"errors"
+	"math"
+
+	"github.com/gaoxin19/ginx/config"
+	"github.com/gaoxin19/ginx/db"
+)
+
+const (
+	// dbStartPriority 先于其他启动钩子确认数据库可用
+	dbStartPriority = math.MinInt
+	// dbShutdownPriority 连接池在其他关闭钩子（任务池、定时任务等可能仍在使用数据库）之后关闭
+	dbShutdownPriority = math.MaxInt - 1
+)
+
+// OpenDB 按配置创建连接池
+func OpenDB(opts *config.DatabaseOptions) (*db.DB, error) {
+	return db.Open(db.Config{
+		Name:            opts.Name,
+		Driver:          opts.Driver,
+		DSN:             opts.DSN,
+		MaxOpenConns:    opts.MaxOpenConns,
+		MaxIdleConns:    opts.MaxIdleConns,
+		ConnMaxLifetime: opts.ConnMaxLifetime,
+		ConnMaxIdleTime: opts.ConnMaxIdleTime,
+		PingTimeout:     opts.PingTimeout,
+	})
+}
+
+// UseDB 启动时 Ping 数据库，不可用时终止启动；将其加入就绪检查，并注入请求的 ctx（需在注册路由之前调用）；
+// 关闭时在其他关闭钩子之后关闭连接池
+func (e *Engine) UseDB(d *db.DB) {
+	name := "db-" + d.Name()
+	e.RegisterOnStart(name, d.Ping, WithPriority(dbStartPriority))
+	e.RegisterOnShutdown(name, d.Close, WithPriority(dbShutdownPriority))
+	e.health.Register(name, d.Ping)
+	e.Use(db.Middleware(d))
+}
+
+// ProvideDB 注册按 Options.Database 创建 *db.DB 的构造函数，创建后自动调用 UseDB
+func (c *Container) ProvideDB() error {
+	return c.Provide(func(e *Engine) (*db.DB, error) {
+		opts := e.opts().Database
+		if opts == nil {
+			return nil, errors.New("database is not configured")
+		}
+		d, err := OpenDB(opts)
+		if err != nil {
+			return nil, err
+		}
+		e.UseDB(d)
+		return d, nil
+	})
+}
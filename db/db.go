@@ -0,0 +1,145 @@
+// Package db 管理 database/sql 连接池的生命周期。
+//
+// Open 按配置创建连接池，Ping 用于启动钩子与就绪检查，连接池状态以 go_sql_* 指标暴露，Close 用于关闭钩子。
+// 通过 engine.UseDB(db) 接入 ginx。sqlx、GORM 与 pgx 均可基于 *sql.DB 使用：
+//
+//	sqlx.NewDb(d.DB, "pgx")
+//	gorm.Open(postgres.New(postgres.Config{Conn: d.DB}))
+//	sql.Open("pgx", dsn) // 需导入 github.com/jackc/pgx/v5/stdlib
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/gaoxin19/ginx/metrics"
+)
+
+// DefaultPingTimeout Ping 的默认超时
+const DefaultPingTimeout = 5 * time.Second
+
+// Config 连接池配置
+type Config struct {
+	// Name 连接池名称，用于指标的 db_name 标签与健康检查名称，默认 default
+	Name string
+	// Driver 已注册的驱动名称，如 pgx、mysql、sqlite3
+	Driver string
+	DSN    string
+	// MaxOpenConns 最大连接数，0 表示不限制
+	MaxOpenConns int
+	// MaxIdleConns 最大空闲连接数，0 时使用 database/sql 的默认值 2
+	MaxIdleConns int
+	// ConnMaxLifetime 连接的最长使用时间，0 表示不限制
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime 连接的最长空闲时间，0 表示不限制
+	ConnMaxIdleTime time.Duration
+	// PingTimeout Ping 的超时，默认 5s
+	PingTimeout time.Duration
+}
+
+// DB 带生命周期管理的连接池
+type DB struct {
+	*sql.DB
+	conf      Config
+	collector prometheus.Collector
+}
+
+// Open 按配置创建连接池并注册连接池指标；不会建立连接，需调用 Ping 确认数据库可用
+func Open(conf Config) (*DB, error) {
+	sqlDB, err := sql.Open(conf.Driver, conf.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", conf.Name, err)
+	}
+	sqlDB.SetMaxOpenConns(conf.MaxOpenConns)
+	if conf.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(conf.MaxIdleConns)
+	}
+	sqlDB.SetConnMaxLifetime(conf.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(conf.ConnMaxIdleTime)
+
+	d, err := Wrap(sqlDB, conf)
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// Wrap 管理已创建的连接池，如通过 pgx 的 stdlib.OpenDBFromPool 得到的 *sql.DB；conf 中只使用 Name 与 PingTimeout
+func Wrap(sqlDB *sql.DB, conf Config) (*DB, error) {
+	if conf.Name == "" {
+		conf.Name = "default"
+	}
+	if conf.PingTimeout <= 0 {
+		conf.PingTimeout = DefaultPingTimeout
+	}
+	d := &DB{DB: sqlDB, conf: conf, collector: collectors.NewDBStatsCollector(sqlDB, conf.Name)}
+	if err := metrics.Registry.Register(d.collector); err != nil {
+		return nil, fmt.Errorf("failed to register metrics of database %s: %w", conf.Name, err)
+	}
+	return d, nil
+}
+
+// Name 返回连接池名称
+func (d *DB) Name() string {
+	return d.conf.Name
+}
+
+// Ping 在 PingTimeout 内确认数据库可用，用于启动钩子与就绪检查
+func (d *DB) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, d.conf.PingTimeout)
+	defer cancel()
+	if err := d.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database %s: %w", d.conf.Name, err)
+	}
+	return nil
+}
+
+// Close 关闭连接池并注销指标，等待执行中的查询结束；ctx 结束时返回 ctx 的错误，连接池仍会在后台关闭
+func (d *DB) Close(ctx context.Context) error {
+	metrics.Registry.Unregister(d.collector)
+	done := make(chan error, 1)
+	go func() {
+		done <- d.DB.Close()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to close database %s: %w", d.conf.Name, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("database %s: %w", d.conf.Name, ctx.Err())
+	}
+}
+
+type contextKey struct{ name string }
+
+// NewContext 返回携带 d 的 ctx，以连接池名称区分多个连接池
+func NewContext(ctx context.Context, d *DB) context.Context {
+	return context.WithValue(ctx, contextKey{d.conf.Name}, d)
+}
+
+// FromContext 返回 ctx 中名为 name 的连接池，name 为空时为 default
+func FromContext(ctx context.Context, name ...string) *DB {
+	key := contextKey{"default"}
+	if len(name) > 0 && name[0] != "" {
+		key.name = name[0]
+	}
+	d, _ := ctx.Value(key).(*DB)
+	return d
+}
+
+// Middleware 将 d 注入请求的 ctx，处理函数通过 db.FromContext(c.Request.Context()) 获取
+func Middleware(d *DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), d))
+		c.Next()
+	}
+}
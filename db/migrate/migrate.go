@@ -0,0 +1,255 @@
+// Package migrate 在服务启动时执行数据库迁移。
+//
+// 支持 golang-migrate（{version}_{name}.up.sql，版本记录在 schema_migrations）与
+// goose（{version}_{name}.sql，版本记录在 goose_db_version）两种文件与版本表格式，可直接接管已有工具维护的数据库。
+// 执行前获取数据库的咨询锁（PostgreSQL 的 pg_advisory_lock、MySQL 的 GET_LOCK），多副本同时启动时只有一个实例执行迁移，
+// 其余实例等待锁释放后发现没有待执行的迁移直接返回。
+// 通过 engine.UseMigrations(migrator) 接入 ginx：迁移作为启动钩子在服务就绪之前执行，失败时终止启动。
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Format 迁移文件与版本表格式
+type Format string
+
+const (
+	// FormatMigrate golang-migrate 格式
+	FormatMigrate Format = "migrate"
+	// FormatGoose goose 格式
+	FormatGoose Format = "goose"
+)
+
+// Dialect 数据库类型，决定占位符、建表语句与咨询锁的实现
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	MySQL    Dialect = "mysql"
+	// SQLite 不支持咨询锁，仅适用于单实例
+	SQLite Dialect = "sqlite"
+)
+
+// DefaultLockTimeout 等待咨询锁的默认超时
+const DefaultLockTimeout = 10 * time.Minute
+
+// ErrDirty golang-migrate 格式的版本表标记为 dirty，上次迁移中途失败，需要人工修复后清除标记
+var ErrDirty = errors.New("database is dirty, fix the failed migration and clear the dirty flag")
+
+// Options 迁移配置
+type Options struct {
+	Dialect Dialect
+	// Format 默认为 FormatMigrate
+	Format Format
+	// Table 版本表名，默认 schema_migrations（golang-migrate）或 goose_db_version（goose）
+	Table string
+	// LockTimeout 等待其他实例完成迁移的超时，默认 10m
+	LockTimeout time.Duration
+	// Logger 为空时不记录日志
+	Logger *zap.Logger
+}
+
+// Migrator 数据库迁移
+type Migrator struct {
+	db         *sql.DB
+	opts       Options
+	migrations []Migration
+	store      versionStore
+}
+
+// New 从 fsys 读取迁移文件，通常为 embed.FS 的子目录（fs.Sub）
+func New(db *sql.DB, fsys fs.FS, opts Options) (*Migrator, error) {
+	if opts.Format == "" {
+		opts.Format = FormatMigrate
+	}
+	if opts.LockTimeout <= 0 {
+		opts.LockTimeout = DefaultLockTimeout
+	}
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	switch opts.Dialect {
+	case Postgres, MySQL, SQLite:
+	default:
+		return nil, fmt.Errorf("unsupported migration dialect %q", opts.Dialect)
+	}
+
+	m := &Migrator{db: db, opts: opts}
+	switch opts.Format {
+	case FormatMigrate:
+		if m.opts.Table == "" {
+			m.opts.Table = "schema_migrations"
+		}
+		m.store = &migrateStore{m: m}
+	case FormatGoose:
+		if m.opts.Table == "" {
+			m.opts.Table = "goose_db_version"
+		}
+		m.store = &gooseStore{m: m}
+	default:
+		return nil, fmt.Errorf("unsupported migration format %q", opts.Format)
+	}
+
+	var err error
+	if m.migrations, err = Load(fsys, opts.Format); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Version 返回当前版本，尚未执行过迁移时为 0
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	if err := m.store.ensure(ctx); err != nil {
+		return 0, err
+	}
+	return m.store.version(ctx)
+}
+
+// Pending 返回待执行的迁移
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, mig := range m.migrations {
+		if mig.Version > version {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// Up 持有咨询锁执行所有待执行的迁移，用作启动钩子
+func (m *Migrator) Up(ctx context.Context) error {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		m.opts.Logger.Info("Database schema is up to date")
+		return nil
+	}
+	for _, mig := range pending {
+		start := time.Now()
+		if err := m.store.apply(ctx, mig); err != nil {
+			return fmt.Errorf("failed to apply migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		m.opts.Logger.Info("Migration applied",
+			zap.Int64("version", mig.Version),
+			zap.String("name", mig.Name),
+			zap.Duration("elapsed", time.Since(start)),
+		)
+	}
+	return nil
+}
+
+// lock 获取咨询锁，返回释放函数
+func (m *Migrator) lock(ctx context.Context) (unlock func(), err error) {
+	if m.opts.Dialect == SQLite {
+		return func() {}, nil
+	}
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration lock connection: %w", err)
+	}
+	h := fnv.New64a()
+	h.Write([]byte("ginx-migrate:" + m.opts.Table))
+	key := int64(h.Sum64() >> 1)
+
+	start := time.Now()
+	lockCtx, cancel := context.WithTimeout(ctx, m.opts.LockTimeout)
+	defer cancel()
+	switch m.opts.Dialect {
+	case Postgres:
+		_, err = conn.ExecContext(lockCtx, "SELECT pg_advisory_lock($1)", key)
+		unlock = func() {
+			conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+			conn.Close()
+		}
+	case MySQL:
+		var ok sql.NullInt64
+		name := fmt.Sprintf("ginx-migrate-%x", key)
+		err = conn.QueryRowContext(lockCtx, "SELECT GET_LOCK(?, ?)", name, int(m.opts.LockTimeout.Seconds())).Scan(&ok)
+		if err == nil && ok.Int64 != 1 {
+			err = errors.New("timed out")
+		}
+		unlock = func() {
+			conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+			conn.Close()
+		}
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		m.opts.Logger.Info("Acquired migration lock", zap.Duration("waited", waited))
+	}
+	return unlock, nil
+}
+
+// exec 执行一个迁移的语句，NoTx 为 false 时与 record 在同一事务中执行
+func (m *Migrator) exec(ctx context.Context, mig Migration, record func(ctx context.Context, q querier) error) error {
+	if mig.NoTx {
+		for _, stmt := range mig.Up {
+			if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return record(ctx, m.db)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range mig.Up {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if err := record(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// rebind 将 ? 占位符转换为 PostgreSQL 的 $n
+func (m *Migrator) rebind(query string) string {
+	if m.opts.Dialect != Postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
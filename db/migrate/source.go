@@ -0,0 +1,133 @@
+package migrate
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version int64
+	Name    string
+	// Up 升级语句，golang-migrate 格式为整个文件，goose 格式为按语句拆分后的列表
+	Up []string
+	// NoTx 不在事务中执行，goose 格式由 -- +goose NO TRANSACTION 指定；golang-migrate 格式总是不使用事务
+	NoTx bool
+}
+
+// Load 从 fsys 读取迁移文件：golang-migrate 格式为 {version}_{name}.up.sql（忽略 .down.sql），
+// goose 格式为 {version}_{name}.sql，以 -- +goose Up 与 -- +goose Down 分隔
+func Load(fsys fs.FS, format Format) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := map[int64]string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".sql" {
+			continue
+		}
+		base := strings.TrimSuffix(name, ".sql")
+		if format == FormatMigrate {
+			if !strings.HasSuffix(base, ".up") {
+				continue
+			}
+			base = strings.TrimSuffix(base, ".up")
+		}
+
+		ver, title, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(ver, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name %s", name)
+		}
+		if prev, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, prev, name)
+		}
+		seen[version] = name
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+		m := Migration{Version: version, Name: title}
+		if format == FormatGoose {
+			if m.Up, m.NoTx, err = parseGoose(string(data)); err != nil {
+				return nil, fmt.Errorf("invalid migration %s: %w", name, err)
+			}
+		} else {
+			m.Up, m.NoTx = []string{string(data)}, true
+		}
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// parseGoose 取出 -- +goose Up 段的语句，按行尾的分号拆分，StatementBegin 与 StatementEnd 之间的内容作为一条语句
+func parseGoose(src string) (stmts []string, noTx bool, err error) {
+	var (
+		buf     strings.Builder
+		inUp    bool
+		foundUp bool
+		inBlock bool
+	)
+	scanner := bufio.NewScanner(strings.NewReader(src))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if annotation, ok := strings.CutPrefix(trimmed, "-- +goose "); ok {
+			switch strings.TrimSpace(annotation) {
+			case "Up":
+				inUp, foundUp = true, true
+			case "Down":
+				inUp = false
+			case "NO TRANSACTION":
+				noTx = true
+			case "StatementBegin":
+				inBlock = true
+			case "StatementEnd":
+				if inUp && strings.TrimSpace(buf.String()) != "" {
+					stmts = append(stmts, buf.String())
+				}
+				buf.Reset()
+				inBlock = false
+			}
+			continue
+		}
+		if !inUp {
+			continue
+		}
+
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+		if !inBlock && strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, buf.String())
+			buf.Reset()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, false, err
+	}
+	if !foundUp {
+		return nil, false, fmt.Errorf("missing -- +goose Up annotation")
+	}
+	if inBlock {
+		return nil, false, fmt.Errorf("missing -- +goose StatementEnd annotation")
+	}
+	if s := strings.TrimSpace(buf.String()); s != "" && !strings.HasPrefix(s, "--") {
+		stmts = append(stmts, buf.String())
+	}
+	return stmts, noTx, nil
+}
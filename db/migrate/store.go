@@ -0,0 +1,145 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// versionStore 版本表的读写
+type versionStore interface {
+	// ensure 创建版本表
+	ensure(ctx context.Context) error
+	// version 返回已执行的最大版本
+	version(ctx context.Context) (int64, error)
+	// apply 执行迁移并记录版本
+	apply(ctx context.Context, mig Migration) error
+}
+
+// migrateStore golang-migrate 的版本表：只有一行，记录当前版本与 dirty 标记；
+// 执行前先写入新版本并标记 dirty，成功后清除，中途失败时保持 dirty 等待人工处理
+type migrateStore struct {
+	m *Migrator
+}
+
+func (s *migrateStore) ensure(ctx context.Context) error {
+	_, err := s.m.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)", s.m.opts.Table))
+	if err != nil {
+		return fmt.Errorf("failed to create migration table: %w", err)
+	}
+	return nil
+}
+
+func (s *migrateStore) version(ctx context.Context) (int64, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := s.m.db.QueryRowContext(ctx, fmt.Sprintf("SELECT version, dirty FROM %s LIMIT 1", s.m.opts.Table)).Scan(&version, &dirty)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0, nil
+	case err != nil:
+		return 0, fmt.Errorf("failed to read migration version: %w", err)
+	case dirty:
+		return 0, fmt.Errorf("version %d: %w", version, ErrDirty)
+	}
+	return version, nil
+}
+
+func (s *migrateStore) apply(ctx context.Context, mig Migration) error {
+	if err := s.set(ctx, s.m.db, mig.Version, true); err != nil {
+		return err
+	}
+	return s.m.exec(ctx, mig, func(ctx context.Context, q querier) error {
+		return s.set(ctx, q, mig.Version, false)
+	})
+}
+
+func (s *migrateStore) set(ctx context.Context, q querier, version int64, dirty bool) error {
+	if _, err := q.ExecContext(ctx, "DELETE FROM "+s.m.opts.Table); err != nil {
+		return fmt.Errorf("failed to update migration version: %w", err)
+	}
+	query := s.m.rebind(fmt.Sprintf("INSERT INTO %s (version, dirty) VALUES (?, ?)", s.m.opts.Table))
+	if _, err := q.ExecContext(ctx, query, version, dirty); err != nil {
+		return fmt.Errorf("failed to update migration version: %w", err)
+	}
+	return nil
+}
+
+// gooseStore goose 的版本表：每次执行或回滚追加一行，版本的最后一行决定其是否已执行
+type gooseStore struct {
+	m *Migrator
+}
+
+func (s *gooseStore) ensure(ctx context.Context) error {
+	var ddl string
+	switch s.m.opts.Dialect {
+	case Postgres:
+		ddl = "CREATE TABLE IF NOT EXISTS %s (id serial PRIMARY KEY, version_id bigint NOT NULL, is_applied boolean NOT NULL, tstamp timestamp NULL DEFAULT now())"
+	case MySQL:
+		ddl = "CREATE TABLE IF NOT EXISTS %s (id serial NOT NULL, version_id bigint NOT NULL, is_applied boolean NOT NULL, tstamp timestamp NULL DEFAULT now(), PRIMARY KEY(id))"
+	case SQLite:
+		ddl = "CREATE TABLE IF NOT EXISTS %s (id INTEGER PRIMARY KEY AUTOINCREMENT, version_id INTEGER NOT NULL, is_applied INTEGER NOT NULL, tstamp TIMESTAMP DEFAULT (datetime('now')))"
+	}
+	if _, err := s.m.db.ExecContext(ctx, fmt.Sprintf(ddl, s.m.opts.Table)); err != nil {
+		return fmt.Errorf("failed to create migration table: %w", err)
+	}
+
+	// 与 goose 一致，新建的版本表写入版本 0
+	var n int
+	if err := s.m.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+s.m.opts.Table).Scan(&n); err != nil {
+		return fmt.Errorf("failed to read migration version: %w", err)
+	}
+	if n > 0 {
+		return nil
+	}
+	query := s.m.rebind(fmt.Sprintf("INSERT INTO %s (version_id, is_applied) VALUES (?, ?)", s.m.opts.Table))
+	if _, err := s.m.db.ExecContext(ctx, query, 0, true); err != nil {
+		return fmt.Errorf("failed to init migration table: %w", err)
+	}
+	return nil
+}
+
+func (s *gooseStore) version(ctx context.Context) (int64, error) {
+	rows, err := s.m.db.QueryContext(ctx, fmt.Sprintf("SELECT version_id, is_applied FROM %s ORDER BY id DESC", s.m.opts.Table))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	defer rows.Close()
+
+	// 按 id 倒序，每个版本第一次出现的行为其最新状态
+	seen := map[int64]bool{}
+	var current int64
+	for rows.Next() {
+		var (
+			version int64
+			applied bool
+		)
+		if err := rows.Scan(&version, &applied); err != nil {
+			return 0, fmt.Errorf("failed to read migration version: %w", err)
+		}
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		if applied && version > current {
+			current = version
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	return current, nil
+}
+
+func (s *gooseStore) apply(ctx context.Context, mig Migration) error {
+	return s.m.exec(ctx, mig, func(ctx context.Context, q querier) error {
+		query := s.m.rebind(fmt.Sprintf("INSERT INTO %s (version_id, is_applied) VALUES (?, ?)", s.m.opts.Table))
+		if _, err := q.ExecContext(ctx, query, mig.Version, true); err != nil {
+			return fmt.Errorf("failed to record migration version: %w", err)
+		}
+		return nil
+	})
+}
@@ -3,31 +3,80 @@ package ginx
 import (
 	"context"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"gopkg.in/natefinch/lumberjack.v2"
 
 	"github.com/gaoxin19/ginx/config"
+	"github.com/gaoxin19/ginx/health"
+	"github.com/gaoxin19/ginx/metrics"
 	"github.com/gaoxin19/ginx/middleware"
+	"github.com/gaoxin19/ginx/openapi"
+	"github.com/gaoxin19/ginx/report"
 	"github.com/gaoxin19/ginx/upgrader"
 )
 
 type Engine struct {
 	*gin.Engine
-	server            *http.Server
-	upgrader          upgrader.Upgrader
-	logger            *zap.Logger
-	options           *config.Options
-	shutdownCallbacks []func()
+	server   *http.Server
+	upgrader upgrader.Upgrader
+	logger   *zap.Logger
+	logLevel zap.AtomicLevel
+	logFile  *lumberjack.Logger
+	signals  signals
+	options  *config.Options
+	// optsMu 保护 ApplyConfig 对 options 的替换，reloadMu 串行化 ApplyConfig
+	optsMu   sync.RWMutex
+	reloadMu sync.Mutex
+	health   *health.Checker
+	admin    *gin.Engine
+	control  *controlSocket
+	conns    *connTracker
+	inflight *inflightTracker
+	upgrades upgradeStats
+
+	// 可热加载的中间件
+	realIP    swapHandler
+	cors      swapHandler
+	rateLimit swapHandler
+	rateStore middleware.RateLimitStore
+
+	hooksMu            sync.Mutex
+	startHooks         []hook
+	shutdownHooks      []hook
+	drainHooks         []hook
+	beforeUpgradeHooks []hook
+	afterUpgradeHooks  []hook
+	servers            []extraServer
+	runners            []namedRunner
+	runnerCancel       context.CancelFunc
+	runnerDone         chan struct{}
+	modules            []Module
+	versions           map[string]bool
+	openapiOnce        sync.Once
+	openapiDoc         *openapi.Document
+	stop               context.CancelFunc
+	upgrade            func() error
+	upgradeBinary      func(path string) error
+	listenPacket       func(network, addr string) (net.PacketConn, error)
+	handoff            upgrader.Handoff
+	prepareReload      func(*upgrader.ReloadOptions) error
+	reason             ShutdownReason
+	panicHooks         []middleware.PanicHook
+	reporters          []report.ErrorReporter
 }
 
 func New(opts *config.Options) (*Engine, error) {
-	logger, err := NewLogger(&LogConfig{
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	logger, level, logFile, err := newLogger(&LogConfig{
 		Level:      opts.Logger.Level,
 		Filename:   opts.Logger.Filename,
 		MaxSize:    opts.Logger.MaxSize,
@@ -36,36 +85,141 @@ func New(opts *config.Options) (*Engine, error) {
 		Compress:   opts.Logger.Compress,
 		LocalTime:  opts.Logger.LocalTime,
 		Console:    opts.Logger.Console,
+		Color:      opts.Logger.Color,
+
+		RedactFields:   opts.Logger.RedactFields,
+		RedactPatterns: opts.Logger.RedactPatterns,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to init logger: %w", err)
 	}
 	SetLogger(logger)
 
-	gin.SetMode(gin.ReleaseMode)
-	router := gin.New()
-
-	if opts.EnableRecovery {
-		router.Use(middleware.Recovery(logger))
+	if opts.Debug {
+		gin.SetMode(gin.DebugMode)
+	} else {
+		gin.SetMode(gin.ReleaseMode)
 	}
-	if opts.EnableLogger {
-		router.Use(middleware.Logger(logger))
+	setupValidator()
+	SetResponseConfig(ResponseConfig{Bare: opts.BareResponse})
+	if err := SetJSONEngine(opts.JSONEngine); err != nil {
+		return nil, err
 	}
+	router := gin.New()
 
-	return &Engine{
+	e := &Engine{
 		Engine: router,
 		server: &http.Server{
 			Handler:      router,
 			ReadTimeout:  opts.ReadTimeout,
 			WriteTimeout: opts.WriteTimeout,
 		},
-		logger:  logger,
-		options: opts,
-	}, nil
+		logger:   logger,
+		logLevel: level,
+		logFile:  logFile,
+		options:  opts,
+		conns:    newConnTracker(),
+		inflight: newInflightTracker(),
+	}
+
+	if api := opts.API; api != nil && (api.Header != "" || api.Query != "") {
+		e.server.Handler = e.negotiateVersion(router)
+	}
+
+	if e.signals, err = parseSignals(opts.Signals); err != nil {
+		return nil, err
+	}
+
+	if err := e.useMiddlewares(); err != nil {
+		return nil, err
+	}
+
+	if opts.Sentry != nil && opts.Sentry.DSN != "" {
+		reporter, err := report.NewSentry(report.SentryConfig{
+			DSN:         opts.Sentry.DSN,
+			Release:     opts.Sentry.Release,
+			Environment: opts.Sentry.Environment,
+			SampleRate:  opts.Sentry.SampleRate,
+		})
+		if err != nil {
+			return nil, err
+		}
+		e.UseErrorReporter(reporter)
+	}
+
+	mgmt, err := e.setupAdmin()
+	if err != nil {
+		return nil, err
+	}
+	if opts.ControlSocket != "" {
+		e.setupControlSocket(opts.ControlSocket)
+	}
+	if opts.Update != nil {
+		if err := e.setupUpdater(opts.Update); err != nil {
+			return nil, err
+		}
+	}
+
+	livenessPath, readinessPath := "/healthz", "/readyz"
+	if opts.Health != nil {
+		e.health = health.New(opts.Health.Timeout)
+		livenessPath, readinessPath = opts.Health.LivenessPath, opts.Health.ReadinessPath
+		if opts.Health.Enabled {
+			router.GET(livenessPath, e.health.LivenessHandler())
+			router.GET(readinessPath, e.health.ReadinessHandler())
+		}
+	} else {
+		e.health = health.New(0)
+	}
+	if e.admin != nil {
+		e.admin.GET(livenessPath, e.health.LivenessHandler())
+		e.admin.GET(readinessPath, e.health.ReadinessHandler())
+	}
+
+	metricsPath := opts.MetricsPath
+	if metricsPath == "" && e.admin != nil {
+		metricsPath = "/metrics"
+	}
+	if metricsPath != "" {
+		mgmt.GET(metricsPath, gin.WrapH(metrics.Handler()))
+	}
+	versionPath := opts.VersionPath
+	if versionPath == "" && e.admin != nil {
+		versionPath = "/version"
+	}
+	if versionPath != "" {
+		mgmt.GET(versionPath, BuildInfoHandler())
+	}
+	if opts.EnablePprof {
+		if err := e.mountPprof(mgmt); err != nil {
+			return nil, err
+		}
+	}
+	if opts.LogLevelPath != "" {
+		mgmt.GET(opts.LogLevelPath, gin.WrapH(e.logLevel))
+		mgmt.PUT(opts.LogLevelPath, gin.WrapH(e.logLevel))
+	}
+	if opts.OpenAPI != nil {
+		if err := e.mountOpenAPI(opts.OpenAPI); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
 }
 
 func (e *Engine) Run() error {
-	upg, err := upgrader.New(e.logger)
+	return e.RunContext(context.Background())
+}
+
+// RunContext 启动服务，在 ctx 被取消或收到退出信号时优雅关闭，升级方式由 Options.UpgradeStrategy 决定
+func (e *Engine) RunContext(ctx context.Context) error {
+	return e.run(ctx, e.upgraderOptions())
+}
+
+// run 使用 opts 指定的升级器运行服务，所有升级方式共用同一条启动路径
+func (e *Engine) run(ctx context.Context, opts upgrader.Options) error {
+	upg, err := upgrader.New(e.logger, opts)
 	if err != nil {
 		return fmt.Errorf("failed to create upgrader: %w", err)
 	}
@@ -73,92 +227,128 @@ func (e *Engine) Run() error {
 	defer e.upgrader.Stop()
 	ln, err := e.upgrader.Listen("tcp", fmt.Sprintf(":%d", e.options.Port))
 	if err != nil {
-		return fmt.Errorf("failed to create listener: %w", err)
+		return e.stopped(ReasonListenerError, fmt.Errorf("failed to create listener: %w", err))
 	}
+	e.upgrader.WatchSignal()
+	e.upgrades.started(e.upgrader.HasParent())
+	defer e.removePIDFile()
 
-	if err := e.upgrader.Ready(); err != nil {
-		return fmt.Errorf("failed to mark as ready: %w", err)
-	}
+	return e.serve(ctx, e.server, ln, serveOptions{
+		listen:        e.upgrader.Listen,
+		ready:         e.upgrader.Ready,
+		exit:          e.upgrader.Exit(),
+		upgrade:       e.upgrader.Upgrade,
+		upgradeBinary: e.upgrader.UpgradeBinary,
+		listenPacket:  e.upgrader.ListenPacket,
+		handoff:       e.upgrader,
+	})
+}
 
-	e.logger.Info("Server is starting", zap.Int("port", e.options.Port))
+func (e *Engine) upgraderOptions() upgrader.Options {
+	e.hooksMu.Lock()
+	reload := e.prepareReload
+	e.hooksMu.Unlock()
 
-	go func() {
-		if err := e.server.Serve(ln); err != nil && err != http.ErrServerClosed {
-			e.logger.Error("Server error", zap.Error(err))
-			e.upgrader.Stop()
+	opts := upgrader.Options{
+		Strategy:        upgrader.Strategy(e.options.UpgradeStrategy),
+		ShutdownTimeout: e.shutdownTimeout(),
+		UpgradeTimeout:  e.options.UpgradeTimeout,
+		PIDFile:         e.options.PIDFile,
+		OnFailure:       e.onUpgradeFailure,
+		OnSuccess:       e.onUpgradeSuccess,
+		Signals:         e.signals.upgrade,
+		BeforeUpgrade:   e.executeBeforeUpgradeHooks,
+		AfterUpgrade:    e.executeAfterUpgradeHooks,
+		Reload:          reload,
+	}
+	if retry := e.options.UpgradeRetry; retry != nil {
+		opts.Retry = upgrader.RetryPolicy{
+			MaxAttempts:  retry.MaxAttempts,
+			Backoff:      retry.Backoff,
+			MaxBackoff:   retry.MaxBackoff,
+			StableWindow: retry.StableWindow,
 		}
-	}()
-
-	<-e.upgrader.Exit()
-	return nil
+	}
+	return opts
 }
 
-func (e *Engine) Logger() *zap.Logger {
-	return e.logger
+// removePIDFile 退出时删除仍记录当前进程的 PID 文件
+func (e *Engine) removePIDFile() {
+	if e.options.PIDFile == "" {
+		return
+	}
+	if err := upgrader.RemovePIDFile(e.options.PIDFile); err != nil {
+		e.logger.Warn("Failed to remove pid file", zap.Error(err))
+	}
 }
 
-func (engine *Engine) GracefulServe(server *http.Server) error {
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-
-	errChan := make(chan error, 1)
-
-	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errChan <- err
-		}
-	}()
-
-	select {
-	case <-quit:
-		L().Info("Received shutdown signal, starting graceful shutdown...")
+// DefaultShutdownHookTimeout 默认的关闭钩子总超时时间
+const DefaultShutdownHookTimeout = 10 * time.Second
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		engine.executeShutdownCallbacks()
+func (e *Engine) shutdownHookTimeout() time.Duration {
+	if timeout := e.opts().ShutdownHookTimeout; timeout > 0 {
+		return timeout
+	}
+	return DefaultShutdownHookTimeout
+}
 
-		if err := server.Shutdown(ctx); err != nil {
-			L().Error("Server shutdown error", zap.Error(err))
-			return fmt.Errorf("server shutdown error: %w", err)
-		}
+func (e *Engine) shutdownTimeout() time.Duration {
+	if timeout := e.opts().ShutdownTimeout; timeout > 0 {
+		return timeout
+	}
+	return upgrader.DefaultShutdownTimeout
+}
 
-		L().Info("Server has been shutdown successfully")
-		return nil
+// RegisterPanicHook 注册 panic 回调，在 Recovery 中间件捕获 panic 后调用，可用于 Sentry 等告警上报
+func (e *Engine) RegisterPanicHook(hook middleware.PanicHook) {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	e.panicHooks = append(e.panicHooks, hook)
+}
 
-	case err := <-errChan:
-		return fmt.Errorf("HTTP server error: %w", err)
-	}
+// UseErrorReporter 启用错误上报：panic 与 5xx 响应会被上报，进程关闭时刷新缓冲的事件，
+// 需在注册路由之前调用
+func (e *Engine) UseErrorReporter(r report.ErrorReporter) {
+	e.hooksMu.Lock()
+	e.reporters = append(e.reporters, r)
+	e.hooksMu.Unlock()
+	e.RegisterPanicHook(report.PanicHook(r))
+	e.Use(report.Middleware(r))
+	e.RegisterOnShutdown("error-reporter-flush", r.Flush, WithPriority(math.MaxInt))
 }
 
-func (engine *Engine) RegisterOnShutdown(f func()) {
-	engine.shutdownCallbacks = append(engine.shutdownCallbacks, f)
+// Health 返回健康检查管理器，可用于注册就绪检查
+func (e *Engine) Health() *health.Checker {
+	return e.health
 }
 
-func (engine *Engine) executeShutdownCallbacks() {
-	for _, cb := range engine.shutdownCallbacks {
-		cb()
-	}
+// Connections 返回当前各状态的连接数
+func (e *Engine) Connections() ConnStats {
+	return e.conns.Stats()
 }
 
-func (e *Engine) GracefulRun() error {
-	graceful := upgrader.NewGracefulUpgrader(e.logger)
+func (e *Engine) Logger() *zap.Logger {
+	return e.logger
+}
 
-	ln, err := graceful.Listen("tcp", fmt.Sprintf(":%d", e.options.Port))
+// GracefulServe 使用调用方提供的 server 运行，收到退出信号时优雅关闭
+func (engine *Engine) GracefulServe(server *http.Server) error {
+	addr := server.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	ln, err := net.Listen("tcp", addr)
 	if err != nil {
-		return fmt.Errorf("failed to create listener: %w", err)
+		return engine.stopped(ReasonListenerError, fmt.Errorf("failed to create listener: %w", err))
 	}
 
-	e.logger.Info("Server is starting",
-		zap.Int("port", e.options.Port),
-		zap.Int("pid", os.Getpid()),
-	)
-
-	go func() {
-		if err := e.server.Serve(ln); err != nil && err != http.ErrServerClosed {
-			e.logger.Error("Server error", zap.Error(err))
-		}
-	}()
+	return engine.serve(context.Background(), server, ln, serveOptions{listen: net.Listen})
+}
 
-	return graceful.WaitForSignal(e.server)
+// GracefulRun 使用 GracefulUpgrader 运行，收到 SIGHUP 时 fork 新进程并平滑重启，
+// 等同于将 Options.UpgradeStrategy 设为 graceful 后调用 Run
+func (e *Engine) GracefulRun() error {
+	opts := e.upgraderOptions()
+	opts.Strategy = upgrader.StrategyGraceful
+	return e.run(context.Background(), opts)
 }
@@ -0,0 +1,237 @@
+package ginx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/middleware"
+	"github.com/gaoxin19/ginx/query"
+)
+
+// ProblemContentType RFC 7807 错误响应的内容类型
+const ProblemContentType = "application/problem+json"
+
+// Error 业务错误，由错误处理中间件转换为 problem+json 响应
+type Error struct {
+	// Status HTTP 状态码
+	Status int
+	// Code 业务错误码，如 "user_not_found"
+	Code string
+	// Message 返回给客户端的错误描述
+	Message string
+	// Details 附加信息，如字段级校验错误
+	Details any
+	// Err 原始错误，仅用于日志，不会返回给客户端
+	Err error
+}
+
+// NewError 创建业务错误
+func NewError(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails 返回附带详情的副本
+func (e *Error) WithDetails(details any) *Error {
+	cp := *e
+	cp.Details = details
+	return &cp
+}
+
+// Wrap 返回包装了原始错误的副本
+func (e *Error) Wrap(err error) *Error {
+	cp := *e
+	cp.Err = err
+	return &cp
+}
+
+// Problem RFC 7807 问题详情
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code,omitempty"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ErrorMapper 将错误转换为 *Error，无法处理时返回 nil
+type ErrorMapper func(err error) *Error
+
+var (
+	mappersMu sync.RWMutex
+	mappers   = []ErrorMapper{mapValidationError, mapBindingError, mapStdError}
+)
+
+// RegisterErrorMapper 注册错误转换器，后注册的优先匹配，用于把领域错误映射为 HTTP 错误
+func RegisterErrorMapper(m ErrorMapper) {
+	mappersMu.Lock()
+	defer mappersMu.Unlock()
+	mappers = append([]ErrorMapper{m}, mappers...)
+}
+
+// ToError 将任意错误转换为 *Error，无匹配的转换器时返回 500
+func ToError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	mappersMu.RLock()
+	defer mappersMu.RUnlock()
+	for _, m := range mappers {
+		if e := m(err); e != nil {
+			return e
+		}
+	}
+	return &Error{
+		Status:  http.StatusInternalServerError,
+		Code:    "internal_error",
+		Message: http.StatusText(http.StatusInternalServerError),
+		Err:     err,
+	}
+}
+
+// AbortWithError 记录错误并中止请求，由错误处理中间件写出响应
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// WriteError 立即以 problem+json 写出错误
+func WriteError(c *gin.Context, err error) {
+	e := localizeError(c, ToError(err))
+	if e.Status >= http.StatusInternalServerError {
+		Ctx(c).Error("Request failed", zapError(e))
+	}
+	c.Abort()
+	// 与成功响应使用同一 JSON 引擎
+	body, err := marshalJSON(problemOf(c, e))
+	if err != nil {
+		Ctx(c).Error("Failed to marshal problem response", zap.Error(err))
+		c.Data(http.StatusInternalServerError, MIMEJSON+charset(MIMEJSON),
+			[]byte(`{"status":500,"title":"Internal Server Error"}`))
+		return
+	}
+	c.Data(e.Status, ProblemContentType, body)
+}
+
+// ErrorHandler 返回错误处理中间件，把处理函数记录在 c.Errors 中的最后一个错误转换为 problem+json 响应
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		WriteError(c, c.Errors.Last().Err)
+	}
+}
+
+func problemOf(c *gin.Context, e *Error) Problem {
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(e.Status),
+		Status:    e.Status,
+		Detail:    e.Message,
+		Instance:  c.Request.URL.Path,
+		Code:      e.Code,
+		Details:   e.Details,
+		RequestID: middleware.GetRequestID(c),
+	}
+}
+
+// FieldError 字段级校验错误
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func mapValidationError(err error) *Error {
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) {
+		return nil
+	}
+	fields := make([]FieldError, 0, len(ve))
+	for _, fe := range ve {
+		fields = append(fields, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Error(),
+		})
+	}
+	return &Error{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "validation_failed",
+		Message: "request validation failed",
+		Details: fields,
+		Err:     err,
+	}
+}
+
+func mapBindingError(err error) *Error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		numErr    *strconv.NumError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &Error{Status: http.StatusBadRequest, Code: "invalid_json", Message: "malformed JSON body", Err: err}
+	case errors.As(err, &typeErr):
+		return &Error{
+			Status:  http.StatusBadRequest,
+			Code:    "invalid_type",
+			Message: "field " + typeErr.Field + " must be " + typeErr.Type.String(),
+			Err:     err,
+		}
+	case errors.As(err, &numErr):
+		return &Error{Status: http.StatusBadRequest, Code: "invalid_number", Message: "invalid number " + strconv.Quote(numErr.Num), Err: err}
+	case errors.Is(err, query.ErrInvalid):
+		return &Error{Status: http.StatusBadRequest, Code: "invalid_query", Message: err.Error(), Err: err}
+	}
+	return nil
+}
+
+func mapStdError(err error) *Error {
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytes):
+		return &Error{Status: http.StatusRequestEntityTooLarge, Code: "body_too_large", Message: "request body too large", Err: err}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Status: http.StatusGatewayTimeout, Code: "timeout", Message: "request timed out", Err: err}
+	case errors.Is(err, context.Canceled):
+		return &Error{Status: 499, Code: "canceled", Message: "request canceled", Err: err}
+	}
+	return nil
+}
+
+func zapError(e *Error) zap.Field {
+	if e.Err != nil {
+		return zap.Error(e.Err)
+	}
+	return zap.String("error", e.Message)
+}
@@ -0,0 +1,12 @@
+package ginx
+
+import (
+	"github.com/gaoxin19/ginx/events"
+)
+
+// UseEvents 随服务启动事件总线的任务池，关闭时在 HTTP 服务停止、不再有新事件发布之后排空异步处理；
+// 与 UseWorkers 共用同一任务池时重复注册不影响
+func (e *Engine) UseEvents(b *events.Bus) {
+	e.RegisterOnStart("events", b.Start)
+	e.RegisterOnShutdown("events", b.Shutdown)
+}
@@ -0,0 +1,237 @@
+// Package events 提供进程内的类型化事件总线。
+//
+// 处理函数按事件类型订阅，默认在任务池中异步执行，失败时按任务池的退避策略重试，重试耗尽后记录日志并回调 Options.OnError；
+// 使用 Sync 订阅的处理函数在 Publish 中同步执行，错误直接返回给发布方。
+// 通过 engine.UseEvents(bus) 接入 ginx：关闭时在 HTTP 服务停止、不再有新事件发布之后排空异步处理。
+//
+//	type UserCreated struct{ ID int64 }
+//
+//	events.Subscribe(bus, "send-welcome-mail", func(ctx context.Context, e UserCreated) error {
+//		return mailer.SendWelcome(ctx, e.ID)
+//	}, events.WithRetries(3))
+//
+//	events.Publish(c.Request.Context(), bus, UserCreated{ID: user.ID})
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/worker"
+)
+
+// Handler 事件处理函数
+type Handler[E any] func(ctx context.Context, event E) error
+
+// Failure 重试耗尽后仍失败的异步处理
+type Failure struct {
+	// Event 事件
+	Event any
+	// Handler 订阅时指定的处理函数名称
+	Handler string
+	Err     error
+}
+
+// Options 事件总线配置
+type Options struct {
+	// Pool 执行异步处理的任务池，为空时创建名为 events 的任务池
+	Pool *worker.Pool
+	// Retries 异步处理失败后默认的重试次数
+	Retries int
+	// Logger 为空时不记录日志
+	Logger *zap.Logger
+	// OnError 异步处理最终失败时调用，可用于告警或写入死信表
+	OnError func(ctx context.Context, f Failure)
+}
+
+// SubscribeOption 订阅选项
+type SubscribeOption func(*subscription)
+
+// Sync 在 Publish 中同步执行处理函数，错误返回给发布方，不重试
+func Sync() SubscribeOption {
+	return func(s *subscription) {
+		s.sync = true
+	}
+}
+
+// WithRetries 设置异步处理的重试次数，默认为 Options.Retries
+func WithRetries(n int) SubscribeOption {
+	return func(s *subscription) {
+		s.retries = n
+	}
+}
+
+// WithTimeout 设置单次处理的超时
+func WithTimeout(d time.Duration) SubscribeOption {
+	return func(s *subscription) {
+		s.timeout = d
+	}
+}
+
+type subscription struct {
+	id      uint64
+	name    string
+	fn      func(ctx context.Context, event any) error
+	sync    bool
+	retries int
+	timeout time.Duration
+}
+
+// Bus 事件总线
+type Bus struct {
+	opts Options
+
+	mu       sync.RWMutex
+	handlers map[reflect.Type][]*subscription
+	nextID   uint64
+}
+
+// New 创建事件总线
+func New(opts Options) *Bus {
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	if opts.Pool == nil {
+		opts.Pool = worker.New(worker.Options{Name: "events", Logger: opts.Logger})
+	}
+	return &Bus{opts: opts, handlers: map[reflect.Type][]*subscription{}}
+}
+
+// Pool 返回执行异步处理的任务池
+func (b *Bus) Pool() *worker.Pool {
+	return b.opts.Pool
+}
+
+// Start 启动任务池，可重复调用
+func (b *Bus) Start(ctx context.Context) error {
+	return b.opts.Pool.Start(ctx)
+}
+
+// Shutdown 停止接收事件并等待异步处理完成，之后 Publish 返回 worker.ErrClosed
+func (b *Bus) Shutdown(ctx context.Context) error {
+	return b.opts.Pool.Shutdown(ctx)
+}
+
+// Subscribe 订阅 E 类型的事件，name 用于日志与任务池指标，返回的函数取消订阅
+func Subscribe[E any](b *Bus, name string, h Handler[E], opts ...SubscribeOption) (unsubscribe func()) {
+	s := &subscription{
+		name: name,
+		fn: func(ctx context.Context, event any) error {
+			return h(ctx, event.(E))
+		},
+		retries: b.opts.Retries,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	t := reflect.TypeFor[E]()
+
+	b.mu.Lock()
+	b.nextID++
+	s.id = b.nextID
+	b.handlers[t] = append(b.handlers[t], s)
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := b.handlers[t]
+		for i, sub := range subs {
+			if sub.id == s.id {
+				b.handlers[t] = append(subs[:i:i], subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish 发布事件：同步处理函数按订阅顺序执行，异步处理函数提交到任务池；
+// 返回同步处理函数的错误与提交失败（worker.ErrQueueFull、worker.ErrClosed）的错误
+func Publish[E any](ctx context.Context, b *Bus, event E) error {
+	b.mu.RLock()
+	subs := b.handlers[reflect.TypeFor[E]()]
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, s := range subs {
+		var err error
+		if s.sync {
+			err = b.call(ctx, s, event)
+		} else {
+			err = b.submit(ctx, s, event)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("event %T handler %s: %w", event, s.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// submit 提交异步处理，处理时的 ctx 保留发布方 ctx 中的值（如请求 ID），取消由任务池控制
+func (b *Bus) submit(ctx context.Context, s *subscription, event any) error {
+	values := context.WithoutCancel(ctx)
+	attempts := 0
+
+	job := func(poolCtx context.Context) error {
+		jobCtx := valueContext{Context: poolCtx, values: values}
+		err := b.call(jobCtx, s, event)
+		attempts++
+		if err != nil && attempts > s.retries {
+			b.fail(jobCtx, s, event, err)
+		}
+		return err
+	}
+	return b.opts.Pool.Submit(s.name, job, worker.WithRetries(s.retries))
+}
+
+// call 在超时控制下执行一次处理函数并恢复 panic
+func (b *Bus) call(ctx context.Context, s *subscription, event any) (err error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			b.opts.Logger.Error("Event handler panic",
+				zap.String("event", fmt.Sprintf("%T", event)),
+				zap.String("handler", s.name),
+				zap.Any("panic", r),
+				zap.ByteString("stack", debug.Stack()),
+			)
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return s.fn(ctx, event)
+}
+
+func (b *Bus) fail(ctx context.Context, s *subscription, event any, err error) {
+	b.opts.Logger.Error("Event handler failed",
+		zap.String("event", fmt.Sprintf("%T", event)),
+		zap.String("handler", s.name),
+		zap.Error(err),
+	)
+	if b.opts.OnError != nil {
+		b.opts.OnError(ctx, Failure{Event: event, Handler: s.name, Err: err})
+	}
+}
+
+// valueContext 取消与超时来自任务池，值来自发布方的 ctx
+type valueContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c valueContext) Value(key any) any {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.values.Value(key)
+}
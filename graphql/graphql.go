@@ -0,0 +1,250 @@
+// Package graphql 基于 gqlgen 提供 GraphQL 接口。
+//
+// 请求经过 gin 的中间件链，每个操作按 trace id 与请求 ID 记录日志和耗时，可限制查询复杂度，
+// 开发环境可开启 playground 与内省。通过 engine.RegisterOnDrain("graphql", srv.Shutdown) 接入 ginx 的关闭流程，
+// 关闭时结束进行中的订阅，并拒绝新的操作。
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/metrics"
+	"github.com/gaoxin19/ginx/middleware"
+)
+
+// DefaultKeepAlive 订阅连接默认的心跳间隔
+const DefaultKeepAlive = 10 * time.Second
+
+// ErrShuttingDown 服务关闭过程中拒绝新的操作
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// Options Server 配置
+type Options struct {
+	// Logger 为空时不记录日志
+	Logger *zap.Logger
+	// ComplexityLimit 查询复杂度上限，为 0 时不限制
+	ComplexityLimit int
+	// Playground 在 Mount 的路径下提供 /playground 调试页面，通常只在开发环境开启
+	Playground bool
+	// Introspection 允许内省查询，通常只在开发环境开启
+	Introspection bool
+	// KeepAlive 订阅连接的心跳间隔，默认 10s
+	KeepAlive time.Duration
+	// CheckOrigin 校验 WebSocket 的 Origin，为空时要求 Origin 与 Host 一致
+	CheckOrigin func(r *http.Request) bool
+}
+
+// Server GraphQL 服务
+type Server struct {
+	opts Options
+	srv  *handler.Server
+
+	mu       sync.Mutex
+	closing  bool
+	subs     map[*subscription]struct{}
+	finished sync.WaitGroup
+}
+
+// subscription 进行中的订阅
+type subscription struct {
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+// requestInfo 记录日志所需的请求信息
+type requestInfo struct {
+	traceID   string
+	requestID string
+}
+
+type requestInfoKey struct{}
+
+// New 创建 Server
+func New(schema graphql.ExecutableSchema, opts Options) *Server {
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	if opts.KeepAlive == 0 {
+		opts.KeepAlive = DefaultKeepAlive
+	}
+
+	s := &Server{
+		opts: opts,
+		srv:  handler.New(schema),
+		subs: make(map[*subscription]struct{}),
+	}
+	s.srv.AddTransport(transport.Websocket{
+		Upgrader:              websocket.Upgrader{CheckOrigin: opts.CheckOrigin},
+		KeepAlivePingInterval: opts.KeepAlive,
+	})
+	s.srv.AddTransport(transport.Options{})
+	s.srv.AddTransport(transport.GET{})
+	s.srv.AddTransport(transport.POST{})
+	s.srv.AddTransport(transport.MultipartForm{})
+	s.srv.SetQueryCache(lru.New(1000))
+	if opts.Introspection {
+		s.srv.Use(extension.Introspection{})
+	}
+	if opts.ComplexityLimit > 0 {
+		s.srv.Use(extension.FixedComplexityLimit(opts.ComplexityLimit))
+	}
+	s.srv.SetRecoverFunc(func(ctx context.Context, err any) error {
+		s.opts.Logger.Error("GraphQL resolver panic", append(s.fields(ctx), zap.Any("panic", err), zap.Stack("stack"))...)
+		return gqlerror.Errorf("internal server error")
+	})
+	s.srv.AroundOperations(s.aroundOperation)
+	return s
+}
+
+// Handler 返回处理 GraphQL 请求（含 WebSocket 订阅）的 gin 处理函数
+func (s *Server) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), requestInfoKey{}, requestInfo{
+			traceID:   middleware.TraceID(c),
+			requestID: middleware.GetRequestID(c),
+		})
+		s.srv.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	}
+}
+
+// PlaygroundHandler 返回指向 endpoint 的 playground 页面
+func (s *Server) PlaygroundHandler(endpoint string) gin.HandlerFunc {
+	return gin.WrapF(playground.Handler("GraphQL", endpoint))
+}
+
+// Mount 在 path 注册 GET 与 POST 接口，开启 Playground 时在 path/playground 提供调试页面
+func (s *Server) Mount(r gin.IRouter, path string) {
+	h := s.Handler()
+	r.GET(path, h)
+	r.POST(path, h)
+	if s.opts.Playground {
+		r.GET(path+"/playground", s.PlaygroundHandler(path))
+	}
+}
+
+// Shutdown 拒绝新的操作并结束进行中的订阅，等待订阅退出或 ctx 结束
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closing = true
+	n := len(s.subs)
+	for sub := range s.subs {
+		sub.cancel()
+	}
+	s.mu.Unlock()
+	if n > 0 {
+		s.opts.Logger.Info("Closing GraphQL subscriptions", zap.Int("count", n))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.finished.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// aroundOperation 记录操作日志与指标，并跟踪订阅以便关闭时结束
+func (s *Server) aroundOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	oc := graphql.GetOperationContext(ctx)
+	opType := "unknown"
+	if oc.Operation != nil {
+		opType = string(oc.Operation.Operation)
+	}
+	start := time.Now()
+
+	if oc.Operation == nil || oc.Operation.Operation != ast.Subscription {
+		handle := next(ctx)
+		return func(ctx context.Context) *graphql.Response {
+			resp := handle(ctx)
+			if resp != nil {
+				s.observe(ctx, oc, opType, start, len(resp.Errors))
+			}
+			return resp
+		}
+	}
+
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		return graphql.OneShot(graphql.ErrorResponse(ctx, "%s", ErrShuttingDown.Error()))
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	sub := &subscription{cancel: cancel}
+	s.subs[sub] = struct{}{}
+	s.finished.Add(1)
+	s.mu.Unlock()
+
+	var errs int
+	handle := next(ctx)
+	return func(ctx context.Context) *graphql.Response {
+		resp := handle(ctx)
+		if resp != nil {
+			errs += len(resp.Errors)
+			return resp
+		}
+		// 订阅结束
+		sub.once.Do(func() {
+			cancel()
+			s.mu.Lock()
+			delete(s.subs, sub)
+			s.mu.Unlock()
+			s.finished.Done()
+			s.observe(ctx, oc, opType, start, errs)
+		})
+		return nil
+	}
+}
+
+func (s *Server) observe(ctx context.Context, oc *graphql.OperationContext, opType string, start time.Time, errs int) {
+	elapsed := time.Since(start)
+	status := "ok"
+	if errs > 0 {
+		status = "error"
+	}
+	metrics.GraphQLOperations.WithLabelValues(opType, status).Observe(elapsed.Seconds())
+
+	fields := append(s.fields(ctx),
+		zap.String("operation", oc.OperationName),
+		zap.String("type", opType),
+		zap.Duration("latency", elapsed),
+		zap.Int("errors", errs),
+	)
+	if errs > 0 {
+		s.opts.Logger.Warn("GraphQL operation failed", fields...)
+		return
+	}
+	s.opts.Logger.Debug("GraphQL operation", fields...)
+}
+
+func (s *Server) fields(ctx context.Context) []zap.Field {
+	info, _ := ctx.Value(requestInfoKey{}).(requestInfo)
+	var fields []zap.Field
+	if info.traceID != "" {
+		fields = append(fields, zap.String("trace_id", info.traceID))
+	}
+	if info.requestID != "" {
+		fields = append(fields, zap.String("request_id", info.requestID))
+	}
+	return fields
+}
@@ -0,0 +1,62 @@
+package grpcx
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+
+	"github.com/gaoxin19/ginx/middleware"
+)
+
+// GatewayOptions grpc-gateway 配置
+type GatewayOptions struct {
+	// ForwardHeaders 额外转发为 gRPC metadata 的请求头，X-Request-ID 与 traceparent 默认转发
+	ForwardHeaders []string
+	// MuxOptions 其它 runtime.ServeMuxOption
+	MuxOptions []runtime.ServeMuxOption
+}
+
+// NewGateway 创建 grpc-gateway 的 ServeMux，生成的 RegisterXxxHandlerServer 或
+// RegisterXxxHandlerFromEndpoint 注册到该 mux 后，通过 Mount 挂载到引擎
+func NewGateway(opts GatewayOptions) *runtime.ServeMux {
+	forward := map[string]bool{
+		strings.ToLower(middleware.RequestIDHeader): true,
+		"traceparent": true,
+		"tracestate":  true,
+	}
+	for _, h := range opts.ForwardHeaders {
+		forward[strings.ToLower(h)] = true
+	}
+
+	muxOpts := append([]runtime.ServeMuxOption{
+		runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+			if forward[strings.ToLower(key)] {
+				return strings.ToLower(key), true
+			}
+			return runtime.DefaultHeaderMatcher(key)
+		}),
+	}, opts.MuxOptions...)
+	return runtime.NewServeMux(muxOpts...)
+}
+
+// Mount 将 gateway 挂载到 prefix 下，请求会先经过路由器上的中间件（鉴权、日志、指标等）；
+// 请求路径原样交给 gateway，prefix 应为 proto 中 HTTP 注解路径的公共前缀，如 /v1
+func Mount(r gin.IRouter, prefix string, mux http.Handler) {
+	handler := func(c *gin.Context) {
+		req := c.Request
+		if id := middleware.GetRequestID(c); id != "" && req.Header.Get(middleware.RequestIDHeader) == "" {
+			req = req.Clone(req.Context())
+			req.Header.Set(middleware.RequestIDHeader, id)
+		}
+		mux.ServeHTTP(c.Writer, req)
+	}
+
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix != "/" {
+		r.Any(prefix, handler)
+		prefix += "/"
+	}
+	r.Any(prefix+"*path", handler)
+}
@@ -0,0 +1,188 @@
+// Package grpcx 提供与 ginx 引擎共享生命周期的 gRPC 服务。
+//
+// 通过 engine.AddServer("grpc", ":9090", grpcx.New(grpcx.Options{Logger: engine.Logger()}))
+// 与 HTTP 服务一同启动，或通过 engine.AddMuxServer("grpc", srv) 与 HTTP 服务共用同一端口；
+// 平滑重启时监听器由新进程继承，旧进程在关闭时执行 GracefulStop。
+package grpcx
+
+import (
+	"context"
+	"net"
+	"runtime/debug"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/gaoxin19/ginx/middleware"
+)
+
+// Options gRPC 服务配置
+type Options struct {
+	// Logger 日志实例，默认不输出日志
+	Logger *zap.Logger
+	// UnaryInterceptors 一元调用拦截器，在内置的恢复与日志拦截器之后执行
+	UnaryInterceptors []grpc.UnaryServerInterceptor
+	// StreamInterceptors 流式调用拦截器，在内置的恢复与日志拦截器之后执行
+	StreamInterceptors []grpc.StreamServerInterceptor
+	// ServerOptions 其它 grpc.ServerOption
+	ServerOptions []grpc.ServerOption
+	// DisableHealth 不注册 grpc.health.v1 健康检查服务
+	DisableHealth bool
+	// Reflection 注册反射服务，便于 grpcurl 等工具调试
+	Reflection bool
+}
+
+// Server gRPC 服务，实现 ginx.Server
+type Server struct {
+	*grpc.Server
+	health *health.Server
+}
+
+// New 创建 gRPC 服务，内置 panic 恢复与访问日志拦截器
+func New(opts Options) *Server {
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	unary := append([]grpc.UnaryServerInterceptor{
+		unaryRecovery(opts.Logger),
+		unaryLogger(opts.Logger),
+	}, opts.UnaryInterceptors...)
+	stream := append([]grpc.StreamServerInterceptor{
+		streamRecovery(opts.Logger),
+		streamLogger(opts.Logger),
+	}, opts.StreamInterceptors...)
+
+	serverOpts := append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}, opts.ServerOptions...)
+
+	s := &Server{Server: grpc.NewServer(serverOpts...)}
+	if !opts.DisableHealth {
+		s.health = health.NewServer()
+		healthpb.RegisterHealthServer(s.Server, s.health)
+	}
+	if opts.Reflection {
+		reflection.Register(s.Server)
+	}
+	return s
+}
+
+// Serve 在 ln 上提供服务
+func (s *Server) Serve(ln net.Listener) error {
+	if err := s.Server.Serve(ln); err != nil && err != grpc.ErrServerStopped {
+		return err
+	}
+	return nil
+}
+
+// Shutdown 将健康状态置为 NOT_SERVING 并等待进行中的调用结束，ctx 超时后强制关闭
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.health != nil {
+		s.health.Shutdown()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		return ctx.Err()
+	}
+}
+
+// SetServingStatus 设置指定服务的健康状态，service 为空表示整体状态
+func (s *Server) SetServingStatus(service string, serving bool) {
+	if s.health == nil {
+		return
+	}
+	st := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		st = healthpb.HealthCheckResponse_SERVING
+	}
+	s.health.SetServingStatus(service, st)
+}
+
+func unaryRecovery(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(logger, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func streamRecovery(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(logger, info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recovered(logger *zap.Logger, method string, r any) error {
+	logger.Error("Panic recovered",
+		zap.Any("error", r),
+		zap.String("method", method),
+		zap.ByteString("stack", debug.Stack()),
+	)
+	return status.Error(codes.Internal, "internal error")
+}
+
+func unaryLogger(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func streamLogger(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), logger, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(ctx context.Context, logger *zap.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.String("code", code.String()),
+		zap.Duration("latency", time.Since(start)),
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(middleware.RequestIDHeader); len(ids) > 0 {
+			fields = append(fields, zap.String("request_id", ids[0]))
+		}
+	}
+
+	switch code {
+	case codes.OK, codes.Canceled, codes.NotFound, codes.InvalidArgument, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange:
+		logger.Info("gRPC call", fields...)
+	default:
+		logger.Error("gRPC call", append(fields, zap.Error(err))...)
+	}
+}
@@ -0,0 +1,118 @@
+package ginx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type ginContextKey struct{}
+
+// RequestContext 返回携带 *gin.Context 的请求 context，传给 NewHTTPClient 创建的客户端时会透传请求 ID 与 trace
+func RequestContext(c *gin.Context) context.Context {
+	return context.WithValue(c.Request.Context(), ginContextKey{}, c)
+}
+
+// GinContext 从 Handle 或 RequestContext 传入的 context 中取回 *gin.Context
+func GinContext(ctx context.Context) *gin.Context {
+	c, _ := ctx.Value(ginContextKey{}).(*gin.Context)
+	return c
+}
+
+// StatusCoder 响应类型实现该接口时使用其返回的状态码，默认 200
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Empty 无请求参数或无响应内容时使用的类型
+type Empty struct{}
+
+// Handle 将类型化的处理函数适配为 gin.HandlerFunc：依次从路径参数（uri 标签）、
+// 查询参数（form 标签）和请求体（JSON 或表单）绑定 Req，统一校验后调用 fn，
+// 成功时按全局响应包装写出，失败时按 ToError 映射为 problem+json
+func Handle[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) gin.HandlerFunc {
+	h := func(c *gin.Context) {
+		var req Req
+		if err := bindRequest(c, &req); err != nil {
+			WriteError(c, err)
+			return
+		}
+
+		resp, err := fn(RequestContext(c), req)
+		if err != nil {
+			WriteError(c, err)
+			return
+		}
+
+		status := http.StatusOK
+		if sc, ok := any(resp).(StatusCoder); ok {
+			status = sc.StatusCode()
+		}
+		if _, ok := any(resp).(Empty); ok || status == http.StatusNoContent {
+			c.Status(status)
+			return
+		}
+		respond(c, status, resp)
+	}
+	registerTyped(h, reflect.TypeFor[Req](), reflect.TypeFor[Resp]())
+	return h
+}
+
+// bindRequest 绑定路径参数、查询参数与请求体，最后统一执行一次校验
+func bindRequest(c *gin.Context, obj any) error {
+	if len(c.Params) > 0 {
+		params := make(map[string][]string, len(c.Params))
+		for _, p := range c.Params {
+			params[p.Key] = []string{p.Value}
+		}
+		if err := binding.MapFormWithTag(obj, params, "uri"); err != nil {
+			return badRequest(err)
+		}
+	}
+
+	if err := binding.MapFormWithTag(obj, c.Request.URL.Query(), "form"); err != nil {
+		return badRequest(err)
+	}
+
+	if c.Request.Body != nil && c.Request.ContentLength != 0 {
+		mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+		switch mediaType {
+		case "application/x-www-form-urlencoded", "multipart/form-data":
+			if err := c.Request.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+				return badRequest(err)
+			}
+			if err := binding.MapFormWithTag(obj, c.Request.PostForm, "form"); err != nil {
+				return badRequest(err)
+			}
+		default:
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				return badRequest(err)
+			}
+			if len(body) > 0 {
+				if err := JSON().Unmarshal(body, obj); err != nil {
+					return badRequest(err)
+				}
+			}
+		}
+	}
+
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// badRequest 包装无法被 ErrorMapper 识别的绑定错误
+func badRequest(err error) error {
+	if e := ToError(err); e.Status != http.StatusInternalServerError {
+		return err
+	}
+	return NewError(http.StatusBadRequest, "invalid_request", err.Error()).Wrap(err)
+}
@@ -0,0 +1,155 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Status 检查状态
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusFail     Status = "fail"
+	StatusDraining Status = "draining"
+)
+
+// DefaultTimeout 默认单次检查超时时间
+const DefaultTimeout = 5 * time.Second
+
+// CheckFunc 健康检查函数，返回 nil 表示健康
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Status    Status  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report 健康检查报告
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker 健康检查管理器
+type Checker struct {
+	mu       sync.RWMutex
+	checks   []check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// New 创建健康检查管理器，timeout 为 0 时使用 DefaultTimeout
+func New(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout}
+}
+
+// Register 注册一个命名的就绪检查，同名检查会被替换
+func (c *Checker) Register(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range c.checks {
+		if c.checks[i].name == name {
+			c.checks[i].fn = fn
+			return
+		}
+	}
+	c.checks = append(c.checks, check{name: name, fn: fn})
+}
+
+// SetDraining 设置是否处于排空状态，排空期间就绪检查始终返回失败
+func (c *Checker) SetDraining(draining bool) {
+	c.draining.Store(draining)
+}
+
+// Draining 返回是否处于排空状态
+func (c *Checker) Draining() bool {
+	return c.draining.Load()
+}
+
+// Check 并发执行所有检查并汇总结果
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := make([]check, len(c.checks))
+	copy(checks, c.checks)
+	c.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, chk := range checks {
+		wg.Add(1)
+		go func(i int, chk check) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, chk.fn)
+		}(i, chk)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+	for i, chk := range checks {
+		report.Checks[chk.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	if c.Draining() {
+		report.Status = StatusDraining
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, fn CheckFunc) (result CheckResult) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			result = CheckResult{Status: StatusFail, Error: "panic during health check"}
+		}
+		result.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	}()
+
+	if err := fn(ctx); err != nil {
+		return CheckResult{Status: StatusFail, Error: err.Error()}
+	}
+	return CheckResult{Status: StatusOK}
+}
+
+// LivenessHandler 存活探针，进程能够响应即返回 200
+func (c *Checker) LivenessHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, Report{Status: StatusOK})
+	}
+}
+
+// ReadinessHandler 就绪探针，任一检查失败或处于排空状态时返回 503
+func (c *Checker) ReadinessHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		report := c.Check(ctx.Request.Context())
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		ctx.JSON(status, report)
+	}
+}
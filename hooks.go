@@ -0,0 +1,229 @@
+package ginx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// HookOption 钩子选项
+type HookOption func(*hookOptions)
+
+type hookOptions struct {
+	priority int
+	timeout  time.Duration
+}
+
+// WithPriority 设置钩子优先级，数值越小越先执行，相同优先级按注册顺序执行
+func WithPriority(priority int) HookOption {
+	return func(o *hookOptions) {
+		o.priority = priority
+	}
+}
+
+// WithHookTimeout 设置单个钩子的超时时间
+func WithHookTimeout(timeout time.Duration) HookOption {
+	return func(o *hookOptions) {
+		o.timeout = timeout
+	}
+}
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+	hookOptions
+}
+
+// RegisterOnStart 注册启动钩子，在监听端口之后、开始接收请求之前按优先级依次执行，
+// 任一钩子返回错误都会终止启动
+func (engine *Engine) RegisterOnStart(name string, fn func(ctx context.Context) error, opts ...HookOption) {
+	engine.hooksMu.Lock()
+	defer engine.hooksMu.Unlock()
+	engine.startHooks = append(engine.startHooks, newHook(name, fn, opts))
+}
+
+// RegisterOnShutdown 注册关闭钩子，在 HTTP 服务停止接收请求后按优先级依次执行
+func (engine *Engine) RegisterOnShutdown(name string, fn func(ctx context.Context) error, opts ...HookOption) {
+	engine.hooksMu.Lock()
+	defer engine.hooksMu.Unlock()
+	engine.shutdownHooks = append(engine.shutdownHooks, newHook(name, fn, opts))
+}
+
+// RegisterOnDrain 注册排空钩子，在关闭或升级时与 HTTP 服务关闭同时并发执行，
+// 用于通知 SSE、WebSocket 等长连接发送结束消息并主动断开，避免阻塞到超时
+func (engine *Engine) RegisterOnDrain(name string, fn func(ctx context.Context) error, opts ...HookOption) {
+	engine.hooksMu.Lock()
+	defer engine.hooksMu.Unlock()
+	engine.drainHooks = append(engine.drainHooks, newHook(name, fn, opts))
+}
+
+// UpgradePhase 升级钩子的执行阶段
+type UpgradePhase int
+
+const (
+	// BeforeUpgrade 启动新进程之前，如刷新缓冲、从负载均衡摘除；钩子返回错误会取消本次升级
+	BeforeUpgrade UpgradePhase = iota
+	// AfterUpgrade 新进程就绪之后、当前进程开始关闭之前，如记录日志、推送指标
+	AfterUpgrade
+)
+
+// RegisterOnUpgrade 注册升级钩子，在 SIGHUP、管理接口或控制套接字触发的平滑升级中按优先级依次执行
+func (engine *Engine) RegisterOnUpgrade(phase UpgradePhase, name string, fn func(ctx context.Context) error, opts ...HookOption) {
+	engine.hooksMu.Lock()
+	defer engine.hooksMu.Unlock()
+	switch phase {
+	case BeforeUpgrade:
+		engine.beforeUpgradeHooks = append(engine.beforeUpgradeHooks, newHook(name, fn, opts))
+	case AfterUpgrade:
+		engine.afterUpgradeHooks = append(engine.afterUpgradeHooks, newHook(name, fn, opts))
+	}
+}
+
+func newHook(name string, fn func(ctx context.Context) error, opts []HookOption) hook {
+	h := hook{name: name, fn: fn}
+	for _, opt := range opts {
+		opt(&h.hookOptions)
+	}
+	return h
+}
+
+// sortedHooks 返回按优先级排序后的钩子副本
+func (engine *Engine) sortedHooks(hooks *[]hook) []hook {
+	engine.hooksMu.Lock()
+	sorted := make([]hook, len(*hooks))
+	copy(sorted, *hooks)
+	engine.hooksMu.Unlock()
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].priority < sorted[j].priority
+	})
+	return sorted
+}
+
+func (engine *Engine) executeStartHooks(ctx context.Context) error {
+	for _, h := range engine.sortedHooks(&engine.startHooks) {
+		start := time.Now()
+		if err := runHook(ctx, h); err != nil {
+			engine.logger.Error("Start hook failed",
+				zap.String("hook", h.name),
+				zap.Duration("elapsed", time.Since(start)),
+				zap.Error(err),
+			)
+			return fmt.Errorf("start hook %q: %w", h.name, err)
+		}
+		engine.logger.Info("Start hook completed",
+			zap.String("hook", h.name),
+			zap.Duration("elapsed", time.Since(start)),
+		)
+	}
+	return nil
+}
+
+func (engine *Engine) executeShutdownHooks(ctx context.Context) error {
+	var errs []error
+	for _, h := range engine.sortedHooks(&engine.shutdownHooks) {
+		start := time.Now()
+		if err := runHook(ctx, h); err != nil {
+			engine.logger.Error("Shutdown hook failed",
+				zap.String("hook", h.name),
+				zap.Duration("elapsed", time.Since(start)),
+				zap.Error(err),
+			)
+			errs = append(errs, fmt.Errorf("shutdown hook %q: %w", h.name, err))
+			continue
+		}
+		engine.logger.Info("Shutdown hook completed",
+			zap.String("hook", h.name),
+			zap.Duration("elapsed", time.Since(start)),
+		)
+	}
+	return errors.Join(errs...)
+}
+
+func (engine *Engine) executeDrainHooks(ctx context.Context) error {
+	hooks := engine.sortedHooks(&engine.drainHooks)
+	errs := make([]error, len(hooks))
+
+	var wg sync.WaitGroup
+	for i, h := range hooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			if err := runHook(ctx, h); err != nil {
+				engine.logger.Error("Drain hook failed",
+					zap.String("hook", h.name),
+					zap.Duration("elapsed", time.Since(start)),
+					zap.Error(err),
+				)
+				errs[i] = fmt.Errorf("drain hook %q: %w", h.name, err)
+				return
+			}
+			engine.logger.Info("Drain hook completed",
+				zap.String("hook", h.name),
+				zap.Duration("elapsed", time.Since(start)),
+			)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// executeBeforeUpgradeHooks 任一钩子失败时停止执行并取消升级
+func (engine *Engine) executeBeforeUpgradeHooks() error {
+	for _, h := range engine.sortedHooks(&engine.beforeUpgradeHooks) {
+		start := time.Now()
+		if err := runHook(context.Background(), h); err != nil {
+			engine.logger.Error("Upgrade hook failed",
+				zap.String("hook", h.name),
+				zap.Duration("elapsed", time.Since(start)),
+				zap.Error(err),
+			)
+			return fmt.Errorf("upgrade hook %q: %w", h.name, err)
+		}
+		engine.logger.Info("Upgrade hook completed",
+			zap.String("hook", h.name),
+			zap.Duration("elapsed", time.Since(start)),
+		)
+	}
+	return nil
+}
+
+// executeAfterUpgradeHooks 新进程已就绪，钩子失败只记录日志
+func (engine *Engine) executeAfterUpgradeHooks() {
+	for _, h := range engine.sortedHooks(&engine.afterUpgradeHooks) {
+		start := time.Now()
+		if err := runHook(context.Background(), h); err != nil {
+			engine.logger.Error("Upgrade hook failed",
+				zap.String("hook", h.name),
+				zap.Duration("elapsed", time.Since(start)),
+				zap.Error(err),
+			)
+			continue
+		}
+		engine.logger.Info("Upgrade hook completed",
+			zap.String("hook", h.name),
+			zap.Duration("elapsed", time.Since(start)),
+		)
+	}
+}
+
+func runHook(ctx context.Context, h hook) (err error) {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h.fn(ctx)
+}
@@ -0,0 +1,246 @@
+package ginx
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/breaker"
+	"github.com/gaoxin19/ginx/metrics"
+	"github.com/gaoxin19/ginx/middleware"
+)
+
+// HTTPClientOptions 出站 HTTP 客户端配置
+type HTTPClientOptions struct {
+	// Name 客户端名称，用于指标与日志，默认 default
+	Name string
+	// Timeout 单次调用（含重试）的总超时，默认 30s
+	Timeout time.Duration
+	// Logger 日志实例，默认使用全局日志
+	Logger *zap.Logger
+
+	// Retries 最大重试次数，默认不重试；仅重试幂等方法或可重放请求体的请求
+	Retries int
+	// RetryBackoff 首次重试的等待时间，之后指数增长并加入随机抖动，默认 100ms
+	RetryBackoff time.Duration
+	// RetryMaxBackoff 重试等待时间上限，默认 2s
+	RetryMaxBackoff time.Duration
+	// RetryOn 判断是否重试，默认在连接错误与 502、503、504 时重试
+	RetryOn func(resp *http.Response, err error) bool
+
+	// MaxIdleConnsPerHost 每个主机保持的空闲连接数，默认 16
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost 每个主机的最大连接数，0 表示不限制
+	MaxConnsPerHost int
+	// IdleConnTimeout 空闲连接超时，默认 90s
+	IdleConnTimeout time.Duration
+
+	// Breaker 按主机熔断，为 nil 时不启用
+	Breaker *breaker.Group
+	// Transport 底层传输，默认按上述连接池参数创建
+	Transport http.RoundTripper
+}
+
+// NewHTTPClient 创建出站 HTTP 客户端，自动透传请求 ID 与 traceparent（需使用 RequestContext 作为请求 context），
+// 记录日志与 ginx_client_* 指标，并按配置重试与熔断
+func NewHTTPClient(opts HTTPClientOptions) *http.Client {
+	if opts.Name == "" {
+		opts.Name = "default"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.Logger == nil {
+		opts.Logger = L()
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = 100 * time.Millisecond
+	}
+	if opts.RetryMaxBackoff <= 0 {
+		opts.RetryMaxBackoff = 2 * time.Second
+	}
+	if opts.RetryOn == nil {
+		opts.RetryOn = defaultRetryOn
+	}
+
+	next := opts.Transport
+	if next == nil {
+		next = newTransport(opts)
+	}
+	if opts.Breaker != nil {
+		next = breaker.Transport(opts.Breaker, next)
+	}
+
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: &clientTransport{opts: opts, next: next},
+	}
+}
+
+// NewHTTPClient 创建使用引擎日志的出站 HTTP 客户端
+func (e *Engine) NewHTTPClient(opts HTTPClientOptions) *http.Client {
+	if opts.Logger == nil {
+		opts.Logger = e.logger
+	}
+	return NewHTTPClient(opts)
+}
+
+func newTransport(opts HTTPClientOptions) *http.Transport {
+	maxIdle := opts.MaxIdleConnsPerHost
+	if maxIdle <= 0 {
+		maxIdle = 16
+	}
+	idleTimeout := opts.IdleConnTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = 90 * time.Second
+	}
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdle * 8,
+		MaxIdleConnsPerHost:   maxIdle,
+		MaxConnsPerHost:       opts.MaxConnsPerHost,
+		IdleConnTimeout:       idleTimeout,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+func defaultRetryOn(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+type clientTransport struct {
+	opts HTTPClientOptions
+	next http.RoundTripper
+}
+
+func (t *clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = propagate(req)
+	retryable := t.opts.Retries > 0 && replayable(req)
+	if retryable {
+		// 重试时会替换请求体，RoundTripper 不应修改调用方的请求
+		req = req.Clone(req.Context())
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.roundTrip(req, attempt)
+		if !retryable || attempt >= t.opts.Retries || !t.opts.RetryOn(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+			resp.Body.Close()
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		select {
+		case <-time.After(t.backoff(attempt)):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// roundTrip 执行单次请求并记录日志与指标
+func (t *clientTransport) roundTrip(req *http.Request, attempt int) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.ClientRequests.WithLabelValues(t.opts.Name, req.URL.Host, req.Method, code).Inc()
+	metrics.ClientDuration.WithLabelValues(t.opts.Name, req.URL.Host, req.Method).Observe(elapsed.Seconds())
+
+	fields := []zap.Field{
+		zap.String("client", t.opts.Name),
+		zap.String("method", req.Method),
+		zap.String("url", req.URL.Redacted()),
+		zap.Duration("latency", elapsed),
+		zap.Int("attempt", attempt+1),
+		zap.String("request_id", req.Header.Get(middleware.RequestIDHeader)),
+	}
+	switch {
+	case err != nil:
+		t.opts.Logger.Warn("Outbound request failed", append(fields, zap.Error(err))...)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		t.opts.Logger.Warn("Outbound request failed", append(fields, zap.Int("status", resp.StatusCode))...)
+	default:
+		t.opts.Logger.Debug("Outbound request", append(fields, zap.Int("status", resp.StatusCode))...)
+	}
+	return resp, err
+}
+
+// backoff 指数退避并加入 0.5~1 倍的随机抖动
+func (t *clientTransport) backoff(attempt int) time.Duration {
+	d := t.opts.RetryBackoff << attempt
+	if d <= 0 || d > t.opts.RetryMaxBackoff {
+		d = t.opts.RetryMaxBackoff
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// propagate 从请求 context 中取出当前请求的 ID 与 traceparent 并写入出站请求头
+func propagate(req *http.Request) *http.Request {
+	c := ginContextFrom(req.Context())
+	if c == nil {
+		return req
+	}
+
+	req = req.Clone(req.Context())
+	if id := middleware.GetRequestID(c); id != "" && req.Header.Get(middleware.RequestIDHeader) == "" {
+		req.Header.Set(middleware.RequestIDHeader, id)
+	}
+	if tp := c.GetHeader("traceparent"); tp != "" && req.Header.Get("traceparent") == "" {
+		req.Header.Set("traceparent", tp)
+		if ts := c.GetHeader("tracestate"); ts != "" {
+			req.Header.Set("tracestate", ts)
+		}
+	}
+	return req
+}
+
+func ginContextFrom(ctx context.Context) *gin.Context {
+	if c := GinContext(ctx); c != nil {
+		return c
+	}
+	c, _ := ctx.(*gin.Context)
+	return c
+}
+
+// replayable 幂等方法或可通过 GetBody 重放请求体的请求才允许重试
+func replayable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	}
+	return req.GetBody != nil && req.Header.Get("Idempotency-Key") != ""
+}
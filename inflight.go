@@ -0,0 +1,54 @@
+package ginx
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/middleware"
+)
+
+// inflightTracker 记录正在处理的请求，强制关闭时用于输出被中断的请求
+type inflightTracker struct {
+	mu   sync.Mutex
+	reqs map[*http.Request]time.Time
+}
+
+func newInflightTracker() *inflightTracker {
+	return &inflightTracker{reqs: make(map[*http.Request]time.Time)}
+}
+
+func (t *inflightTracker) wrap(next http.Handler) http.Handler {
+	if next == nil {
+		next = http.DefaultServeMux
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.mu.Lock()
+		t.reqs[r] = time.Now()
+		t.mu.Unlock()
+		defer func() {
+			t.mu.Lock()
+			delete(t.reqs, r)
+			t.mu.Unlock()
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// logAll 输出所有仍在处理的请求并返回数量
+func (t *inflightTracker) logAll(logger *zap.Logger, msg string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for r, start := range t.reqs {
+		logger.Warn(msg,
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.String("remote_addr", r.RemoteAddr),
+			zap.String("request_id", r.Header.Get(middleware.RequestIDHeader)),
+			zap.Duration("elapsed", time.Since(start)),
+		)
+	}
+	return len(t.reqs)
+}
@@ -0,0 +1,74 @@
+package ginx
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// JSON 引擎名称
+const (
+	JSONStd      = "std"
+	JSONIterator = "jsoniter"
+	JSONSonic    = "sonic"
+)
+
+// JSONEngine JSON 编解码实现，用于响应助手与 Handle 的请求体绑定
+type JSONEngine interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+type stdJSON struct{}
+
+func (stdJSON) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (stdJSON) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+var (
+	jsonEnginesMu sync.RWMutex
+	jsonEngines   = map[string]JSONEngine{
+		JSONStd:      stdJSON{},
+		JSONIterator: jsoniter.ConfigCompatibleWithStandardLibrary,
+	}
+
+	currentJSON atomic.Pointer[JSONEngine]
+)
+
+func init() {
+	var std JSONEngine = stdJSON{}
+	currentJSON.Store(&std)
+}
+
+// RegisterJSONEngine 注册 JSON 引擎，之后可通过名称在 Options.JSONEngine 中选用
+func RegisterJSONEngine(name string, engine JSONEngine) {
+	jsonEnginesMu.Lock()
+	defer jsonEnginesMu.Unlock()
+	jsonEngines[name] = engine
+}
+
+// SetJSONEngine 切换全局 JSON 引擎，名称为空时使用标准库
+func SetJSONEngine(name string) error {
+	if name == "" {
+		name = JSONStd
+	}
+	jsonEnginesMu.RLock()
+	engine, ok := jsonEngines[name]
+	jsonEnginesMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown json engine %q", name)
+	}
+	currentJSON.Store(&engine)
+	return nil
+}
+
+// JSON 返回当前使用的 JSON 引擎
+func JSON() JSONEngine {
+	return *currentJSON.Load()
+}
+
+func marshalJSON(v any) ([]byte, error) {
+	return JSON().Marshal(v)
+}
@@ -0,0 +1,9 @@
+//go:build amd64 || arm64
+
+package ginx
+
+import "github.com/bytedance/sonic"
+
+func init() {
+	RegisterJSONEngine(JSONSonic, sonic.ConfigStd)
+}
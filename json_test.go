@@ -0,0 +1,84 @@
+package ginx
+
+import (
+	"testing"
+	"time"
+)
+
+// 基准测试覆盖所有已注册的引擎，sonic 仅在 amd64/arm64 上注册，其它平台自动跳过：
+//
+//	go test -run '^$' -bench BenchmarkJSON -benchmem
+//	GOARCH=386 go test -run '^$' -bench BenchmarkJSON -benchmem
+
+type benchItem struct {
+	ID    int64             `json:"id"`
+	Name  string            `json:"name"`
+	Price float64           `json:"price"`
+	Tags  []string          `json:"tags"`
+	Attrs map[string]string `json:"attrs"`
+}
+
+type benchPayload struct {
+	RequestID string      `json:"request_id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Total     int         `json:"total"`
+	Items     []benchItem `json:"items"`
+}
+
+func newBenchPayload() benchPayload {
+	p := benchPayload{RequestID: "7f3c2a9e-1b4d-4c8a-9e2f-5d6b7a8c9d0e", CreatedAt: time.Unix(1700000000, 0).UTC()}
+	for i := range 50 {
+		p.Items = append(p.Items, benchItem{
+			ID:    int64(i),
+			Name:  "item name with some length",
+			Price: float64(i) * 1.25,
+			Tags:  []string{"alpha", "beta", "gamma"},
+			Attrs: map[string]string{"color": "red", "size": "xl"},
+		})
+	}
+	p.Total = len(p.Items)
+	return p
+}
+
+func benchEngines(b *testing.B, fn func(b *testing.B, engine JSONEngine)) {
+	for _, name := range []string{JSONStd, JSONIterator, JSONSonic} {
+		jsonEnginesMu.RLock()
+		engine, ok := jsonEngines[name]
+		jsonEnginesMu.RUnlock()
+		b.Run(name, func(b *testing.B) {
+			if !ok {
+				b.Skipf("json engine %q is not available on this platform", name)
+			}
+			fn(b, engine)
+		})
+	}
+}
+
+func BenchmarkJSONMarshal(b *testing.B) {
+	p := newBenchPayload()
+	benchEngines(b, func(b *testing.B, engine JSONEngine) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := engine.Marshal(&p); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkJSONUnmarshal(b *testing.B) {
+	data, err := stdJSON{}.Marshal(newBenchPayload())
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchEngines(b, func(b *testing.B, engine JSONEngine) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for range b.N {
+			var p benchPayload
+			if err := engine.Unmarshal(data, &p); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package ginx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/metrics"
+	"github.com/gaoxin19/ginx/upgrader"
+)
+
+// serveOptions 单次生命周期的运行参数
+type serveOptions struct {
+	// listen 为附加服务创建监听器
+	listen func(network, addr string) (net.Listener, error)
+	// ready 在启动钩子执行完成后调用，用于通知父进程退出
+	ready func() error
+	// exit 升级完成后关闭，通知当前进程开始优雅关闭
+	exit <-chan struct{}
+	// upgrade 触发一次平滑升级，为空时不支持升级
+	upgrade func() error
+	// upgradeBinary 升级到指定的可执行文件
+	upgradeBinary func(path string) error
+	// listenPacket 创建或继承 UDP 等数据报套接字
+	listenPacket func(network, addr string) (net.PacketConn, error)
+	// handoff 升级时向新进程传递文件与状态，为空时不支持
+	handoff upgrader.Handoff
+}
+
+// serve 统一的服务生命周期：创建附加服务监听器 -> 执行启动钩子 -> 开始接收请求、启动 Runner -> 等待退出 -> 优雅关闭 -> 执行关闭钩子，
+// Run、GracefulRun 与 GracefulServe 均通过它运行，以保证行为一致
+func (e *Engine) serve(ctx context.Context, server *http.Server, ln net.Listener, opts serveOptions) error {
+	var stop context.CancelFunc
+	if len(e.signals.shutdown) > 0 {
+		ctx, stop = signal.NotifyContext(ctx, e.signals.shutdown...)
+	} else {
+		ctx, stop = context.WithCancel(ctx)
+	}
+	defer stop()
+	e.setControl(stop, opts)
+	defer e.setControl(nil, serveOptions{})
+	e.stopped(ReasonNone, nil)
+
+	var lns []net.Listener
+	if opts.listen != nil {
+		var err error
+		if lns, err = e.listenServers(opts.listen); err != nil {
+			ln.Close()
+			return e.stopped(ReasonListenerError, err)
+		}
+	}
+	closeAll := func() {
+		ln.Close()
+		for _, l := range lns {
+			if l != nil {
+				l.Close()
+			}
+		}
+	}
+
+	if err := e.executeStartHooks(ctx); err != nil {
+		closeAll()
+		return e.stopped(ReasonHookFailure, err)
+	}
+
+	if opts.ready != nil {
+		if err := opts.ready(); err != nil {
+			closeAll()
+			return e.stopped(ReasonHookFailure, fmt.Errorf("failed to mark as ready: %w", err))
+		}
+	}
+
+	if lns == nil {
+		lns = make([]net.Listener, len(e.extraServers()))
+	}
+	ln = e.httpListener(server, ln, lns)
+
+	e.logger.Info("Server is starting",
+		zap.String("addr", ln.Addr().String()),
+		zap.Int("pid", os.Getpid()),
+	)
+
+	go e.watchLogLevelSignal(ctx)
+	go e.watchLogRotateSignal(ctx)
+
+	errChan := make(chan error, 1)
+	go func() {
+		if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			errChan <- err
+		}
+	}()
+	e.startServers(lns, errChan)
+	e.startRunners(errChan)
+
+	var reason ShutdownReason
+	select {
+	case <-ctx.Done():
+		e.logger.Info("Received shutdown signal, starting graceful shutdown...")
+		reason = ReasonSignal
+		e.preDrain(server)
+	case <-opts.exit:
+		e.logger.Info("Upgrade completed, starting graceful shutdown...")
+		reason = ReasonUpgrade
+	case err := <-errChan:
+		e.logger.Error("Server error", zap.Error(err))
+		e.shutdown(server)
+		return e.stopped(ReasonListenerError, fmt.Errorf("server error: %w", err))
+	}
+
+	return e.stopped(reason, e.shutdown(server))
+}
+
+// shutdownServer 两阶段关闭 HTTP 服务：先在 Drain.SoftTimeout 内等待请求处理完成，
+// 超时后记录仍在处理的请求并强制关闭所有连接，避免卡住的处理函数使关闭无限期等待
+func (e *Engine) shutdownServer(ctx context.Context, server *http.Server) error {
+	soft := ctx
+	if drain := e.opts().Drain; drain != nil && drain.SoftTimeout > 0 {
+		var cancel context.CancelFunc
+		soft, cancel = context.WithTimeout(ctx, drain.SoftTimeout)
+		defer cancel()
+	}
+
+	err := server.Shutdown(soft)
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	n := e.inflight.logAll(e.logger, "Request cut by forced shutdown")
+	metrics.ForceClosedRequests.Add(float64(n))
+	e.logger.Warn("Drain timed out, force closing connections", zap.Int("requests", n))
+	if cerr := server.Close(); cerr != nil {
+		return errors.Join(err, cerr)
+	}
+	return err
+}
+
+// preDrain 将就绪检查置为不可用，并在 DrainDelay 内继续处理请求，等待负载均衡器摘除流量
+func (e *Engine) preDrain(server *http.Server) {
+	e.health.SetDraining(true)
+	opts := e.opts()
+	if drain := opts.Drain; drain != nil && drain.CloseIdle {
+		server.SetKeepAlivesEnabled(false)
+	}
+
+	delay := opts.DrainDelay
+	if delay <= 0 {
+		return
+	}
+	e.logger.Info("Readiness disabled, waiting before shutdown", zap.Duration("delay", delay))
+	time.Sleep(delay)
+}
+
+// shutdown 在 ShutdownTimeout 内优雅关闭服务，排空钩子、Runner 的停止与服务关闭并发执行，
+// 使长连接与消费者能在超时前主动结束；之后在 ShutdownHookTimeout 内执行关闭钩子
+func (e *Engine) shutdown(server *http.Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.shutdownTimeout())
+	defer cancel()
+
+	e.health.SetDraining(true)
+	start := time.Now()
+
+	drained := make(chan error, 3)
+	go func() {
+		drained <- e.executeDrainHooks(ctx)
+	}()
+	go func() {
+		drained <- e.shutdownServers(ctx)
+	}()
+	go func() {
+		drained <- e.stopRunners(ctx)
+	}()
+
+	var shutdownErr error
+	if err := e.shutdownServer(ctx, server); err != nil {
+		e.logger.Error("Server shutdown error", zap.Error(err))
+		shutdownErr = fmt.Errorf("server shutdown error: %w", err)
+	}
+	for range 3 {
+		if err := <-drained; err != nil && shutdownErr == nil {
+			shutdownErr = err
+		}
+	}
+
+	// http.Server.Shutdown 不会等待被劫持的连接
+	var hijackedTimeout time.Duration
+	if drain := e.opts().Drain; drain != nil {
+		hijackedTimeout = drain.HijackedTimeout
+	}
+	if n := e.conns.waitHijacked(ctx, hijackedTimeout); n > 0 {
+		e.logger.Warn("Force closed hijacked connections", zap.Int("count", n))
+	}
+	e.upgrades.drained(time.Since(start))
+
+	// 关闭钩子（关闭数据库、排空任务、上报错误等）使用独立的超时，
+	// 避免被卡住的请求耗尽 ShutdownTimeout 后所有钩子都拿到已取消的 ctx
+	hookCtx, hookCancel := context.WithTimeout(context.Background(), e.shutdownHookTimeout())
+	defer hookCancel()
+	if err := e.executeShutdownHooks(hookCtx); err != nil {
+		if shutdownErr == nil {
+			shutdownErr = err
+		}
+	}
+
+	if shutdownErr != nil {
+		return shutdownErr
+	}
+
+	e.logger.Info("Server has been shutdown successfully", zap.Int("pid", os.Getpid()))
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/gaoxin19/ginx/redact"
 )
 
 // LogConfig 日志配置
@@ -20,34 +22,47 @@ type LogConfig struct {
 	Compress   bool
 	LocalTime  bool
 	Console    bool
+	// Color 控制台输出按级别着色
+	Color bool
+	// RedactFields 需要脱敏的字段名，RedactPatterns 需要脱敏的内容正则，对所有输出生效
+	RedactFields   []string
+	RedactPatterns []string
 }
 
 // NewLogger 创建日志实例
 func NewLogger(conf *LogConfig) (*zap.Logger, error) {
+	logger, _, _, err := newLogger(conf)
+	return logger, err
+}
+
+// newLogger 创建日志实例，并返回可在运行时调整的日志级别与用于切分的日志文件（未输出到文件时为 nil）
+func newLogger(conf *LogConfig) (*zap.Logger, zap.AtomicLevel, *lumberjack.Logger, error) {
 	if conf.Filename != "" {
 		if err := os.MkdirAll(filepath.Dir(conf.Filename), 0744); err != nil {
-			return nil, fmt.Errorf("can't create log directory: %w", err)
+			return nil, zap.AtomicLevel{}, nil, fmt.Errorf("can't create log directory: %w", err)
 		}
 	}
 
-	level, err := zapcore.ParseLevel(conf.Level)
+	level, err := zap.ParseAtomicLevel(conf.Level)
 	if err != nil {
-		return nil, fmt.Errorf("parse log level error: %w", err)
+		return nil, zap.AtomicLevel{}, nil, fmt.Errorf("parse log level error: %w", err)
 	}
 
 	cores := make([]zapcore.Core, 0)
 	encoderConfig := newEncoderConfig()
 
 	// 文件输出
+	var file *lumberjack.Logger
 	if conf.Filename != "" {
-		fileWriter := zapcore.AddSync(&lumberjack.Logger{
+		file = &lumberjack.Logger{
 			Filename:   conf.Filename,
 			MaxSize:    conf.MaxSize,
 			MaxBackups: conf.MaxBackups,
 			MaxAge:     conf.MaxAge,
 			Compress:   conf.Compress,
 			LocalTime:  conf.LocalTime,
-		})
+		}
+		fileWriter := zapcore.AddSync(file)
 
 		cores = append(cores, zapcore.NewCore(
 			zapcore.NewJSONEncoder(encoderConfig),
@@ -58,7 +73,11 @@ func NewLogger(conf *LogConfig) (*zap.Logger, error) {
 
 	// 控制台输出
 	if conf.Console {
-		consoleEncoder := zapcore.NewConsoleEncoder(encoderConfig)
+		consoleConfig := encoderConfig
+		if conf.Color {
+			consoleConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		}
+		consoleEncoder := zapcore.NewConsoleEncoder(consoleConfig)
 		cores = append(cores, zapcore.NewCore(
 			consoleEncoder,
 			zapcore.Lock(os.Stdout),
@@ -67,9 +86,16 @@ func NewLogger(conf *LogConfig) (*zap.Logger, error) {
 	}
 
 	core := zapcore.NewTee(cores...)
+	if len(conf.RedactFields) > 0 || len(conf.RedactPatterns) > 0 {
+		r, err := redact.New(conf.RedactFields, conf.RedactPatterns)
+		if err != nil {
+			return nil, zap.AtomicLevel{}, nil, err
+		}
+		core = r.Core(core)
+	}
 	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 
-	return logger, nil
+	return logger, level, file, nil
 }
 
 func newEncoderConfig() zapcore.EncoderConfig {
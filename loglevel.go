@@ -0,0 +1,65 @@
+package ginx
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogLevel 返回引擎日志的动态级别，可在运行时通过 SetLevel 调整
+func (e *Engine) LogLevel() zap.AtomicLevel {
+	return e.logLevel
+}
+
+// watchLogLevelSignal 收到 Signals.LogLevel（默认 SIGUSR1）时在 debug 与配置的日志级别之间切换
+func (e *Engine) watchLogLevelSignal(ctx context.Context) {
+	if len(e.signals.logLevel) == 0 {
+		return
+	}
+	configured := e.logLevel.Level()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, e.signals.logLevel...)
+	defer signal.Stop(sig)
+
+	for {
+		select {
+		case <-sig:
+			next := zapcore.DebugLevel
+			if e.logLevel.Level() == zapcore.DebugLevel {
+				next = configured
+			}
+			e.logLevel.SetLevel(next)
+			e.logger.Info("Log level changed", zap.Stringer("level", next))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// watchLogRotateSignal 收到 Signals.LogRotate 时切分日志文件，未输出到文件或未配置信号时不监听
+func (e *Engine) watchLogRotateSignal(ctx context.Context) {
+	if e.logFile == nil || len(e.signals.logRotate) == 0 {
+		return
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, e.signals.logRotate...)
+	defer signal.Stop(sig)
+
+	for {
+		select {
+		case <-sig:
+			if err := e.logFile.Rotate(); err != nil {
+				e.logger.Error("Failed to rotate log file", zap.Error(err))
+				continue
+			}
+			e.logger.Info("Log file rotated")
+		case <-ctx.Done():
+			return
+		}
+	}
+}
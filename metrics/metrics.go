@@ -0,0 +1,202 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace 指标名前缀
+const Namespace = "ginx"
+
+// Registry ginx 的指标注册表，默认包含 Go 运行时与进程指标
+var Registry = func() *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	r.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return r
+}()
+
+// SlowRequests 慢请求计数
+var SlowRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Name:      "slow_requests_total",
+	Help:      "Number of requests exceeding the slow request threshold.",
+}, []string{"method", "route"})
+
+// Connections 按状态统计的当前连接数，state 为 new、active、idle、hijacked
+var Connections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Name:      "connections",
+	Help:      "Number of open connections by state.",
+}, []string{"state"})
+
+// BreakerState 熔断器状态：0 关闭，1 打开，2 半开
+var BreakerState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Name:      "breaker_state",
+	Help:      "Circuit breaker state (0 closed, 1 open, 2 half-open).",
+}, []string{"name"})
+
+// BreakerRejected 熔断器拒绝的请求数
+var BreakerRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Name:      "breaker_rejected_total",
+	Help:      "Number of requests rejected by an open circuit breaker.",
+}, []string{"name"})
+
+// ClientRequests 出站 HTTP 请求数，code 为状态码或 error
+var ClientRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Name:      "client_requests_total",
+	Help:      "Number of outbound HTTP requests.",
+}, []string{"client", "host", "method", "code"})
+
+// ClientDuration 出站 HTTP 请求耗时
+var ClientDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Name:      "client_request_duration_seconds",
+	Help:      "Outbound HTTP request latency in seconds.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"client", "host", "method"})
+
+// UpgradeFailures 平滑升级失败的尝试次数
+var UpgradeFailures = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Name:      "upgrade_failures_total",
+	Help:      "Number of failed graceful upgrade attempts.",
+})
+
+// UpgradeAttempts 平滑升级的尝试次数
+var UpgradeAttempts = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Name:      "upgrade_attempts_total",
+	Help:      "Number of graceful upgrade attempts.",
+})
+
+// UpgradeSuccesses 平滑升级成功次数
+var UpgradeSuccesses = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Name:      "upgrade_successes_total",
+	Help:      "Number of successful graceful upgrades.",
+})
+
+// LastUpgradeTimestamp 最近一次升级成功的 Unix 时间戳
+var LastUpgradeTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Name:      "last_upgrade_timestamp_seconds",
+	Help:      "Unix timestamp of the last successful graceful upgrade.",
+})
+
+// DrainDuration 优雅关闭时排空请求与连接的耗时
+var DrainDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Name:      "drain_duration_seconds",
+	Help:      "Time taken to drain in-flight requests during shutdown or upgrade.",
+	Buckets:   []float64{.01, .05, .1, .5, 1, 2.5, 5, 10, 30, 60},
+})
+
+// ForceClosedRequests 关闭超时后被强制中断的请求数
+var ForceClosedRequests = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Name:      "force_closed_requests_total",
+	Help:      "Number of in-flight requests cut off by a forced shutdown.",
+})
+
+// GraphQLOperations GraphQL 操作耗时，订阅为从开始到结束的时长
+var GraphQLOperations = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Name:      "graphql_operation_duration_seconds",
+	Help:      "Duration of GraphQL operations by type and status.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"type", "status"})
+
+// WorkerJobs 后台任务执行结果计数，status 为 succeeded、failed、retried 或 rejected
+var WorkerJobs = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Name:      "worker_jobs_total",
+	Help:      "Number of background jobs by pool and status.",
+}, []string{"pool", "status"})
+
+// WorkerQueueDepth 等待执行的后台任务数
+var WorkerQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Name:      "worker_queue_depth",
+	Help:      "Number of background jobs waiting in the queue.",
+}, []string{"pool"})
+
+// CronRuns 定时任务执行次数，status 为 succeeded、failed、skipped 或 locked（由其他实例执行）
+var CronRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Name:      "cron_runs_total",
+	Help:      "Number of cron job runs by job and status.",
+}, []string{"job", "status"})
+
+// CronDuration 定时任务执行耗时
+var CronDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Name:      "cron_duration_seconds",
+	Help:      "Duration of cron job runs.",
+	Buckets:   []float64{.01, .1, .5, 1, 5, 10, 30, 60, 300, 900},
+}, []string{"job"})
+
+// CronLastSuccess 定时任务最近一次成功的时间戳
+var CronLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Name:      "cron_last_success_timestamp_seconds",
+	Help:      "Unix timestamp of the last successful run of a cron job.",
+}, []string{"job"})
+
+// ConsumerMessages 消息队列消费结果计数，status 为 succeeded、failed 或 retried
+var ConsumerMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Name:      "consumer_messages_total",
+	Help:      "Number of consumed messages by consumer and status.",
+}, []string{"consumer", "status"})
+
+// ConsumerDuration 单条消息的处理耗时，包含重试
+var ConsumerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Name:      "consumer_duration_seconds",
+	Help:      "Duration of message handling by consumer.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"consumer"})
+
+// WebhookDeliveries Webhook 投递结果计数，status 为 succeeded、retried 或 failed
+var WebhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Name:      "webhook_deliveries_total",
+	Help:      "Number of webhook delivery attempts by status.",
+}, []string{"status"})
+
+// WebhookDuration 单次 Webhook 请求耗时
+var WebhookDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Name:      "webhook_duration_seconds",
+	Help:      "Duration of webhook requests.",
+	Buckets:   prometheus.DefBuckets,
+})
+
+// RedisCommands Redis 命令耗时，status 为 ok 或 error，redis.Nil 视为 ok
+var RedisCommands = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Name:      "redis_command_duration_seconds",
+	Help:      "Duration of redis commands by client, command and status.",
+	Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+}, []string{"client", "command", "status"})
+
+func init() {
+	Registry.MustRegister(SlowRequests, Connections, BreakerState, BreakerRejected, ClientRequests, ClientDuration,
+		UpgradeFailures, UpgradeAttempts, UpgradeSuccesses, LastUpgradeTimestamp, DrainDuration, ForceClosedRequests,
+		GraphQLOperations, WorkerJobs, WorkerQueueDepth, CronRuns, CronDuration, CronLastSuccess,
+		ConsumerMessages, ConsumerDuration, WebhookDeliveries, WebhookDuration, RedisCommands)
+}
+
+// Handler 返回暴露 Registry 中指标的 HTTP 处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidCredentials 用户名或密码错误
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// CredentialProvider 校验用户名密码并返回调用方身份
+type CredentialProvider interface {
+	Authenticate(ctx context.Context, username, password string) (principal any, err error)
+}
+
+// CredentialProviderFunc 函数形式的 CredentialProvider
+type CredentialProviderFunc func(ctx context.Context, username, password string) (any, error)
+
+func (f CredentialProviderFunc) Authenticate(ctx context.Context, username, password string) (any, error) {
+	return f(ctx, username, password)
+}
+
+// dummyHash 用户不存在时参与比较的哈希，使响应时间与用户存在时一致
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("ginx-dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// BcryptCredentials 基于 用户名 -> bcrypt 哈希 映射的凭证校验器，身份为用户名
+func BcryptCredentials(users map[string]string) CredentialProvider {
+	return CredentialProviderFunc(func(_ context.Context, username, password string) (any, error) {
+		hash, ok := users[username]
+		if !ok {
+			_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+			return nil, ErrInvalidCredentials
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+			return nil, ErrInvalidCredentials
+		}
+		return username, nil
+	})
+}
+
+// BasicAuthConfig Basic 认证配置
+type BasicAuthConfig struct {
+	// Realm 认证域，默认 "Restricted"
+	Realm    string
+	Provider CredentialProvider
+	// Logger 用于记录认证失败的审计日志，为 nil 时不记录
+	Logger *zap.Logger
+}
+
+// BasicAuth 返回一个 Basic 认证中间件，认证通过后将调用方身份写入 PrincipalKey
+func BasicAuth(conf BasicAuthConfig) gin.HandlerFunc {
+	if conf.Provider == nil {
+		panic("middleware: BasicAuth requires a Provider")
+	}
+	realm := conf.Realm
+	if realm == "" {
+		realm = "Restricted"
+	}
+	challenge := "Basic realm=" + strconv.Quote(realm)
+
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			c.Header("WWW-Authenticate", challenge)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		principal, err := conf.Provider.Authenticate(c.Request.Context(), username, password)
+		if err != nil {
+			if conf.Logger != nil {
+				conf.Logger.Warn("Basic auth failed",
+					zap.String("realm", realm),
+					zap.String("username", username),
+					zap.String("ip", ClientIP(c)),
+					zap.String("path", c.Request.URL.Path),
+					zap.Error(err),
+				)
+			}
+			c.Header("WWW-Authenticate", challenge)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Set(PrincipalKey, principal)
+		c.Next()
+	}
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/redact"
+)
+
+// BodyCaptureConfig 请求/响应体捕获配置，仅用于排查问题，不建议在生产环境常开
+type BodyCaptureConfig struct {
+	// Enabled 为 true 时捕获所有请求
+	Enabled bool
+	// Header 请求携带该头（值非空）时捕获当前请求，为空时不支持按请求开启
+	Header string
+	// MaxSize 每个 body 最多记录的字节数，默认 4096
+	MaxSize int
+	// ContentTypes 允许记录的内容类型，支持 "text/*" 前缀匹配，默认 JSON、表单与文本
+	ContentTypes []string
+	// RedactFields 需要脱敏的 JSON/表单字段名（大小写不敏感），默认 redact.DefaultFields
+	RedactFields []string
+	// Redactor 自定义脱敏器，设置后忽略 RedactFields
+	Redactor *redact.Redactor
+}
+
+var defaultCaptureContentTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/x-www-form-urlencoded",
+	"text/*",
+}
+
+// BodyCapture 返回一个记录请求与响应体的中间件
+func BodyCapture(logger *zap.Logger, conf BodyCaptureConfig) gin.HandlerFunc {
+	maxSize := conf.MaxSize
+	if maxSize <= 0 {
+		maxSize = 4096
+	}
+	contentTypes := conf.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = defaultCaptureContentTypes
+	}
+	redactor := conf.Redactor
+	if redactor == nil {
+		fields := conf.RedactFields
+		if len(fields) == 0 {
+			fields = redact.DefaultFields
+		}
+		redactor, _ = redact.New(fields, nil)
+	}
+
+	return func(c *gin.Context) {
+		if !conf.Enabled && (conf.Header == "" || c.GetHeader(conf.Header) == "") {
+			c.Next()
+			return
+		}
+
+		var reqBody []byte
+		var reqTruncated bool
+		reqType := c.GetHeader("Content-Type")
+		if c.Request.Body != nil && matchContentType(reqType, contentTypes) {
+			buf, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(maxSize)+1))
+			if err == nil {
+				c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(buf), c.Request.Body), c.Request.Body}
+				reqBody, reqTruncated = buf, len(buf) > maxSize
+				if reqTruncated {
+					reqBody = reqBody[:maxSize]
+				}
+			}
+		}
+
+		w := &captureWriter{ResponseWriter: c.Writer, max: maxSize}
+		c.Writer = w
+		c.Next()
+
+		respFields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", w.Status()),
+			zap.String("request_id", GetRequestID(c)),
+		}
+		if reqBody != nil {
+			respFields = append(respFields,
+				zap.String("request_body", redactor.Body(reqType, reqBody)),
+				zap.Bool("request_truncated", reqTruncated),
+			)
+		}
+		if respType := w.Header().Get("Content-Type"); w.buf.Len() > 0 && matchContentType(respType, contentTypes) {
+			respFields = append(respFields,
+				zap.String("response_body", redactor.Body(respType, w.buf.Bytes())),
+				zap.Bool("response_truncated", w.truncated),
+			)
+		}
+		logger.Info("Body capture", respFields...)
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// captureWriter 在写出响应的同时保留前 max 个字节
+type captureWriter struct {
+	gin.ResponseWriter
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *captureWriter) capture(b []byte) {
+	remain := w.max - w.buf.Len()
+	if len(b) > remain {
+		w.truncated = true
+		b = b[:max(remain, 0)]
+	}
+	w.buf.Write(b)
+}
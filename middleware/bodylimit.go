@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimitConfig 请求体大小限制配置
+type BodyLimitConfig struct {
+	// Limit 默认最大字节数，为 0 时不限制
+	Limit int64
+	// Routes 按路由模式（c.FullPath()）覆盖限制，如 "/upload": 32 << 20
+	Routes map[string]int64
+}
+
+// BodyLimit 返回一个请求体大小限制中间件。Content-Length 超出限制时直接返回 413，
+// 未声明长度的请求在读取超出限制时返回错误
+func BodyLimit(conf BodyLimitConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := conf.Limit
+		if override, ok := conf.Routes[c.FullPath()]; ok {
+			limit = override
+		}
+		if limit <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
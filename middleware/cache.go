@@ -0,0 +1,314 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 默认缓存配置
+const (
+	DefaultCacheTTL     = time.Minute
+	DefaultCacheMaxBody = 1 << 20
+)
+
+// CachedResponse 缓存的响应。Status 为 0 的条目是响应 Vary 头的索引，
+// 实际响应按 Vary 列出的请求头存放在各自的变体键下
+type CachedResponse struct {
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+	// Vary 响应声明的 Vary 请求头
+	Vary []string `json:"vary,omitempty"`
+	// Public 响应显式声明了 public 或 s-maxage，可用于携带凭证的请求
+	Public bool `json:"public,omitempty"`
+}
+
+// CacheStore 响应缓存存储
+type CacheStore interface {
+	// Get 读取缓存，未命中时返回 nil
+	Get(ctx context.Context, key string) (*CachedResponse, error)
+	Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration) error
+	// DeletePrefix 删除所有以 prefix 开头的缓存，prefix 为空时清空
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// CacheConfig 响应缓存配置
+type CacheConfig struct {
+	// Store 缓存存储，默认 64MB 的进程内 LRU
+	Store CacheStore
+	// TTL 响应未声明 max-age 时的缓存时间，默认 1 分钟
+	TTL time.Duration
+	// VaryHeaders 参与缓存键计算的请求头，如 Accept、Accept-Language
+	VaryHeaders []string
+	// MaxBodySize 超过该大小的响应不缓存，默认 1MB
+	MaxBodySize int
+}
+
+// Cache GET 响应缓存
+type Cache struct {
+	conf CacheConfig
+}
+
+// NewCache 创建响应缓存
+func NewCache(conf CacheConfig) *Cache {
+	if conf.Store == nil {
+		conf.Store = NewLRUCacheStore(64 << 20)
+	}
+	if conf.TTL <= 0 {
+		conf.TTL = DefaultCacheTTL
+	}
+	if conf.MaxBodySize <= 0 {
+		conf.MaxBodySize = DefaultCacheMaxBody
+	}
+	return &Cache{conf: conf}
+}
+
+// skipCacheHeaders 不随缓存保存与回放的响应头
+var skipCacheHeaders = []string{"Set-Cookie", "Date", "Age", "X-Cache", RequestIDHeader}
+
+// Middleware 返回缓存中间件。仅缓存 GET 请求的 200 响应，HEAD 请求复用 GET 的缓存；
+// 遵循请求的 no-store、no-cache 指令与响应的 no-store、no-cache、private、max-age、s-maxage 指令，
+// 响应的 Vary 头参与缓存键计算，设置了 Cookie 的响应不会被缓存。
+// 携带 Authorization 或 Cookie 的请求只读写显式声明 public 或 s-maxage 的响应（RFC 9111 §3.5）
+func (ca *Cache) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+		reqDirectives := cacheDirectives(c.GetHeader("Cache-Control"))
+		if _, ok := reqDirectives["no-store"]; ok {
+			c.Next()
+			return
+		}
+
+		key := ca.key(c.Request)
+		ctx := c.Request.Context()
+		credentialed := c.GetHeader("Authorization") != "" || c.GetHeader("Cookie") != ""
+		if _, ok := reqDirectives["no-cache"]; !ok {
+			if resp := ca.lookup(ctx, key, c.Request); resp != nil && (resp.Public || !credentialed) {
+				ca.replay(c, resp)
+				return
+			}
+		}
+
+		if c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		c.Header("X-Cache", "MISS")
+		w := &cacheWriter{ResponseWriter: c.Writer, maxSize: ca.conf.MaxBodySize}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.Status() != http.StatusOK || w.overflow || len(c.Errors) > 0 {
+			return
+		}
+		header := w.Header()
+		if header.Get("Set-Cookie") != "" {
+			return
+		}
+		ttl, public, ok := responseTTL(header.Get("Cache-Control"), ca.conf.TTL)
+		if !ok || (credentialed && !public) {
+			return
+		}
+		vary, ok := varyHeaders(header)
+		if !ok {
+			return
+		}
+
+		resp := &CachedResponse{
+			Status:   w.Status(),
+			Header:   make(http.Header, len(header)),
+			Body:     w.buf.Bytes(),
+			StoredAt: time.Now(),
+			Vary:     vary,
+			Public:   public,
+		}
+		for k, v := range header {
+			if !slices.Contains(skipCacheHeaders, k) {
+				resp.Header[k] = slices.Clone(v)
+			}
+		}
+		ctx = context.WithoutCancel(ctx)
+		if len(vary) > 0 {
+			index := &CachedResponse{Vary: vary, StoredAt: resp.StoredAt}
+			if err := ca.conf.Store.Set(ctx, key, index, ttl); err != nil {
+				return
+			}
+			key = variantKey(key, vary, c.Request)
+		}
+		_ = ca.conf.Store.Set(ctx, key, resp, ttl)
+	}
+}
+
+// lookup 读取缓存，遇到 Vary 索引时按请求头再读取对应的变体
+func (ca *Cache) lookup(ctx context.Context, key string, r *http.Request) *CachedResponse {
+	resp, err := ca.conf.Store.Get(ctx, key)
+	if err != nil || resp == nil {
+		return nil
+	}
+	if resp.Status != 0 {
+		return resp
+	}
+	resp, err = ca.conf.Store.Get(ctx, variantKey(key, resp.Vary, r))
+	if err != nil || resp == nil || resp.Status == 0 {
+		return nil
+	}
+	return resp
+}
+
+func (ca *Cache) replay(c *gin.Context, resp *CachedResponse) {
+	h := c.Writer.Header()
+	for k, v := range resp.Header {
+		h[k] = v
+	}
+	h.Set("X-Cache", "HIT")
+	h.Set("Age", strconv.Itoa(int(time.Since(resp.StoredAt).Seconds())))
+
+	c.Status(resp.Status)
+	if c.Request.Method == http.MethodHead {
+		c.Writer.WriteHeaderNow()
+	} else {
+		_, _ = c.Writer.Write(resp.Body)
+	}
+	c.Abort()
+}
+
+// Invalidate 删除指定路径的所有缓存（不同查询参数与请求头的变体）
+func (ca *Cache) Invalidate(ctx context.Context, paths ...string) error {
+	for _, p := range paths {
+		if err := ca.conf.Store.DeletePrefix(ctx, p+"?"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InvalidatePrefix 删除路径以 prefix 开头的所有缓存，prefix 为空时清空缓存
+func (ca *Cache) InvalidatePrefix(ctx context.Context, prefix string) error {
+	return ca.conf.Store.DeletePrefix(ctx, prefix)
+}
+
+// key 由路径、排序后的查询参数与 VaryHeaders 组成，路径在最前以支持按前缀失效
+func (ca *Cache) key(r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(r.URL.Path)
+	b.WriteByte('?')
+	b.WriteString(r.URL.Query().Encode())
+	for _, name := range ca.conf.VaryHeaders {
+		b.WriteByte('#')
+		b.WriteString(url.QueryEscape(r.Header.Get(name)))
+	}
+	return b.String()
+}
+
+// variantKey 在缓存键后追加响应 Vary 列出的请求头取值
+func variantKey(key string, vary []string, r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(key)
+	for _, name := range vary {
+		b.WriteByte('#')
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(strings.Join(r.Header.Values(name), ",")))
+	}
+	return b.String()
+}
+
+// varyHeaders 返回响应 Vary 头中排序去重后的请求头名称，Vary 为 * 时不可缓存
+func varyHeaders(header http.Header) ([]string, bool) {
+	var names []string
+	for _, v := range header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name == "*" {
+				return nil, false
+			}
+			names = append(names, http.CanonicalHeaderKey(name))
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names), true
+}
+
+// cacheDirectives 解析 Cache-Control 指令
+func cacheDirectives(header string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return directives
+}
+
+// responseTTL 按响应的 Cache-Control 计算缓存时间，public 表示响应显式声明了 public 或 s-maxage，
+// 不可缓存时 ok 为 false
+func responseTTL(header string, fallback time.Duration) (ttl time.Duration, public, ok bool) {
+	d := cacheDirectives(header)
+	for _, name := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := d[name]; ok {
+			return 0, false, false
+		}
+	}
+	_, public = d["public"]
+	if _, ok := d["s-maxage"]; ok {
+		public = true
+	}
+	for _, name := range []string{"s-maxage", "max-age"} {
+		if v, ok := d[name]; ok {
+			secs, err := strconv.Atoi(v)
+			if err != nil || secs <= 0 {
+				return 0, false, false
+			}
+			return time.Duration(secs) * time.Second, public, true
+		}
+	}
+	return fallback, public, true
+}
+
+// cacheWriter 在写出响应的同时保存一份副本
+type cacheWriter struct {
+	gin.ResponseWriter
+	buf      bytes.Buffer
+	maxSize  int
+	overflow bool
+}
+
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *cacheWriter) capture(b []byte) {
+	if w.overflow {
+		return
+	}
+	if w.buf.Len()+len(b) > w.maxSize {
+		w.overflow = true
+		w.buf = bytes.Buffer{}
+		return
+	}
+	w.buf.Write(b)
+}
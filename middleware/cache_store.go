@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LRUCacheStore 按总大小限制的进程内 LRU 缓存
+type LRUCacheStore struct {
+	mu       sync.Mutex
+	maxBytes int
+	size     int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	resp    *CachedResponse
+	size    int
+	expires time.Time
+}
+
+// NewLRUCacheStore 创建 LRU 缓存，maxBytes 为缓存的响应总大小上限
+func NewLRUCacheStore(maxBytes int) *LRUCacheStore {
+	return &LRUCacheStore{
+		maxBytes: maxBytes,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (s *LRUCacheStore) Get(_ context.Context, key string) (*CachedResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return nil, nil
+	}
+	entry := el.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		s.remove(el)
+		return nil, nil
+	}
+	s.ll.MoveToFront(el)
+	return entry.resp, nil
+}
+
+func (s *LRUCacheStore) Set(_ context.Context, key string, resp *CachedResponse, ttl time.Duration) error {
+	size := len(key) + len(resp.Body)
+	for k, v := range resp.Header {
+		size += len(k)
+		for _, s := range v {
+			size += len(s)
+		}
+	}
+	if size > s.maxBytes {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		s.remove(el)
+	}
+	entry := &lruEntry{key: key, resp: resp, size: size, expires: time.Now().Add(ttl)}
+	s.items[key] = s.ll.PushFront(entry)
+	s.size += size
+
+	for s.size > s.maxBytes {
+		s.remove(s.ll.Back())
+	}
+	return nil
+}
+
+func (s *LRUCacheStore) DeletePrefix(_ context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, el := range s.items {
+		if strings.HasPrefix(key, prefix) {
+			s.remove(el)
+		}
+	}
+	return nil
+}
+
+// Len 返回缓存条目数
+func (s *LRUCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
+
+func (s *LRUCacheStore) remove(el *list.Element) {
+	entry := s.ll.Remove(el).(*lruEntry)
+	delete(s.items, entry.key)
+	s.size -= entry.size
+}
+
+// RedisCacheStore 基于 Redis 的共享响应缓存
+type RedisCacheStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisCacheStore 创建 Redis 缓存存储，prefix 为空时使用 "ginx:cache:"；
+// 按前缀失效通过 SCAN 实现，在 Redis Cluster 中只会扫描单个节点
+func NewRedisCacheStore(client redis.Cmdable, prefix string) *RedisCacheStore {
+	if prefix == "" {
+		prefix = "ginx:cache:"
+	}
+	return &RedisCacheStore{client: client, prefix: prefix}
+}
+
+func (s *RedisCacheStore) Get(ctx context.Context, key string) (*CachedResponse, error) {
+	data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var resp CachedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode cached response: %w", err)
+	}
+	return &resp, nil
+}
+
+func (s *RedisCacheStore) Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to encode cached response: %w", err)
+	}
+	return s.client.Set(ctx, s.prefix+key, data, ttl).Err()
+}
+
+func (s *RedisCacheStore) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := s.client.Scan(ctx, 0, s.prefix+escapeGlob(prefix)+"*", 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == 100 {
+			if err := s.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan cache keys: %w", err)
+	}
+	if len(keys) > 0 {
+		return s.client.Del(ctx, keys...).Err()
+	}
+	return nil
+}
+
+// escapeGlob 转义 Redis 匹配模式中的特殊字符
+func escapeGlob(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(s)
+}
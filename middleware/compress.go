@@ -0,0 +1,371 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressConfig 响应压缩配置
+type CompressConfig struct {
+	// Level 压缩级别，为 0 时使用各算法的默认级别
+	Level int
+	// MinSize 响应体小于该字节数时不压缩，默认 1024
+	MinSize int
+	// ContentTypes 允许压缩的内容类型，支持 "text/*" 形式的前缀匹配，为空时使用默认列表
+	ContentTypes []string
+	// Encodings 启用的压缩算法，按服务端优先级排列，默认 br、zstd、gzip、deflate
+	Encodings []string
+}
+
+var defaultEncodings = []string{"br", "zstd", "gzip", "deflate"}
+
+var defaultCompressContentTypes = []string{
+	"text/*",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/problem+json",
+	"image/svg+xml",
+}
+
+// CompressWriter 压缩写入器，写入器会被池化复用
+type CompressWriter interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+// EncoderFactory 根据压缩级别创建写入器，level 为 0 时应使用算法的默认级别
+type EncoderFactory func(level int) CompressWriter
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]EncoderFactory{}
+)
+
+// RegisterEncoder 注册一种压缩算法，name 为 Accept-Encoding 中的编码名，重复注册会覆盖
+func RegisterEncoder(name string, factory EncoderFactory) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[strings.ToLower(name)] = factory
+}
+
+func init() {
+	RegisterEncoder("gzip", func(level int) CompressWriter {
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		w, err := gzip.NewWriterLevel(io.Discard, level)
+		if err != nil {
+			w = gzip.NewWriter(io.Discard)
+		}
+		return w
+	})
+	RegisterEncoder("deflate", func(level int) CompressWriter {
+		if level == 0 {
+			level = flate.DefaultCompression
+		}
+		w, err := flate.NewWriter(io.Discard, level)
+		if err != nil {
+			w, _ = flate.NewWriter(io.Discard, flate.DefaultCompression)
+		}
+		return w
+	})
+	RegisterEncoder("br", func(level int) CompressWriter {
+		if level <= 0 || level > brotli.BestCompression {
+			level = brotli.DefaultCompression
+		}
+		return brotli.NewWriterLevel(io.Discard, level)
+	})
+	RegisterEncoder("zstd", func(level int) CompressWriter {
+		zlevel := zstd.SpeedDefault
+		if level > 0 {
+			zlevel = zstd.EncoderLevelFromZstd(level)
+		}
+		w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderLevel(zlevel))
+		return w
+	})
+}
+
+// encoding 一种压缩算法及其写入器池
+type encoding struct {
+	name string
+	pool sync.Pool
+}
+
+func newEncodings(names []string, level int) []*encoding {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	result := make([]*encoding, 0, len(names))
+	for _, name := range names {
+		factory, ok := encoders[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		enc := &encoding{name: strings.ToLower(name)}
+		enc.pool.New = func() any {
+			return factory(level)
+		}
+		result = append(result, enc)
+	}
+	return result
+}
+
+// Compress 返回一个响应压缩中间件，根据 Accept-Encoding 在已注册的算法中协商
+func Compress(conf CompressConfig) gin.HandlerFunc {
+	minSize := conf.MinSize
+	if minSize <= 0 {
+		minSize = 1024
+	}
+	contentTypes := conf.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = defaultCompressContentTypes
+	}
+	names := conf.Encodings
+	if len(names) == 0 {
+		names = defaultEncodings
+	}
+	encodings := newEncodings(names, conf.Level)
+
+	return func(c *gin.Context) {
+		if isUpgrade(c.Request) || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		enc := negotiateEncoding(c.GetHeader("Accept-Encoding"), encodings)
+		if enc == nil {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       enc,
+			minSize:        minSize,
+			contentTypes:   contentTypes,
+			status:         http.StatusOK,
+		}
+		c.Writer = w
+		// 处理函数 panic 时丢弃缓冲的输出且不提交响应头，由外层 Recovery 写入 500；
+		// 不调用 recover，保留原始的 panic 调用栈
+		completed := false
+		defer func() {
+			c.Writer = w.ResponseWriter
+			if completed {
+				w.close()
+			} else {
+				w.abandon()
+			}
+		}()
+
+		c.Next()
+		completed = true
+	}
+}
+
+// negotiateEncoding 按 q 值选择客户端接受的压缩算法，q 值相同时按服务端顺序优先
+func negotiateEncoding(header string, encodings []*encoding) *encoding {
+	if header == "" {
+		return nil
+	}
+
+	var best *encoding
+	bestQ := 0.0
+	for _, enc := range encodings {
+		q := acceptQuality(header, enc.name)
+		if q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+// acceptQuality 返回 Accept-Encoding 中指定编码的 q 值
+func acceptQuality(header, name string) float64 {
+	wildcard := 0.0
+	for _, part := range strings.Split(header, ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(token)) {
+		case name:
+			return q
+		case "*":
+			wildcard = q
+		}
+	}
+	return wildcard
+}
+
+// matchContentType 判断内容类型是否在列表中，列表项支持 "text/*" 前缀匹配
+func matchContentType(contentType string, allowed []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range allowed {
+		if prefix, ok := strings.CutSuffix(t, "*"); ok {
+			if strings.HasPrefix(mediaType, prefix) {
+				return true
+			}
+		} else if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter 先缓冲响应直到达到 MinSize，再决定是否压缩
+type compressWriter struct {
+	gin.ResponseWriter
+
+	encoding     *encoding
+	minSize      int
+	contentTypes []string
+
+	status  int
+	buf     bytes.Buffer
+	encoder CompressWriter
+	started bool
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if !w.started {
+		w.status = code
+	}
+}
+
+func (w *compressWriter) WriteHeaderNow() {}
+
+func (w *compressWriter) Status() int {
+	if w.started {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *compressWriter) Written() bool {
+	return w.started || w.buf.Len() > 0
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.started {
+		if w.encoder != nil {
+			return w.encoder.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf.Write(b)
+	if w.buf.Len() >= w.minSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Flush() {
+	if !w.started {
+		_ = w.start(w.buf.Len() >= w.minSize)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// start 写出响应头并冲刷缓冲区，compress 为 false 或内容不可压缩时原样输出
+func (w *compressWriter) start(compress bool) error {
+	w.started = true
+	h := w.ResponseWriter.Header()
+
+	if h.Get("Content-Type") == "" && w.buf.Len() > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf.Bytes()))
+	}
+	if compress && h.Get("Content-Encoding") == "" && bodyAllowed(w.status) &&
+		matchContentType(h.Get("Content-Type"), w.contentTypes) {
+		h.Set("Content-Encoding", w.encoding.name)
+		h.Del("Content-Length")
+		w.encoder = w.encoding.pool.Get().(CompressWriter)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return nil
+	}
+
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+func (w *compressWriter) close() {
+	if !w.started {
+		_ = w.start(false)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+		w.encoder.Reset(io.Discard)
+		w.encoding.pool.Put(w.encoder)
+		w.encoder = nil
+	}
+}
+
+// abandon 丢弃尚未写出的内容并归还编码器，不再写出任何数据
+func (w *compressWriter) abandon() {
+	w.buf.Reset()
+	if w.encoder != nil {
+		w.encoder.Reset(io.Discard)
+		w.encoding.pool.Put(w.encoder)
+		w.encoder = nil
+	}
+}
+
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// isUpgrade 判断是否为协议升级请求（如 WebSocket 握手），Connection 按逗号分隔的令牌忽略大小写匹配
+func isUpgrade(r *http.Request) bool {
+	if r.Header.Get("Upgrade") != "" {
+		return true
+	}
+	for _, v := range r.Header.Values("Connection") {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
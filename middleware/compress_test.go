@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestCompressGzip(t *testing.T) {
+	body := strings.Repeat("hello compress ", 200)
+	r := gin.New()
+	r.Use(Compress(CompressConfig{}))
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, body) })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != body {
+		t.Fatalf("decompressed body mismatch")
+	}
+}
+
+func TestCompressSkipsUpgrade(t *testing.T) {
+	r := gin.New()
+	r.Use(Compress(CompressConfig{MinSize: 1}))
+	r.GET("/ws", func(c *gin.Context) { c.String(http.StatusOK, strings.Repeat("x", 100)) })
+
+	for _, conn := range []string{"Upgrade", "keep-alive, Upgrade", "upgrade"} {
+		req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("Connection", conn)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if got := w.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("Connection %q: Content-Encoding = %q, want none", conn, got)
+		}
+	}
+}
+
+func TestCompressPanicReturns500(t *testing.T) {
+	var hooked bool
+	r := gin.New()
+	r.Use(RecoveryWithConfig(zap.NewNop(), RecoveryConfig{
+		Hooks: []PanicHook{func(*gin.Context, any, Frames) { hooked = true }},
+	}))
+	r.Use(Compress(CompressConfig{}))
+	r.GET("/", func(c *gin.Context) {
+		// 未达到 MinSize，内容仍在缓冲区中
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+	if !hooked {
+		t.Fatal("panic hook not called")
+	}
+	if strings.Contains(w.Body.String(), "partial") {
+		t.Fatalf("buffered output leaked into error response: %q", w.Body.String())
+	}
+	if got := w.Header().Get("Content-Encoding"); got != "" {
+		t.Fatalf("Content-Encoding = %q, want none", got)
+	}
+}
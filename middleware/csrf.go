@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSRFTokenKey 当前请求的 CSRF token 在 gin.Context 中的键
+const CSRFTokenKey = "csrf_token"
+
+// CSRFTokenStore 同步器令牌模式下 token 的存储，通常基于服务端会话实现
+type CSRFTokenStore interface {
+	// Token 返回已保存的 token，不存在时返回空字符串
+	Token(c *gin.Context) (string, error)
+	SetToken(c *gin.Context, token string) error
+}
+
+// CSRFConfig CSRF 防护配置
+type CSRFConfig struct {
+	// Store 设置后使用同步器令牌模式，否则使用双重提交 cookie 模式
+	Store CSRFTokenStore
+	// CookieName 双重提交模式下的 cookie 名称，默认 csrf_token
+	CookieName string
+	Secure     bool
+	// Header 提交 token 的请求头，默认 X-CSRF-Token
+	Header string
+	// FormField 提交 token 的表单字段，默认 csrf_token
+	FormField string
+	// Skip 返回 true 时跳过校验，默认只跳过携带 Bearer token 的 API 请求；
+	// Basic/Digest 凭证会由浏览器自动附带，不能作为跳过的依据
+	Skip func(c *gin.Context) bool
+}
+
+// CSRF 返回一个 CSRF 防护中间件，安全方法（GET/HEAD/OPTIONS/TRACE）不校验，校验失败返回 403
+func CSRF(conf CSRFConfig) gin.HandlerFunc {
+	if conf.CookieName == "" {
+		conf.CookieName = "csrf_token"
+	}
+	if conf.Header == "" {
+		conf.Header = "X-CSRF-Token"
+	}
+	if conf.FormField == "" {
+		conf.FormField = "csrf_token"
+	}
+	if conf.Skip == nil {
+		conf.Skip = func(c *gin.Context) bool {
+			return bearerToken(c) != ""
+		}
+	}
+
+	return func(c *gin.Context) {
+		if conf.Skip(c) {
+			c.Next()
+			return
+		}
+
+		token, err := loadCSRFToken(c, conf)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		if token == "" {
+			token = newCSRFToken()
+			if err := saveCSRFToken(c, conf, token); err != nil {
+				c.AbortWithError(http.StatusInternalServerError, err)
+				return
+			}
+		}
+		c.Set(CSRFTokenKey, token)
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			c.Next()
+			return
+		}
+
+		submitted := c.GetHeader(conf.Header)
+		if submitted == "" {
+			submitted = c.PostForm(conf.FormField)
+		}
+		if submitted == "" || subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+
+// CSRFToken 返回当前请求的 CSRF token，用于写入页面或响应
+func CSRFToken(c *gin.Context) string {
+	return c.GetString(CSRFTokenKey)
+}
+
+// CSRFField 返回包含 CSRF token 的隐藏表单字段，可直接在 HTML 模板中输出
+func CSRFField(c *gin.Context) template.HTML {
+	return template.HTML(`<input type="hidden" name="csrf_token" value="` +
+		template.HTMLEscapeString(CSRFToken(c)) + `">`)
+}
+
+func loadCSRFToken(c *gin.Context, conf CSRFConfig) (string, error) {
+	if conf.Store != nil {
+		return conf.Store.Token(c)
+	}
+	token, err := c.Cookie(conf.CookieName)
+	if err != nil {
+		return "", nil
+	}
+	return token, nil
+}
+
+func saveCSRFToken(c *gin.Context, conf CSRFConfig, token string) error {
+	if conf.Store != nil {
+		return conf.Store.SetToken(c, token)
+	}
+	// 双重提交模式下 cookie 需要能被前端脚本读取，因此不设置 HttpOnly
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     conf.CookieName,
+		Value:    token,
+		Path:     "/",
+		Secure:   conf.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+func newCSRFToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSRFSkipRules(t *testing.T) {
+	r := gin.New()
+	r.Use(CSRF(CSRFConfig{}))
+	r.POST("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   int
+	}{
+		{"no token", nil, http.StatusForbidden},
+		{"basic auth is not skipped", map[string]string{"Authorization": "Basic dXNlcjpwYXNz"}, http.StatusForbidden},
+		{"digest auth is not skipped", map[string]string{"Authorization": `Digest username="user"`}, http.StatusForbidden},
+		{"bearer token is skipped", map[string]string{"Authorization": "Bearer abc"}, http.StatusNoContent},
+		{"mismatched token", map[string]string{"Cookie": "csrf_token=a", "X-CSRF-Token": "b"}, http.StatusForbidden},
+		{"matching token", map[string]string{"Cookie": "csrf_token=a", "X-CSRF-Token": "a"}, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSRFSafeMethodIssuesToken(t *testing.T) {
+	r := gin.New()
+	r.Use(CSRF(CSRFConfig{}))
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, CSRFToken(c)) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK || w.Body.Len() == 0 {
+		t.Fatalf("GET = %d %q, want 200 with token", w.Code, w.Body.String())
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != w.Body.String() {
+		t.Fatalf("cookie = %+v, want token %q", cookies, w.Body.String())
+	}
+}
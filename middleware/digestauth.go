@@ -0,0 +1,308 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Digest 认证支持的算法
+const (
+	DigestSHA256 = "SHA-256"
+	DigestMD5    = "MD5"
+)
+
+// DefaultDigestNonceTTL 默认的 nonce 有效期
+const DefaultDigestNonceTTL = 5 * time.Minute
+
+// DigestCredentialProvider 为 Digest 认证提供用户的 HA1，即 H(username:realm:password)。
+// Digest 需要服务端能计算该摘要，因此无法使用 bcrypt 哈希保存的密码
+type DigestCredentialProvider interface {
+	HA1(ctx context.Context, username, realm, algorithm string) (ha1 string, principal any, err error)
+}
+
+// DigestCredentialProviderFunc 函数形式的 DigestCredentialProvider
+type DigestCredentialProviderFunc func(ctx context.Context, username, realm, algorithm string) (string, any, error)
+
+func (f DigestCredentialProviderFunc) HA1(ctx context.Context, username, realm, algorithm string) (string, any, error) {
+	return f(ctx, username, realm, algorithm)
+}
+
+// DigestPasswords 基于 用户名 -> 明文密码 映射的凭证提供者，身份为用户名
+func DigestPasswords(users map[string]string) DigestCredentialProvider {
+	return DigestCredentialProviderFunc(func(_ context.Context, username, realm, algorithm string) (string, any, error) {
+		password, ok := users[username]
+		if !ok {
+			return "", nil, ErrInvalidCredentials
+		}
+		return digestHash(algorithm, username+":"+realm+":"+password), username, nil
+	})
+}
+
+// DigestAuthConfig Digest 认证配置
+type DigestAuthConfig struct {
+	// Realm 认证域，默认 "Restricted"
+	Realm    string
+	Provider DigestCredentialProvider
+	// Algorithms 按优先级依次发出的质询算法，默认 SHA-256 与 MD5
+	Algorithms []string
+	// NonceTTL nonce 有效期，过期后客户端会以 stale=true 的质询透明地重试，默认 5 分钟
+	NonceTTL time.Duration
+	// Secret 签名 nonce 的密钥，多实例部署时需保持一致，默认随机生成
+	Secret []byte
+	// Logger 用于记录认证失败的审计日志，为 nil 时不记录
+	Logger *zap.Logger
+}
+
+// DigestAuth 返回一个 Digest 认证中间件（RFC 7616，qop=auth），认证通过后将调用方身份写入 PrincipalKey。
+// nonce 由服务端签名无需存储，nonce 计数在进程内校验以防止重放
+func DigestAuth(conf DigestAuthConfig) gin.HandlerFunc {
+	if conf.Provider == nil {
+		panic("middleware: DigestAuth requires a Provider")
+	}
+	if conf.Realm == "" {
+		conf.Realm = "Restricted"
+	}
+	if len(conf.Algorithms) == 0 {
+		conf.Algorithms = []string{DigestSHA256, DigestMD5}
+	}
+	for _, alg := range conf.Algorithms {
+		if alg != DigestSHA256 && alg != DigestMD5 {
+			panic("middleware: unsupported digest algorithm " + alg)
+		}
+	}
+	if conf.NonceTTL <= 0 {
+		conf.NonceTTL = DefaultDigestNonceTTL
+	}
+	if len(conf.Secret) == 0 {
+		conf.Secret = make([]byte, 32)
+		_, _ = rand.Read(conf.Secret)
+	}
+	d := &digestAuth{conf: conf, counts: make(map[string]*nonceCount), lastSweep: time.Now()}
+
+	return func(c *gin.Context) {
+		params, ok := parseDigestAuthorization(c.GetHeader("Authorization"))
+		if !ok {
+			d.challenge(c, false)
+			return
+		}
+
+		principal, stale, err := d.verify(c, params)
+		if err != nil {
+			if conf.Logger != nil && !stale {
+				conf.Logger.Warn("Digest auth failed",
+					zap.String("realm", conf.Realm),
+					zap.String("username", params["username"]),
+					zap.String("ip", ClientIP(c)),
+					zap.String("path", c.Request.URL.Path),
+					zap.Error(err),
+				)
+			}
+			d.challenge(c, stale)
+			return
+		}
+
+		c.Set(PrincipalKey, principal)
+		c.Next()
+	}
+}
+
+type digestAuth struct {
+	conf DigestAuthConfig
+
+	mu        sync.Mutex
+	counts    map[string]*nonceCount
+	lastSweep time.Time
+}
+
+// nonceCount 记录每个 nonce 已使用的最大计数
+type nonceCount struct {
+	nc      uint64
+	expires time.Time
+}
+
+func (d *digestAuth) challenge(c *gin.Context, stale bool) {
+	nonce := d.newNonce()
+	for _, alg := range d.conf.Algorithms {
+		v := "Digest realm=" + strconv.Quote(d.conf.Realm) +
+			`, qop="auth", algorithm=` + alg +
+			", nonce=" + strconv.Quote(nonce)
+		if stale {
+			v += ", stale=true"
+		}
+		c.Writer.Header().Add("WWW-Authenticate", v)
+	}
+	c.AbortWithStatus(http.StatusUnauthorized)
+}
+
+// verify 校验 Digest 响应，stale 为 true 表示仅 nonce 过期，客户端可直接用新 nonce 重试
+func (d *digestAuth) verify(c *gin.Context, p map[string]string) (principal any, stale bool, err error) {
+	alg := p["algorithm"]
+	if alg == "" {
+		alg = DigestMD5
+	}
+	if !d.algorithmAllowed(alg) || p["qop"] != "auth" || p["realm"] != d.conf.Realm ||
+		p["username"] == "" || p["cnonce"] == "" || p["userhash"] == "true" {
+		return nil, false, ErrInvalidCredentials
+	}
+	// uri 必须指向当前请求，防止响应被用于其它资源
+	if p["uri"] != c.Request.RequestURI {
+		return nil, false, ErrInvalidCredentials
+	}
+	nc, err := strconv.ParseUint(p["nc"], 16, 64)
+	if err != nil || nc == 0 {
+		return nil, false, ErrInvalidCredentials
+	}
+	issued, ok := d.checkNonce(p["nonce"])
+	if !ok {
+		return nil, false, ErrInvalidCredentials
+	}
+	if time.Since(issued) > d.conf.NonceTTL {
+		return nil, true, ErrInvalidCredentials
+	}
+
+	ha1, principal, err := d.conf.Provider.HA1(c.Request.Context(), p["username"], d.conf.Realm, alg)
+	if err != nil {
+		return nil, false, err
+	}
+	ha2 := digestHash(alg, c.Request.Method+":"+p["uri"])
+	expected := digestHash(alg, ha1+":"+p["nonce"]+":"+p["nc"]+":"+p["cnonce"]+":auth:"+ha2)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(p["response"]))) != 1 {
+		return nil, false, ErrInvalidCredentials
+	}
+	if !d.useCount(p["nonce"], nc, issued.Add(d.conf.NonceTTL)) {
+		return nil, false, ErrInvalidCredentials
+	}
+	return principal, false, nil
+}
+
+func (d *digestAuth) algorithmAllowed(alg string) bool {
+	for _, a := range d.conf.Algorithms {
+		if strings.EqualFold(a, alg) {
+			return true
+		}
+	}
+	return false
+}
+
+// newNonce 生成 时间戳 | 随机数 | HMAC 组成的无状态 nonce
+func (d *digestAuth) newNonce() string {
+	b := make([]byte, 16, 16+sha256.Size)
+	binary.BigEndian.PutUint64(b, uint64(time.Now().UnixNano()))
+	_, _ = rand.Read(b[8:16])
+	mac := hmac.New(sha256.New, d.conf.Secret)
+	mac.Write(b)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(b))
+}
+
+// checkNonce 校验 nonce 签名并返回签发时间
+func (d *digestAuth) checkNonce(nonce string) (time.Time, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(nonce)
+	if err != nil || len(b) != 16+sha256.Size {
+		return time.Time{}, false
+	}
+	mac := hmac.New(sha256.New, d.conf.Secret)
+	mac.Write(b[:16])
+	if !hmac.Equal(mac.Sum(nil), b[16:]) {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(b))), true
+}
+
+// useCount 要求同一 nonce 的计数严格递增，过期的记录在访问时被惰性清理
+func (d *digestAuth) useCount(nonce string, nc uint64, expires time.Time) bool {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if now.Sub(d.lastSweep) > d.conf.NonceTTL {
+		for k, v := range d.counts {
+			if now.After(v.expires) {
+				delete(d.counts, k)
+			}
+		}
+		d.lastSweep = now
+	}
+
+	if e, ok := d.counts[nonce]; ok {
+		if nc <= e.nc {
+			return false
+		}
+		e.nc = nc
+		return true
+	}
+	d.counts[nonce] = &nonceCount{nc: nc, expires: expires}
+	return true
+}
+
+func digestHash(algorithm, s string) string {
+	var h hash.Hash
+	if strings.EqualFold(algorithm, DigestMD5) {
+		h = md5.New()
+	} else {
+		h = sha256.New()
+	}
+	h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// parseDigestAuthorization 解析 "Digest k=v, k="v"" 形式的 Authorization 头，引号内允许逗号与转义
+func parseDigestAuthorization(header string) (map[string]string, bool) {
+	scheme, rest, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Digest") {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for {
+		rest = strings.TrimLeft(rest, " \t,")
+		if rest == "" {
+			break
+		}
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return nil, false
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = strings.TrimLeft(rest[eq+1:], " \t")
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				b.WriteByte(rest[i])
+			}
+			if i >= len(rest) {
+				return nil, false
+			}
+			value, rest = b.String(), rest[i+1:]
+		} else {
+			end := strings.IndexByte(rest, ',')
+			if end < 0 {
+				end = len(rest)
+			}
+			value, rest = strings.TrimSpace(rest[:end]), rest[end:]
+		}
+		params[key] = value
+	}
+	return params, true
+}
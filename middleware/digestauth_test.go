@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newDigestRouter(ttl time.Duration) *gin.Engine {
+	r := gin.New()
+	r.Use(DigestAuth(DigestAuthConfig{
+		Realm:    "test",
+		Provider: DigestPasswords(map[string]string{"alice": "secret"}),
+		NonceTTL: ttl,
+	}))
+	r.GET("/private", func(c *gin.Context) { c.String(http.StatusOK, fmt.Sprint(c.MustGet(PrincipalKey))) })
+	return r
+}
+
+// digestNonce 发起未认证请求并从首个质询中取出 nonce
+func digestNonce(t *testing.T, r http.Handler) (string, *httptest.ResponseRecorder) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/private", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("challenge status = %d, want 401", w.Code)
+	}
+	params, ok := parseDigestAuthorization(w.Header().Values("WWW-Authenticate")[0])
+	if !ok || params["nonce"] == "" {
+		t.Fatalf("invalid challenge %q", w.Header().Get("WWW-Authenticate"))
+	}
+	return params["nonce"], w
+}
+
+func digestRequest(r http.Handler, uri, password, nonce, nc string) *httptest.ResponseRecorder {
+	ha1 := digestHash(DigestSHA256, "alice:test:"+password)
+	ha2 := digestHash(DigestSHA256, http.MethodGet+":"+uri)
+	response := digestHash(DigestSHA256, ha1+":"+nonce+":"+nc+":cn, once:auth:"+ha2)
+
+	req := httptest.NewRequest(http.MethodGet, "/private", nil)
+	req.Header.Set("Authorization", fmt.Sprintf(
+		`Digest username="alice", realm="test", nonce="%s", uri="%s", algorithm=SHA-256, qop=auth, nc=%s, cnonce="cn, once", response="%s"`,
+		nonce, uri, nc, response))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestDigestAuth(t *testing.T) {
+	r := newDigestRouter(0)
+	nonce, challenge := digestNonce(t, r)
+	if got := challenge.Header().Values("WWW-Authenticate"); len(got) != 2 || !strings.Contains(got[0], "algorithm=SHA-256") {
+		t.Fatalf("challenges = %q, want SHA-256 then MD5", got)
+	}
+
+	w := digestRequest(r, "/private", "secret", nonce, "00000001")
+	if w.Code != http.StatusOK || w.Body.String() != "alice" {
+		t.Fatalf("valid = %d %q, want 200 alice", w.Code, w.Body.String())
+	}
+	if w := digestRequest(r, "/private", "secret", nonce, "00000001"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed nc status = %d, want 401", w.Code)
+	}
+	if w := digestRequest(r, "/private", "secret", nonce, "00000002"); w.Code != http.StatusOK {
+		t.Fatalf("next nc status = %d, want 200", w.Code)
+	}
+	if w := digestRequest(r, "/private", "wrong", nonce, "00000003"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password status = %d, want 401", w.Code)
+	}
+	if w := digestRequest(r, "/other", "secret", nonce, "00000004"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("mismatched uri status = %d, want 401", w.Code)
+	}
+
+	// 其它实例（不同密钥）签发的 nonce 无效
+	other, _ := digestNonce(t, newDigestRouter(0))
+	if w := digestRequest(r, "/private", "secret", other, "00000001"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("forged nonce status = %d, want 401", w.Code)
+	}
+}
+
+func TestDigestAuthStaleNonce(t *testing.T) {
+	r := newDigestRouter(10 * time.Millisecond)
+	nonce, _ := digestNonce(t, r)
+	time.Sleep(20 * time.Millisecond)
+
+	w := digestRequest(r, "/private", "secret", nonce, "00000001")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expired nonce status = %d, want 401", w.Code)
+	}
+	if !strings.Contains(w.Header().Get("WWW-Authenticate"), "stale=true") {
+		t.Fatalf("challenge = %q, want stale=true", w.Header().Get("WWW-Authenticate"))
+	}
+}
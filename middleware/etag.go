@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultETagMaxSize 默认参与计算 ETag 的最大响应大小
+const DefaultETagMaxSize = 1 << 20
+
+// ETagConfig ETag 中间件配置
+type ETagConfig struct {
+	// Weak 为 true 时生成弱 ETag（W/"..."），适用于响应内容语义相同但字节可能不同的场景，如会被压缩的响应
+	Weak bool
+	// MaxSize 超过该大小的响应不再缓冲，也不生成 ETag，默认 1MB
+	MaxSize int
+}
+
+// ETag 返回条件请求中间件，缓冲 GET/HEAD 的 200 响应并计算 ETag，处理函数已设置 ETag 时直接使用，
+// 请求携带的 If-None-Match 或 If-Modified-Since 命中时返回 304。可在路由组上分别使用以实现按组配置
+func ETag(conf ETagConfig) gin.HandlerFunc {
+	if conf.MaxSize <= 0 {
+		conf.MaxSize = DefaultETagMaxSize
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		w := &etagWriter{ResponseWriter: c.Writer, status: http.StatusOK, maxSize: conf.MaxSize}
+		c.Writer = w
+		defer func() {
+			c.Writer = w.ResponseWriter
+		}()
+
+		c.Next()
+
+		if w.passthrough {
+			return
+		}
+		if !w.written {
+			// 未写出任何内容时交由外层（如错误处理中间件）决定响应
+			if w.status != http.StatusOK {
+				w.ResponseWriter.WriteHeader(w.status)
+			}
+			return
+		}
+		if w.status != http.StatusOK || len(c.Errors) > 0 {
+			w.flush()
+			return
+		}
+
+		h := w.Header()
+		etag := h.Get("ETag")
+		if etag == "" {
+			etag = computeETag(w.buf.Bytes(), conf.Weak)
+			h.Set("ETag", etag)
+		}
+
+		if notModified(c.Request, etag, h.Get("Last-Modified")) {
+			for _, k := range []string{"Content-Type", "Content-Length", "Content-Encoding"} {
+				h.Del(k)
+			}
+			w.ResponseWriter.WriteHeader(http.StatusNotModified)
+			w.ResponseWriter.WriteHeaderNow()
+			return
+		}
+		w.flush()
+	}
+}
+
+func computeETag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if weak {
+		return "W/" + etag
+	}
+	return etag
+}
+
+// notModified 按 RFC 9110 判断条件请求，If-None-Match 存在时忽略 If-Modified-Since
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weakMatch(candidate, etag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// weakMatch 弱比较，忽略 W/ 前缀
+func weakMatch(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
+
+// etagWriter 缓冲响应体，超过 maxSize 或处理函数主动 Flush 时转为直接写出
+type etagWriter struct {
+	gin.ResponseWriter
+
+	buf         bytes.Buffer
+	status      int
+	maxSize     int
+	written     bool
+	passthrough bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+func (w *etagWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.written = true
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	w.written = true
+	if w.buf.Len()+len(b) > w.maxSize {
+		w.flush()
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *etagWriter) Status() int {
+	if w.passthrough {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *etagWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	if w.buf.Len() == 0 {
+		return -1
+	}
+	return w.buf.Len()
+}
+
+func (w *etagWriter) Written() bool {
+	return w.passthrough || w.written
+}
+
+func (w *etagWriter) Flush() {
+	w.flush()
+	w.ResponseWriter.Flush()
+}
+
+// flush 写出缓冲的状态码与响应体，之后的写入直接透传
+func (w *etagWriter) flush() {
+	if w.passthrough {
+		return
+	}
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	if w.buf.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+}
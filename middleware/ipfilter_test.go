@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIPFilterAllowed(t *testing.T) {
+	f, err := NewIPFilter(IPFilterConfig{
+		Allow: []string{"10.0.0.0/8", "2001:db8::/32"},
+		Deny:  []string{"10.0.0.13"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"10.1.2.3":        true,
+		"::ffff:10.1.2.3": true,
+		"2001:db8::1":     true,
+		"10.0.0.13":       false,
+		"192.168.1.1":     false,
+		"not-an-ip":       false,
+		"":                false,
+	}
+	for ip, want := range tests {
+		if got := f.Allowed(ip); got != want {
+			t.Errorf("Allowed(%q) = %v, want %v", ip, got, want)
+		}
+	}
+
+	if err := f.Update(nil, []string{"192.168.0.0/16"}); err != nil {
+		t.Fatal(err)
+	}
+	if !f.Allowed("10.0.0.13") || f.Allowed("192.168.1.1") {
+		t.Fatal("rules not replaced by Update")
+	}
+	if err := f.Update([]string{"bogus/33"}, nil); err == nil {
+		t.Fatal("invalid cidr accepted")
+	}
+	if f.Allowed("192.168.1.1") {
+		t.Fatal("failed Update replaced rules")
+	}
+}
+
+func TestIPFilterMiddlewareIgnoresForwardedFor(t *testing.T) {
+	f, err := NewIPFilter(IPFilterConfig{Allow: []string{"10.0.0.0/8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.Use(f.Middleware())
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.5:1000"
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("spoofed status = %d, want 403", w.Code)
+	}
+
+	req.RemoteAddr = "10.0.0.1:1000"
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("allowed status = %d, want 204", w.Code)
+	}
+}
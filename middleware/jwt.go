@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ClaimsKey 解析后的 JWT claims 在 gin.Context 中的键
+const ClaimsKey = "jwt_claims"
+
+// JWTConfig JWT 认证配置
+type JWTConfig struct {
+	// Key 验签密钥：HMAC 使用 []byte，RSA 使用 *rsa.PublicKey，ECDSA 使用 *ecdsa.PublicKey
+	Key any
+	// JWKSURL 远程 JWKS 地址，设置后按 token 头中的 kid 查找公钥
+	JWKSURL string
+	// JWKSRefresh JWKS 缓存时间，默认 1 小时
+	JWKSRefresh time.Duration
+	// Methods 允许的签名算法，默认根据 Key 类型推断
+	Methods  []string
+	Issuer   string
+	Audience string
+	// Leeway 校验 exp/nbf/iat 时允许的时钟偏差
+	Leeway time.Duration
+	// QueryParam 除 Authorization 头外，还从该查询参数读取 token（如 websocket 场景）
+	QueryParam string
+}
+
+// JWT 返回一个 JWT 认证中间件，校验通过后将 claims 写入 ClaimsKey，失败时返回 401
+func JWT(conf JWTConfig) gin.HandlerFunc {
+	if conf.Key == nil && conf.JWKSURL == "" {
+		panic("middleware: JWT requires Key or JWKSURL")
+	}
+
+	var jwks *jwksCache
+	if conf.JWKSURL != "" {
+		jwks = newJWKSCache(conf.JWKSURL, conf.JWKSRefresh)
+	}
+
+	opts := []jwt.ParserOption{jwt.WithExpirationRequired(), jwt.WithLeeway(conf.Leeway)}
+	if methods := conf.Methods; len(methods) > 0 {
+		opts = append(opts, jwt.WithValidMethods(methods))
+	} else {
+		opts = append(opts, jwt.WithValidMethods(defaultJWTMethods(conf.Key, jwks != nil)))
+	}
+	if conf.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(conf.Issuer))
+	}
+	if conf.Audience != "" {
+		opts = append(opts, jwt.WithAudience(conf.Audience))
+	}
+	parser := jwt.NewParser(opts...)
+
+	return func(c *gin.Context) {
+		raw := bearerToken(c)
+		if raw == "" && conf.QueryParam != "" {
+			raw = c.Query(conf.QueryParam)
+		}
+		if raw == "" {
+			abortUnauthorized(c, "missing token")
+			return
+		}
+
+		claims := jwt.MapClaims{}
+		_, err := parser.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+			if jwks != nil {
+				kid, _ := t.Header["kid"].(string)
+				return jwks.key(c.Request.Context(), kid)
+			}
+			return conf.Key, nil
+		})
+		if err != nil {
+			_ = c.Error(err)
+			abortUnauthorized(c, "invalid token")
+			return
+		}
+
+		c.Set(ClaimsKey, claims)
+		c.Next()
+	}
+}
+
+// GetClaims 获取当前请求解析后的 JWT claims
+func GetClaims(c *gin.Context) jwt.MapClaims {
+	if v, ok := c.Get(ClaimsKey); ok {
+		if claims, ok := v.(jwt.MapClaims); ok {
+			return claims
+		}
+	}
+	return nil
+}
+
+// RequireScopes 要求 token 包含全部指定的 scope（读取 "scope" 空格分隔字符串或 "scp" 数组），
+// 需挂载在 JWT 中间件之后，不满足时返回 403
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted := tokenScopes(GetClaims(c))
+		for _, s := range scopes {
+			if _, ok := granted[s]; !ok {
+				c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+strings.Join(scopes, " ")+`"`)
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+func tokenScopes(claims jwt.MapClaims) map[string]struct{} {
+	scopes := make(map[string]struct{})
+	if s, ok := claims["scope"].(string); ok {
+		for _, v := range strings.Fields(s) {
+			scopes[v] = struct{}{}
+		}
+	}
+	if list, ok := claims["scp"].([]any); ok {
+		for _, v := range list {
+			if s, ok := v.(string); ok {
+				scopes[s] = struct{}{}
+			}
+		}
+	}
+	return scopes
+}
+
+func defaultJWTMethods(key any, jwks bool) []string {
+	rsaMethods := []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}
+	ecMethods := []string{"ES256", "ES384", "ES512"}
+	if jwks {
+		return append(rsaMethods, ecMethods...)
+	}
+	switch key.(type) {
+	case []byte:
+		return []string{"HS256", "HS384", "HS512"}
+	case *rsa.PublicKey:
+		return rsaMethods
+	case *ecdsa.PublicKey:
+		return ecMethods
+	}
+	return nil
+}
+
+func bearerToken(c *gin.Context) string {
+	auth := c.GetHeader("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+func abortUnauthorized(c *gin.Context, desc string) {
+	c.Header("WWW-Authenticate", `Bearer error="invalid_token", error_description="`+desc+`"`)
+	c.AbortWithStatus(http.StatusUnauthorized)
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var hmacKey = []byte("test-secret")
+
+func signHS256(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(hmacKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func serveBearer(r http.Handler, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestJWT(t *testing.T) {
+	r := gin.New()
+	r.Use(JWT(JWTConfig{Key: hmacKey, Issuer: "ginx", Audience: "api"}))
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, GetClaims(c)["sub"].(string)) })
+
+	exp := time.Now().Add(time.Hour).Unix()
+	valid := jwt.MapClaims{"sub": "alice", "iss": "ginx", "aud": "api", "exp": exp}
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"valid", signHS256(t, valid), http.StatusOK},
+		{"missing", "", http.StatusUnauthorized},
+		{"malformed", "not.a.jwt", http.StatusUnauthorized},
+		{"alg none", none, http.StatusUnauthorized},
+		{"expired", signHS256(t, jwt.MapClaims{"sub": "alice", "iss": "ginx", "aud": "api", "exp": time.Now().Add(-time.Hour).Unix()}), http.StatusUnauthorized},
+		{"missing exp", signHS256(t, jwt.MapClaims{"sub": "alice", "iss": "ginx", "aud": "api"}), http.StatusUnauthorized},
+		{"wrong issuer", signHS256(t, jwt.MapClaims{"sub": "alice", "iss": "other", "aud": "api", "exp": exp}), http.StatusUnauthorized},
+		{"wrong audience", signHS256(t, jwt.MapClaims{"sub": "alice", "iss": "ginx", "aud": "web", "exp": exp}), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveBearer(r, tt.token)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && w.Body.String() != "alice" {
+				t.Fatalf("sub = %q, want alice", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireScopes(t *testing.T) {
+	r := gin.New()
+	r.Use(JWT(JWTConfig{Key: hmacKey}), RequireScopes("read", "write"))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	exp := time.Now().Add(time.Hour).Unix()
+	if w := serveBearer(r, signHS256(t, jwt.MapClaims{"scope": "read write admin", "exp": exp})); w.Code != http.StatusNoContent {
+		t.Fatalf("scope string status = %d, want 204", w.Code)
+	}
+	if w := serveBearer(r, signHS256(t, jwt.MapClaims{"scp": []string{"read", "write"}, "exp": exp})); w.Code != http.StatusNoContent {
+		t.Fatalf("scp array status = %d, want 204", w.Code)
+	}
+	w := serveBearer(r, signHS256(t, jwt.MapClaims{"scope": "read", "exp": exp}))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("insufficient scope status = %d, want 403", w.Code)
+	}
+	if w.Header().Get("WWW-Authenticate") == "" {
+		t.Fatal("missing WWW-Authenticate header")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "k1",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer srv.Close()
+
+	sign := func(kid string) string {
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()})
+		tok.Header["kid"] = kid
+		s, err := tok.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	r := gin.New()
+	r.Use(JWT(JWTConfig{JWKSURL: srv.URL}))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	if w := serveBearer(r, sign("k1")); w.Code != http.StatusNoContent {
+		t.Fatalf("valid status = %d, want 204", w.Code)
+	}
+	// HMAC 签名且以公钥作为密钥的算法混淆攻击
+	confused, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()}).
+		SignedString(key.N.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := serveBearer(r, confused); w.Code != http.StatusUnauthorized {
+		t.Fatalf("hmac token status = %d, want 401", w.Code)
+	}
+	// 未知 kid 在最小拉取间隔内不会触发重新拉取
+	for range 3 {
+		if w := serveBearer(r, sign("unknown")); w.Code != http.StatusUnauthorized {
+			t.Fatalf("unknown kid status = %d, want 401", w.Code)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("fetches = %d, want 1", n)
+	}
+}
+
+func TestJWKSCacheKeepsKeysOnFailure(t *testing.T) {
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"keys":[{"kid":"k1","kty":"EC","crv":"P-256",` +
+			`"x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU","y":"x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0"}]}`))
+	}))
+	defer srv.Close()
+
+	j := newJWKSCache(srv.URL, time.Hour)
+	ctx := context.Background()
+	if _, err := j.key(ctx, "k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 缓存过期且超过最小拉取间隔后拉取失败，继续使用旧公钥
+	failing.Store(true)
+	j.mu.Lock()
+	j.fetched = time.Now().Add(-2 * time.Hour)
+	j.attempted = j.fetched
+	j.mu.Unlock()
+	if _, err := j.key(ctx, "k1"); err != nil {
+		t.Fatalf("stale key not used after failed refresh: %v", err)
+	}
+	if _, err := j.key(ctx, "k2"); err == nil {
+		t.Fatal("unknown kid accepted")
+	}
+}
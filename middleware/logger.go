@@ -1,29 +1,193 @@
 package middleware
 
 import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// 访问日志格式
+const (
+	LogFormatJSON     = "json"
+	LogFormatCombined = "combined"
+)
+
+// 可选的访问日志字段
+const (
+	FieldMethod    = "method"
+	FieldPath      = "path"
+	FieldQuery     = "query"
+	FieldRoute     = "route"
+	FieldStatus    = "status"
+	FieldLatency   = "latency"
+	FieldIP        = "ip"
+	FieldUserAgent = "user-agent"
+	FieldReferer   = "referer"
+	FieldBytesIn   = "bytes_in"
+	FieldBytesOut  = "bytes_out"
+	FieldRequestID = "request_id"
+	FieldTraceID   = "trace_id"
+	FieldProto     = "proto"
+	FieldHost      = "host"
+)
+
+// DefaultLogFields 默认输出的访问日志字段
+var DefaultLogFields = []string{
+	FieldMethod, FieldPath, FieldQuery, FieldStatus, FieldLatency,
+	FieldIP, FieldUserAgent, FieldRequestID,
+}
+
+// LoggerConfig 访问日志配置
+type LoggerConfig struct {
+	// Format 日志格式，json（结构化字段，默认）或 combined（Apache combined 格式的单行消息）
+	Format string
+	// Fields json 格式下输出的字段，为空时使用 DefaultLogFields
+	Fields []string
+	// ExtraFields 追加自定义字段
+	ExtraFields func(c *gin.Context) []zap.Field
+
+	// SkipPaths 不记录日志的请求路径，如 /healthz、/metrics
+	SkipPaths []string
+	// SampleRate 成功请求（2xx/3xx）的采样率，取值 (0, 1)，为 0 或 >= 1 时全部记录；
+	// 4xx/5xx 与慢请求始终记录
+	SampleRate float64
+	// SlowThreshold 慢请求阈值，超过该耗时的请求始终记录，并额外输出一条 warn 级别的 "Slow request" 日志
+	SlowThreshold time.Duration
+	// OnSlowRequest 检测到慢请求时的回调，可用于指标统计
+	OnSlowRequest func(c *gin.Context, latency time.Duration)
+}
+
+// accessEntry 单个请求的访问信息
+type accessEntry struct {
+	c       *gin.Context
+	start   time.Time
+	path    string
+	query   string
+	latency time.Duration
+}
+
+var fieldBuilders = map[string]func(e *accessEntry) zap.Field{
+	FieldMethod:    func(e *accessEntry) zap.Field { return zap.String(FieldMethod, e.c.Request.Method) },
+	FieldPath:      func(e *accessEntry) zap.Field { return zap.String(FieldPath, e.path) },
+	FieldQuery:     func(e *accessEntry) zap.Field { return zap.String(FieldQuery, e.query) },
+	FieldRoute:     func(e *accessEntry) zap.Field { return zap.String(FieldRoute, e.c.FullPath()) },
+	FieldStatus:    func(e *accessEntry) zap.Field { return zap.Int(FieldStatus, e.c.Writer.Status()) },
+	FieldLatency:   func(e *accessEntry) zap.Field { return zap.Duration(FieldLatency, e.latency) },
+	FieldIP:        func(e *accessEntry) zap.Field { return zap.String(FieldIP, ClientIP(e.c)) },
+	FieldUserAgent: func(e *accessEntry) zap.Field { return zap.String(FieldUserAgent, e.c.Request.UserAgent()) },
+	FieldReferer:   func(e *accessEntry) zap.Field { return zap.String(FieldReferer, e.c.Request.Referer()) },
+	FieldBytesIn:   func(e *accessEntry) zap.Field { return zap.Int64(FieldBytesIn, e.c.Request.ContentLength) },
+	FieldBytesOut:  func(e *accessEntry) zap.Field { return zap.Int(FieldBytesOut, max(e.c.Writer.Size(), 0)) },
+	FieldRequestID: func(e *accessEntry) zap.Field { return zap.String(FieldRequestID, GetRequestID(e.c)) },
+	FieldTraceID:   func(e *accessEntry) zap.Field { return zap.String(FieldTraceID, TraceID(e.c)) },
+	FieldProto:     func(e *accessEntry) zap.Field { return zap.String(FieldProto, e.c.Request.Proto) },
+	FieldHost:      func(e *accessEntry) zap.Field { return zap.String(FieldHost, e.c.Request.Host) },
+}
+
 // Logger 返回一个日志中间件
 func Logger(logger *zap.Logger) gin.HandlerFunc {
+	return LoggerWithConfig(logger, LoggerConfig{})
+}
+
+// LoggerWithConfig 返回一个可定制字段与格式的日志中间件
+func LoggerWithConfig(logger *zap.Logger, conf LoggerConfig) gin.HandlerFunc {
+	names := conf.Fields
+	if len(names) == 0 {
+		names = DefaultLogFields
+	}
+	builders := make([]func(e *accessEntry) zap.Field, 0, len(names))
+	for _, name := range names {
+		if b, ok := fieldBuilders[name]; ok {
+			builders = append(builders, b)
+		}
+	}
+	combined := conf.Format == LogFormatCombined
+	skip := make(map[string]struct{}, len(conf.SkipPaths))
+	for _, p := range conf.SkipPaths {
+		skip[p] = struct{}{}
+	}
+	sampled := conf.SampleRate > 0 && conf.SampleRate < 1
+
 	return func(c *gin.Context) {
-		start := time.Now()
-		path := c.Request.URL.Path
-		query := c.Request.URL.RawQuery
+		if _, ok := skip[c.Request.URL.Path]; ok {
+			c.Next()
+			return
+		}
+
+		e := &accessEntry{
+			c:     c,
+			start: time.Now(),
+			path:  c.Request.URL.Path,
+			query: c.Request.URL.RawQuery,
+		}
 
 		c.Next()
 
-		logger.Info("Request",
-			zap.String("method", c.Request.Method),
-			zap.String("path", path),
-			zap.String("query", query),
-			zap.Int("status", c.Writer.Status()),
-			zap.Duration("latency", time.Since(start)),
-			zap.String("ip", c.ClientIP()),
-			zap.String("user-agent", c.Request.UserAgent()),
-		)
+		e.latency = time.Since(e.start)
+
+		slow := conf.SlowThreshold > 0 && e.latency >= conf.SlowThreshold
+		if slow {
+			logger.Warn("Slow request",
+				zap.String("method", c.Request.Method),
+				zap.String("route", c.FullPath()),
+				zap.String("handler", c.HandlerName()),
+				zap.Duration("latency", e.latency),
+				zap.Duration("threshold", conf.SlowThreshold),
+				zap.String("request_id", GetRequestID(c)),
+			)
+			if conf.OnSlowRequest != nil {
+				conf.OnSlowRequest(c, e.latency)
+			}
+		}
+		if sampled && c.Writer.Status() < 400 && !slow && rand.Float64() >= conf.SampleRate {
+			return
+		}
+
+		if combined {
+			logger.Info(combinedLine(e))
+			return
+		}
+
+		fields := make([]zap.Field, 0, len(builders)+2)
+		for _, b := range builders {
+			fields = append(fields, b(e))
+		}
+		if conf.ExtraFields != nil {
+			fields = append(fields, conf.ExtraFields(c)...)
+		}
+		logger.Info("Request", fields...)
+	}
+}
+
+// combinedLine 生成 Apache combined 格式的日志行
+func combinedLine(e *accessEntry) string {
+	r := e.c.Request
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = u
+	}
+	size := "-"
+	if n := e.c.Writer.Size(); n > 0 {
+		size = fmt.Sprint(n)
+	}
+	uri := e.path
+	if e.query != "" {
+		uri += "?" + e.query
+	}
+	return fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %s "%s" "%s"`,
+		ClientIP(e.c), user, e.start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method, uri, r.Proto, e.c.Writer.Status(), size,
+		quoteEscape(r.Referer()), quoteEscape(r.UserAgent()),
+	)
+}
+
+func quoteEscape(s string) string {
+	if s == "" {
+		return "-"
 	}
+	return strings.ReplaceAll(s, `"`, `\"`)
 }
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeyFunc 从请求中提取限流键，返回空字符串表示不限流
+type KeyFunc func(c *gin.Context) string
+
+// KeyGlobal 所有请求共享同一个令牌桶
+func KeyGlobal(c *gin.Context) string {
+	return "global"
+}
+
+// KeyByIP 按客户端 IP 限流
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + ClientIP(c)
+}
+
+// KeyByHeader 按请求头（如 X-API-Key）限流，请求头为空时不限流
+func KeyByHeader(name string) KeyFunc {
+	return func(c *gin.Context) string {
+		v := c.GetHeader(name)
+		if v == "" {
+			return ""
+		}
+		return "key:" + v
+	}
+}
+
+// Limit 限流参数
+type Limit struct {
+	// Rate 每秒补充的令牌数
+	Rate float64
+	// Burst 令牌桶容量
+	Burst int
+}
+
+// RateLimitStore 限流状态存储，实现需保证并发安全
+type RateLimitStore interface {
+	// Take 尝试为 key 消耗一个令牌，失败时返回需要等待的时间
+	Take(ctx context.Context, key string, limit Limit) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	Limit
+	// KeyFunc 限流键提取函数，默认按客户端 IP
+	KeyFunc KeyFunc
+	// Store 限流状态存储，默认使用进程内存储
+	Store RateLimitStore
+	// TTL 进程内存储中令牌桶空闲多久后被清理，默认 10 分钟
+	TTL time.Duration
+}
+
+// RateLimit 返回一个令牌桶限流中间件，超出限制时返回 429 并设置 Retry-After，
+// 存储出错时放行请求并将错误记录到 c.Errors
+func RateLimit(conf RateLimitConfig) gin.HandlerFunc {
+	keyFunc := conf.KeyFunc
+	if keyFunc == nil {
+		keyFunc = KeyByIP
+	}
+	store := conf.Store
+	if store == nil {
+		store = NewMemoryStore(conf.TTL)
+	}
+
+	return func(c *gin.Context) {
+		key := keyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		allowed, retryAfter, err := store.Take(c.Request.Context(), key, conf.Limit)
+		if err != nil {
+			_ = c.Error(err)
+			c.Next()
+			return
+		}
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(max(seconds, 1)))
+			c.AbortWithStatus(http.StatusTooManyRequests)
+			return
+		}
+		c.Next()
+	}
+}
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// MemoryStore 进程内令牌桶存储，空闲超过 ttl 的令牌桶在访问时被惰性清理
+type MemoryStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// defaultBucketTTL 令牌桶默认的空闲清理时间
+const defaultBucketTTL = 10 * time.Minute
+
+// NewMemoryStore 创建进程内存储，ttl 为 0 时默认 10 分钟
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	if ttl <= 0 {
+		ttl = defaultBucketTTL
+	}
+	return &MemoryStore{
+		ttl:       ttl,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	now := time.Now()
+	burst := float64(max(limit.Burst, 1))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) > s.ttl {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, lastSeen: now}
+		s.buckets[key] = b
+	} else {
+		elapsed := now.Sub(b.lastSeen).Seconds()
+		b.tokens = math.Min(burst, b.tokens+elapsed*limit.Rate)
+		b.lastSeen = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	if limit.Rate <= 0 {
+		return false, s.ttl, nil
+	}
+	return false, time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second)), nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.lastSeen) > s.ttl {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
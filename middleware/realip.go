@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RealIPKey 解析后的客户端 IP 在 gin.Context 中的键
+const RealIPKey = "real_ip"
+
+// RealIPConfig 客户端真实 IP 解析配置
+type RealIPConfig struct {
+	// TrustedProxies 可信代理的 IP 或 CIDR，只有来自这些地址的转发头才会被采信
+	TrustedProxies []string
+}
+
+// RealIP 返回一个解析客户端真实 IP 的中间件。仅当直连对端是可信代理时，
+// 才依次采信 Forwarded、X-Forwarded-For（从右向左跳过可信代理）和 X-Real-IP
+func RealIP(conf RealIPConfig) (gin.HandlerFunc, error) {
+	trusted, err := parsePrefixes(conf.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	isTrusted := func(ip string) bool {
+		addr, err := netip.ParseAddr(ip)
+		return err == nil && containsAddr(trusted, addr.Unmap())
+	}
+
+	return func(c *gin.Context) {
+		c.Set(RealIPKey, resolveRealIP(c, isTrusted))
+		c.Next()
+	}, nil
+}
+
+// ClientIP 返回 RealIP 解析出的客户端 IP，未启用 RealIP 时回退到直连对端地址，
+// 不采信任何转发头
+func ClientIP(c *gin.Context) string {
+	if ip := c.GetString(RealIPKey); ip != "" {
+		return ip
+	}
+	return remotePeer(c.Request)
+}
+
+// remotePeer 返回 TCP 直连对端的 IP
+func remotePeer(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return peer
+}
+
+func resolveRealIP(c *gin.Context, isTrusted func(string) bool) string {
+	peer := remotePeer(c.Request)
+	if !isTrusted(peer) {
+		return peer
+	}
+
+	if ips := forwardedFor(c.GetHeader("Forwarded")); len(ips) > 0 {
+		return rightmostUntrusted(ips, isTrusted, peer)
+	}
+	if xff := c.GetHeader("X-Forwarded-For"); xff != "" {
+		return rightmostUntrusted(strings.Split(xff, ","), isTrusted, peer)
+	}
+	if ip := strings.TrimSpace(c.GetHeader("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+	return peer
+}
+
+// rightmostUntrusted 从右向左返回第一个非可信代理的地址，全部可信时返回最左侧地址
+func rightmostUntrusted(ips []string, isTrusted func(string) bool, fallback string) string {
+	for i := len(ips) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(ips[i])
+		if net.ParseIP(ip) == nil {
+			return fallback
+		}
+		if !isTrusted(ip) || i == 0 {
+			return ip
+		}
+	}
+	return fallback
+}
+
+// forwardedFor 解析 RFC 7239 Forwarded 头中的 for= 参数
+func forwardedFor(header string) []string {
+	if header == "" {
+		return nil
+	}
+	var ips []string
+	for _, element := range strings.Split(header, ",") {
+		for _, pair := range strings.Split(element, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || !strings.EqualFold(k, "for") {
+				continue
+			}
+			v = strings.Trim(v, `"`)
+			// IPv6 形如 "[2001:db8::1]:4711"
+			if strings.HasPrefix(v, "[") {
+				if end := strings.Index(v, "]"); end > 0 {
+					v = v[1:end]
+				}
+			} else if host, _, err := net.SplitHostPort(v); err == nil {
+				v = host
+			}
+			ips = append(ips, v)
+		}
+	}
+	return ips
+}
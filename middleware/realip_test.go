@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRealIPTrust(t *testing.T) {
+	realIP, err := RealIP(RealIPConfig{TrustedProxies: []string{"10.0.0.0/8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.Use(realIP)
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, ClientIP(c)) })
+
+	tests := []struct {
+		name   string
+		remote string
+		header map[string]string
+		want   string
+	}{
+		{"untrusted peer ignores headers", "203.0.113.5:1000", map[string]string{"X-Forwarded-For": "1.1.1.1", "X-Real-IP": "1.1.1.1"}, "203.0.113.5"},
+		{"trusted peer without headers", "10.0.0.1:1000", nil, "10.0.0.1"},
+		{"rightmost untrusted hop", "10.0.0.1:1000", map[string]string{"X-Forwarded-For": "6.6.6.6, 198.51.100.7, 10.0.0.2"}, "198.51.100.7"},
+		{"all hops trusted", "10.0.0.1:1000", map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"invalid hop falls back to peer", "10.0.0.1:1000", map[string]string{"X-Forwarded-For": "evil, 10.0.0.2"}, "10.0.0.1"},
+		{"forwarded takes precedence", "10.0.0.1:1000", map[string]string{"Forwarded": `for="[2001:db8::1]:4711"`, "X-Forwarded-For": "1.1.1.1"}, "2001:db8::1"},
+		{"x-real-ip", "10.0.0.1:1000", map[string]string{"X-Real-IP": "198.51.100.9"}, "198.51.100.9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if got := w.Body.String(); got != tt.want {
+				t.Fatalf("client ip = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPWithoutRealIP(t *testing.T) {
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, ClientIP(c)) })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.5:1000"
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := w.Body.String(); got != "203.0.113.5" {
+		t.Fatalf("client ip = %q, want direct peer", got)
+	}
+}
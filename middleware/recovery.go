@@ -1,24 +1,136 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
-	"runtime/debug"
+	"runtime"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
+// Frame 一个调用栈帧
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+func (f Frame) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("function", f.Function)
+	enc.AddString("file", f.File)
+	enc.AddInt("line", f.Line)
+	return nil
+}
+
+// Frames 调用栈
+type Frames []Frame
+
+func (fs Frames) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, f := range fs {
+		if err := enc.AppendObject(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PanicHook panic 回调，可用于上报告警，在写出响应前调用
+type PanicHook func(c *gin.Context, err any, stack Frames)
+
+// RecoveryConfig panic 恢复配置
+type RecoveryConfig struct {
+	// Hooks panic 回调
+	Hooks []PanicHook
+	// ErrorBody 生成返回给客户端的 JSON 响应体，默认 {"code":500,"message":"Internal Server Error"}
+	ErrorBody func(c *gin.Context, err any) any
+}
+
+// PanicError 在处理函数所在的其它 goroutine 中捕获的 panic（如 Timeout），
+// 携带原始调用栈，Recovery 重新捕获后以原值与原始调用栈记录并回调
+type PanicError struct {
+	Value any
+	Stack Frames
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprint(e.Value)
+}
+
 func Recovery(logger *zap.Logger) gin.HandlerFunc {
+	return RecoveryWithConfig(logger, RecoveryConfig{})
+}
+
+// RecoveryWithConfig 返回一个 panic 恢复中间件，记录结构化调用栈、执行回调并返回 JSON 错误
+func RecoveryWithConfig(logger *zap.Logger, conf RecoveryConfig) gin.HandlerFunc {
+	errorBody := conf.ErrorBody
+	if errorBody == nil {
+		errorBody = func(*gin.Context, any) any {
+			return gin.H{"code": http.StatusInternalServerError, "message": http.StatusText(http.StatusInternalServerError)}
+		}
+	}
+
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
+				stack := callers(3)
+				if pe, ok := err.(*PanicError); ok {
+					err, stack = pe.Value, pe.Stack
+				}
+				// http.ErrAbortHandler 用于主动中断响应，不视为错误
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
 				logger.Error("Panic recovered",
-					zap.Any("error", err),
-					zap.String("stack", string(debug.Stack())),
+					zap.String("error", fmt.Sprint(err)),
+					zap.String("method", c.Request.Method),
+					zap.String("path", c.Request.URL.Path),
+					zap.String("request_id", GetRequestID(c)),
+					zap.Array("stack", stack),
 				)
-				c.AbortWithStatus(http.StatusInternalServerError)
+
+				for _, hook := range conf.Hooks {
+					runPanicHook(logger, hook, c, err, stack)
+				}
+
+				if c.Writer.Written() {
+					c.Abort()
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, errorBody(c, err))
 			}
 		}()
 		c.Next()
 	}
 }
+
+func runPanicHook(logger *zap.Logger, hook PanicHook, c *gin.Context, err any, stack Frames) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Panic hook failed", zap.Any("error", r))
+		}
+	}()
+	hook(c, err, stack)
+}
+
+// callers 采集调用栈，跳过 runtime 内部帧
+func callers(skip int) Frames {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	stack := make(Frames, 0, n)
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, "runtime.") {
+			stack = append(stack, Frame{Function: f.Function, File: f.File, Line: f.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
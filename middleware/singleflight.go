@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+// SingleflightConfig 请求合并配置
+type SingleflightConfig struct {
+	// KeyFunc 计算合并键，返回空字符串时不合并。默认由请求方法、URL、Authorization 与 Cookie 组成，
+	// 避免不同用户的响应被共享
+	KeyFunc func(c *gin.Context) string
+}
+
+// sharedResponse 由首个请求生成、供等待者共享的响应
+type sharedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// Singleflight 返回请求合并中间件：相同键的并发 GET 请求只执行一次处理函数，
+// 其余请求等待并复用其响应，用于在缓存击穿等突发流量下保护开销较大的读接口
+func Singleflight(conf SingleflightConfig) gin.HandlerFunc {
+	if conf.KeyFunc == nil {
+		conf.KeyFunc = defaultSingleflightKey
+	}
+	var group singleflight.Group
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		key := conf.KeyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		leader := false
+		v, _, _ := group.Do(key, func() (any, error) {
+			leader = true
+			w := &cacheWriter{ResponseWriter: c.Writer, maxSize: DefaultCacheMaxBody}
+			c.Writer = w
+			c.Next()
+			c.Writer = w.ResponseWriter
+
+			if w.overflow || len(c.Errors) > 0 {
+				return nil, nil
+			}
+			return &sharedResponse{status: w.Status(), header: w.Header().Clone(), body: w.buf.Bytes()}, nil
+		})
+		if leader {
+			return
+		}
+
+		resp, _ := v.(*sharedResponse)
+		if resp == nil {
+			// 首个请求的响应无法共享时独立执行
+			c.Next()
+			return
+		}
+		h := c.Writer.Header()
+		for k, vals := range resp.header {
+			if !slices.Contains(skipCacheHeaders, k) {
+				h[k] = vals
+			}
+		}
+		c.Status(resp.status)
+		_, _ = c.Writer.Write(resp.body)
+		c.Abort()
+	}
+}
+
+func defaultSingleflightKey(c *gin.Context) string {
+	var b strings.Builder
+	b.WriteString(c.Request.Method)
+	b.WriteByte(' ')
+	b.WriteString(c.Request.URL.RequestURI())
+	for _, name := range []string{"Authorization", "Cookie"} {
+		b.WriteByte('\n')
+		b.WriteString(c.GetHeader(name))
+	}
+	return b.String()
+}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutConfig 请求超时配置
+type TimeoutConfig struct {
+	// Timeout 默认超时时间，为 0 时不限制
+	Timeout time.Duration
+	// Routes 按路由模式（c.FullPath()）覆盖超时时间，如 "/upload": time.Minute
+	Routes map[string]time.Duration
+}
+
+// Timeout 返回一个请求超时中间件。处理函数在设置了截止时间的 context 中执行，
+// 超时后立即向客户端发送 504，处理函数之后的写入会被丢弃。由于 gin.Context 会被复用，
+// 中间件仍会等待处理函数返回后才结束，处理函数应尊重 c.Request.Context() 尽早退出
+func Timeout(conf TimeoutConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d := conf.Timeout
+		if override, ok := conf.Routes[c.FullPath()]; ok {
+			d = override
+		}
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		w := &timeoutWriter{ResponseWriter: c.Writer, header: make(http.Header), status: http.StatusOK}
+		c.Writer = w
+
+		// 启动 goroutine 后本 goroutine 不再访问 c，直到处理函数返回
+		done := make(chan struct{})
+		panicChan := make(chan any, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					// 在原 goroutine 中采集调用栈，重新 panic 后 Recovery 仍能定位到处理函数
+					panicChan <- &PanicError{Value: p, Stack: callers(3)}
+				}
+			}()
+			c.Next()
+			close(done)
+		}()
+
+		select {
+		case p := <-panicChan:
+			w.abandon()
+			c.Writer = w.ResponseWriter
+			panic(p)
+
+		case <-done:
+			w.flush()
+
+		case <-ctx.Done():
+			w.timeout()
+			select {
+			case p := <-panicChan:
+				c.Writer = w.ResponseWriter
+				panic(p)
+			case <-done:
+			}
+		}
+	}
+}
+
+// timeoutWriter 缓冲处理函数的输出，超时后丢弃所有写入
+type timeoutWriter struct {
+	gin.ResponseWriter
+
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	status   int
+	written  bool
+	timedOut bool
+	flushed  bool
+}
+
+// flush 将缓冲的响应写出，之后的写入直接透传
+func (w *timeoutWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	dst := w.ResponseWriter.Header()
+	for k, v := range w.header {
+		dst[k] = v
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+	w.flushed = true
+}
+
+// timeout 立即发送 504 并丢弃处理函数后续的写入
+func (w *timeoutWriter) timeout() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timedOut = true
+	w.status = http.StatusGatewayTimeout
+	w.ResponseWriter.WriteHeader(http.StatusGatewayTimeout)
+	w.ResponseWriter.Flush()
+}
+
+// abandon 丢弃处理函数的输出，交由上层 Recovery 写入响应
+func (w *timeoutWriter) abandon() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timedOut = true
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut || w.written {
+		return
+	}
+	w.status = code
+	w.written = true
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.written = true
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.flushed {
+		return w.ResponseWriter.Write(b)
+	}
+	w.written = true
+	return w.buf.Write(b)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 仅在缓冲已写出后透传，避免提前发送处理函数的响应头
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.flushed {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.flushed || w.timedOut {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.buf.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.written || w.timedOut
+}
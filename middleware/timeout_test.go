@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestTimeoutReturns504(t *testing.T) {
+	r := gin.New()
+	r.Use(Timeout(TimeoutConfig{Timeout: 20 * time.Millisecond}))
+	r.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.String(http.StatusOK, "late")
+	})
+	r.GET("/fast", func(c *gin.Context) { c.String(http.StatusCreated, "ok") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("slow status = %d, want 504", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "late") {
+		t.Fatalf("late write leaked: %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if w.Code != http.StatusCreated || w.Body.String() != "ok" {
+		t.Fatalf("fast = %d %q", w.Code, w.Body.String())
+	}
+}
+
+func panickingHandler(*gin.Context) {
+	panic("boom")
+}
+
+func TestTimeoutPanicKeepsStack(t *testing.T) {
+	var (
+		gotErr   any
+		gotStack Frames
+	)
+	r := gin.New()
+	r.Use(RecoveryWithConfig(zap.NewNop(), RecoveryConfig{
+		Hooks: []PanicHook{func(_ *gin.Context, err any, stack Frames) { gotErr, gotStack = err, stack }},
+	}))
+	r.Use(Timeout(TimeoutConfig{Timeout: time.Second}))
+	r.GET("/", panickingHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+	if gotErr != "boom" {
+		t.Fatalf("panic value = %v, want boom", gotErr)
+	}
+	if len(gotStack) == 0 || !strings.HasSuffix(gotStack[0].Function, "panickingHandler") {
+		t.Fatalf("top frame = %+v, want panickingHandler", gotStack)
+	}
+}
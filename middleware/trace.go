@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TraceID 从 W3C traceparent 请求头中解析 trace id，不存在时返回空字符串
+func TraceID(c *gin.Context) string {
+	parts := strings.Split(c.GetHeader("traceparent"), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	return parts[1]
+}
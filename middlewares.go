@@ -0,0 +1,148 @@
+package ginx
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gaoxin19/ginx/config"
+	"github.com/gaoxin19/ginx/metrics"
+	"github.com/gaoxin19/ginx/middleware"
+	"github.com/gaoxin19/ginx/redact"
+)
+
+// useMiddlewares 根据配置注册全局中间件
+func (e *Engine) useMiddlewares() error {
+	router, opts, logger := e.Engine, e.options, e.logger
+
+	// 未配置时显式设为不信任任何代理，gin 默认信任所有来源的转发头
+	if err := router.SetTrustedProxies(opts.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	// 可热加载的中间件通过 swapHandler 注册，配置为空时直接放行
+	realIP, err := buildRealIP(opts)
+	if err != nil {
+		return err
+	}
+	e.realIP.set(realIP)
+	router.Use(e.realIP.handle)
+	if opts.EnableRequestID {
+		router.Use(middleware.RequestID())
+	}
+	if opts.EnableRecovery {
+		router.Use(middleware.RecoveryWithConfig(logger, middleware.RecoveryConfig{
+			Hooks: []middleware.PanicHook{e.runPanicHooks},
+		}))
+	}
+	if opts.EnableLogger {
+		logConf := middleware.LoggerConfig{
+			SlowThreshold: opts.SlowRequestThreshold,
+			OnSlowRequest: func(c *gin.Context, _ time.Duration) {
+				metrics.SlowRequests.WithLabelValues(c.Request.Method, c.FullPath()).Inc()
+			},
+		}
+		if opts.AccessLog != nil {
+			logConf.Format = opts.AccessLog.Format
+			logConf.Fields = opts.AccessLog.Fields
+			logConf.SkipPaths = opts.AccessLog.SkipPaths
+			logConf.SampleRate = opts.AccessLog.SampleRate
+		}
+		router.Use(middleware.LoggerWithConfig(logger, logConf))
+	}
+	e.cors.set(buildCORS(opts))
+	router.Use(e.cors.handle)
+	e.rateLimit.set(e.buildRateLimit(opts))
+	router.Use(e.rateLimit.handle)
+	if opts.SecureHeaders != nil {
+		router.Use(middleware.SecureHeaders(middleware.SecureHeadersConfig{
+			HSTSMaxAge:            opts.SecureHeaders.HSTSMaxAge,
+			HSTSIncludeSubdomains: opts.SecureHeaders.HSTSIncludeSubdomains,
+			HSTSPreload:           opts.SecureHeaders.HSTSPreload,
+			ContentTypeNosniff:    opts.SecureHeaders.ContentTypeNosniff,
+			FrameOptions:          opts.SecureHeaders.FrameOptions,
+			ReferrerPolicy:        opts.SecureHeaders.ReferrerPolicy,
+			ContentSecurityPolicy: opts.SecureHeaders.ContentSecurityPolicy,
+		}))
+	}
+	if opts.BodyLimit != nil {
+		router.Use(middleware.BodyLimit(middleware.BodyLimitConfig{
+			Limit:  opts.BodyLimit.Limit,
+			Routes: opts.BodyLimit.Routes,
+		}))
+	}
+	if opts.EnableErrorHandler {
+		router.Use(ErrorHandler())
+	}
+	if opts.BodyCapture != nil {
+		// 捕获的 body 同时应用日志配置中的脱敏字段
+		fields := opts.BodyCapture.RedactFields
+		if len(fields) == 0 {
+			fields = redact.DefaultFields
+		}
+		redactor, err := redact.New(slices.Concat(fields, opts.Logger.RedactFields), opts.Logger.RedactPatterns)
+		if err != nil {
+			return err
+		}
+		router.Use(middleware.BodyCapture(logger, middleware.BodyCaptureConfig{
+			Enabled:      opts.BodyCapture.Enabled,
+			Header:       opts.BodyCapture.Header,
+			MaxSize:      opts.BodyCapture.MaxSize,
+			ContentTypes: opts.BodyCapture.ContentTypes,
+			Redactor:     redactor,
+		}))
+	}
+	return nil
+}
+
+func buildRealIP(opts *config.Options) (gin.HandlerFunc, error) {
+	if opts.TrustedProxies == nil {
+		return nil, nil
+	}
+	realIP, err := middleware.RealIP(middleware.RealIPConfig{TrustedProxies: opts.TrustedProxies})
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	return realIP, nil
+}
+
+func buildCORS(opts *config.Options) gin.HandlerFunc {
+	if opts.CORS == nil {
+		return nil
+	}
+	return middleware.CORS(middleware.CORSConfig{
+		AllowOrigins:        opts.CORS.AllowOrigins,
+		AllowOriginPatterns: opts.CORS.AllowOriginPatterns,
+		AllowMethods:        opts.CORS.AllowMethods,
+		AllowHeaders:        opts.CORS.AllowHeaders,
+		ExposeHeaders:       opts.CORS.ExposeHeaders,
+		AllowCredentials:    opts.CORS.AllowCredentials,
+		MaxAge:              opts.CORS.MaxAge,
+	})
+}
+
+// buildRateLimit 重新加载时共用同一个令牌桶存储，只替换限流参数
+func (e *Engine) buildRateLimit(opts *config.Options) gin.HandlerFunc {
+	if opts.RateLimit == nil {
+		return nil
+	}
+	if e.rateStore == nil {
+		e.rateStore = middleware.NewMemoryStore(0)
+	}
+	return middleware.RateLimit(middleware.RateLimitConfig{
+		Limit: middleware.Limit{Rate: opts.RateLimit.Rate, Burst: opts.RateLimit.Burst},
+		Store: e.rateStore,
+	})
+}
+
+func (e *Engine) runPanicHooks(c *gin.Context, err any, stack middleware.Frames) {
+	e.hooksMu.Lock()
+	hooks := make([]middleware.PanicHook, len(e.panicHooks))
+	copy(hooks, e.panicHooks)
+	e.hooksMu.Unlock()
+
+	for _, hook := range hooks {
+		hook(c, err, stack)
+	}
+}
@@ -0,0 +1,16 @@
+package ginx
+
+import (
+	"math"
+
+	"github.com/gaoxin19/ginx/db/migrate"
+)
+
+// migrateStartPriority 迁移在数据库 Ping 之后、其他启动钩子之前执行
+const migrateStartPriority = math.MinInt + 1
+
+// UseMigrations 在服务就绪之前执行待执行的数据库迁移，失败时终止启动；
+// 多副本同时启动时由咨询锁保证只有一个实例执行迁移
+func (e *Engine) UseMigrations(m *migrate.Migrator) {
+	e.RegisterOnStart("migrate", m.Up, WithPriority(migrateStartPriority))
+}
@@ -0,0 +1,94 @@
+package ginx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Module 自包含的功能模块，拥有自己的路由、中间件与生命周期
+type Module interface {
+	// Name 模块名，在同一引擎中唯一
+	Name() string
+	// Routes 注册模块的路由，r 已应用 Middlewares 返回的中间件
+	Routes(r gin.IRouter)
+	// Middlewares 只作用于本模块路由的中间件
+	Middlewares() []gin.HandlerFunc
+	// OnStart 服务开始接收请求前按注册顺序调用，返回错误会终止启动
+	OnStart(ctx context.Context) error
+	// OnStop 服务停止接收请求后按注册的逆序调用
+	OnStop(ctx context.Context) error
+}
+
+// BaseModule 提供 Module 中可选方法的空实现，可嵌入到模块中
+type BaseModule struct{}
+
+func (BaseModule) Middlewares() []gin.HandlerFunc    { return nil }
+func (BaseModule) OnStart(ctx context.Context) error { return nil }
+func (BaseModule) OnStop(ctx context.Context) error  { return nil }
+
+// Register 注册模块：立即注册路由，并在服务启动与关闭时调用模块的 OnStart 与 OnStop
+func (e *Engine) Register(modules ...Module) error {
+	e.hooksMu.Lock()
+	first := len(e.modules) == 0
+	for _, m := range modules {
+		for _, existing := range e.modules {
+			if existing.Name() == m.Name() {
+				e.hooksMu.Unlock()
+				return fmt.Errorf("module %q already registered", m.Name())
+			}
+		}
+		e.modules = append(e.modules, m)
+	}
+	e.hooksMu.Unlock()
+
+	for _, m := range modules {
+		m.Routes(e.Group("", m.Middlewares()...))
+		e.logger.Debug("Module registered", zap.String("module", m.Name()))
+	}
+	if first && len(modules) > 0 {
+		e.RegisterOnStart("modules", e.startModules)
+		e.RegisterOnShutdown("modules", e.stopModules)
+	}
+	return nil
+}
+
+// Modules 返回已注册的模块
+func (e *Engine) Modules() []Module {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	return append([]Module(nil), e.modules...)
+}
+
+// startModules 按注册顺序启动模块，失败时逆序停止已启动的模块
+func (e *Engine) startModules(ctx context.Context) error {
+	modules := e.Modules()
+	for i, m := range modules {
+		if err := m.OnStart(ctx); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				if serr := modules[j].OnStop(ctx); serr != nil {
+					e.logger.Error("Module stop failed", zap.String("module", modules[j].Name()), zap.Error(serr))
+				}
+			}
+			return fmt.Errorf("module %q: %w", m.Name(), err)
+		}
+	}
+	return nil
+}
+
+// stopModules 按注册的逆序停止模块，汇总所有错误
+func (e *Engine) stopModules(ctx context.Context) error {
+	modules := e.Modules()
+	var errs []error
+	for i := len(modules) - 1; i >= 0; i-- {
+		m := modules[i]
+		if err := m.OnStop(ctx); err != nil {
+			e.logger.Error("Module stop failed", zap.String("module", m.Name()), zap.Error(err))
+			errs = append(errs, fmt.Errorf("module %q: %w", m.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,211 @@
+package ginx
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gaoxin19/ginx/config"
+	"github.com/gaoxin19/ginx/openapi"
+)
+
+// DefaultOpenAPIPath 未配置 OpenAPI.Path 时文档的路径
+const DefaultOpenAPIPath = "/openapi.json"
+
+// typedHandler Handle 记录的请求与响应类型
+type typedHandler struct {
+	req, resp reflect.Type
+}
+
+// typedHandlers 以闭包地址记录 Handle 创建的处理函数，生成文档时与路由树中的处理函数对应
+var typedHandlers sync.Map
+
+func registerTyped(h gin.HandlerFunc, req, resp reflect.Type) {
+	typedHandlers.Store(*(*unsafe.Pointer)(unsafe.Pointer(&h)), typedHandler{req: req, resp: resp})
+}
+
+// OpenAPI 由通过 Handle 注册的路由生成 OpenAPI 文档：路径参数取自 uri 标签，查询参数取自 form 标签，
+// 其余字段作为 JSON 请求体，失败响应为 problem+json
+func (e *Engine) OpenAPI() *openapi.Document {
+	conf := e.options.OpenAPI
+	if conf == nil {
+		conf = &config.OpenAPIOptions{}
+	}
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       conf.Title,
+			Description: conf.Description,
+			Version:     conf.Version,
+		},
+		Paths: make(map[string]*openapi.PathItem),
+	}
+	if doc.Info.Title == "" {
+		doc.Info.Title = "API"
+	}
+	if doc.Info.Version == "" {
+		doc.Info.Version = GetBuildInfo().Version
+	}
+
+	g := openapi.NewGenerator()
+	problem := g.Schema(reflect.TypeOf(Problem{}))
+	chains := handlerChains(e.Engine)
+	for _, r := range e.Routes() {
+		v, ok := typedHandlers.Load(chains[r.Method+" "+r.Path].handler)
+		if !ok {
+			continue
+		}
+		op := e.operation(g, r.Method, r.Path, v.(typedHandler))
+		op.Responses["default"] = &openapi.Response{
+			Description: "Error",
+			Content:     map[string]*openapi.MediaType{"application/problem+json": {Schema: problem}},
+		}
+
+		p := openapiPath(r.Path)
+		item := doc.Paths[p]
+		if item == nil {
+			item = &openapi.PathItem{}
+			doc.Paths[p] = item
+		}
+		item.Set(r.Method, op)
+	}
+	doc.Components.Schemas = g.Schemas()
+	return doc
+}
+
+// operation 生成单个路由的接口描述
+func (e *Engine) operation(g *openapi.Generator, method, route string, th typedHandler) *openapi.Operation {
+	op := &openapi.Operation{
+		OperationID: operationID(method, route),
+		Deprecated:  e.deprecatedRoute(route),
+		Responses:   make(map[string]*openapi.Response),
+	}
+
+	empty := reflect.TypeOf(Empty{})
+	if th.req != empty {
+		op.Parameters = g.Parameters(th.req)
+		if method != http.MethodGet && method != http.MethodHead {
+			if body := g.Body(th.req); body != nil {
+				op.RequestBody = &openapi.RequestBody{
+					Required: len(body.Required) > 0,
+					Content:  map[string]*openapi.MediaType{"application/json": {Schema: body}},
+				}
+			}
+		}
+	}
+	// 路由中有但请求类型未声明的路径参数
+	for _, seg := range strings.Split(route, "/") {
+		if len(seg) < 2 || (seg[0] != ':' && seg[0] != '*') {
+			continue
+		}
+		name := seg[1:]
+		declared := false
+		for _, p := range op.Parameters {
+			declared = declared || (p.In == "path" && p.Name == name)
+		}
+		if !declared {
+			op.Parameters = append(op.Parameters, &openapi.Parameter{
+				Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "string"},
+			})
+		}
+	}
+
+	status := http.StatusOK
+	if sc, ok := reflect.Zero(th.resp).Interface().(StatusCoder); ok && th.resp.Kind() != reflect.Pointer {
+		status = sc.StatusCode()
+	}
+	resp := &openapi.Response{Description: http.StatusText(status)}
+	if th.resp != empty && status != http.StatusNoContent {
+		schema := g.Schema(th.resp)
+		if !responseConfig.Load().Bare {
+			schema = &openapi.Schema{
+				Type: "object",
+				Properties: map[string]*openapi.Schema{
+					"code": {Type: "integer"},
+					"msg":  {Type: "string"},
+					"data": schema,
+				},
+			}
+		}
+		resp.Content = map[string]*openapi.MediaType{"application/json": {Schema: schema}}
+	}
+	op.Responses[strconv.Itoa(status)] = resp
+	return op
+}
+
+// deprecatedRoute 判断路由是否属于 API.Deprecated 中的版本
+func (e *Engine) deprecatedRoute(route string) bool {
+	api := e.options.API
+	if api == nil {
+		return false
+	}
+	prefix := DefaultAPIPrefix
+	if api.Prefix != "" {
+		prefix = api.Prefix
+	}
+	rest, ok := strings.CutPrefix(route, strings.TrimSuffix(prefix, "/")+"/")
+	if !ok {
+		return false
+	}
+	version, _, _ := strings.Cut(rest, "/")
+	_, deprecated := api.Deprecated[version]
+	return deprecated
+}
+
+// openapiPath 将 gin 路由模式转换为 OpenAPI 路径，如 /users/:id -> /users/{id}
+func openapiPath(route string) string {
+	segs := strings.Split(route, "/")
+	for i, seg := range segs {
+		if len(seg) > 1 && (seg[0] == ':' || seg[0] == '*') {
+			segs[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// operationID 由方法与路径生成，如 GET /api/v1/users/:id -> getApiV1UsersId
+func operationID(method, route string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, seg := range strings.FieldsFunc(route, func(r rune) bool {
+		return r == '/' || r == ':' || r == '*' || r == '-' || r == '_' || r == '.'
+	}) {
+		b.WriteString(strings.ToUpper(seg[:1]) + seg[1:])
+	}
+	return b.String()
+}
+
+// mountOpenAPI 提供文档与文档页面，配置了 AllowIPs 或 Users 时两者都需要通过访问控制
+func (e *Engine) mountOpenAPI(conf *config.OpenAPIOptions) error {
+	handlers, err := e.accessControl("openapi", conf.AllowIPs, conf.Users)
+	if err != nil {
+		return err
+	}
+	specPath := conf.Path
+	if specPath == "" {
+		specPath = DefaultOpenAPIPath
+	}
+	e.GET(specPath, append(handlers, func(c *gin.Context) {
+		c.JSON(http.StatusOK, e.cachedOpenAPI())
+	})...)
+
+	switch {
+	case conf.UIPath == "":
+	case conf.UI == "redoc":
+		e.MountRedoc(conf.UIPath, SpecURL(specPath), handlers...)
+	default:
+		e.MountSwaggerUI(conf.UIPath, SpecURL(specPath), handlers...)
+	}
+	return nil
+}
+
+// cachedOpenAPI 首次调用时生成文档，路由需在服务启动前注册
+func (e *Engine) cachedOpenAPI() *openapi.Document {
+	e.openapiOnce.Do(func() { e.openapiDoc = e.OpenAPI() })
+	return e.openapiDoc
+}
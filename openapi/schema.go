@@ -0,0 +1,311 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generator 由 Go 类型生成 Schema：具名结构体注册到 components 并以 $ref 引用，
+// 字段名取自 json 标签，约束取自 binding 标签，description 与 example 标签作为说明与示例
+type Generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+// NewGenerator 创建 Generator
+func NewGenerator() *Generator {
+	return &Generator{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// Schemas 返回已注册的结构定义，用于 Document.Components
+func (g *Generator) Schemas() map[string]*Schema {
+	return g.schemas
+}
+
+// Schema 返回类型 t 的 Schema
+func (g *Generator) Schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Kind() != reflect.Struct && reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer"}
+	case reflect.Int32, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t, nil)
+		}
+		name, ok := g.names[t]
+		if !ok {
+			name = g.name(t)
+			g.names[t] = name
+			// 先占位再生成，以支持递归引用
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.object(t, nil)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+// Parameters 返回 t 中 uri 标签（路径参数）与 form 标签（查询参数）对应的参数
+func (g *Generator) Parameters(t reflect.Type) []*Parameter {
+	t = indirect(t)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var params []*Parameter
+	eachField(t, func(f reflect.StructField) {
+		for _, in := range [...]struct{ tag, in string }{{"uri", "path"}, {"form", "query"}} {
+			name := tagName(f.Tag.Get(in.tag))
+			if name == "" {
+				continue
+			}
+			s := g.Schema(f.Type)
+			rules := parseBinding(f.Tag.Get("binding"))
+			rules.apply(s)
+			params = append(params, &Parameter{
+				Name:        name,
+				In:          in.in,
+				Description: f.Tag.Get("description"),
+				Required:    in.in == "path" || rules.required,
+				Schema:      s,
+			})
+		}
+	})
+	return params
+}
+
+// Body 返回请求体的 Schema：结构体中仅由 uri 或 form 绑定且没有 json 标签的字段不属于请求体，
+// 没有请求体字段时返回 nil
+func (g *Generator) Body(t reflect.Type) *Schema {
+	t = indirect(t)
+	if t.Kind() != reflect.Struct {
+		return g.Schema(t)
+	}
+	s := g.object(t, func(f reflect.StructField) bool {
+		if _, ok := f.Tag.Lookup("json"); ok {
+			return true
+		}
+		_, uri := f.Tag.Lookup("uri")
+		_, form := f.Tag.Lookup("form")
+		return !uri && !form
+	})
+	if len(s.Properties) == 0 {
+		return nil
+	}
+	return s
+}
+
+// object 生成结构体的内联 Schema，keep 为 nil 时保留所有字段
+func (g *Generator) object(t reflect.Type, keep func(reflect.StructField) bool) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	eachField(t, func(f reflect.StructField) {
+		if keep != nil && !keep(f) {
+			return
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := g.Schema(f.Type)
+		rules := parseBinding(f.Tag.Get("binding"))
+		// $ref 的兄弟属性会被忽略，引用类型不附加约束与说明
+		if fs.Ref == "" {
+			rules.apply(fs)
+			fs.Description = f.Tag.Get("description")
+			if ex := f.Tag.Get("example"); ex != "" {
+				fs.Example = example(ex, fs.Type)
+			}
+		}
+		if opts == "string" {
+			fs = &Schema{Type: "string", Description: fs.Description}
+		}
+		s.Properties[name] = fs
+		if rules.required {
+			s.Required = append(s.Required, name)
+		}
+	})
+	return s
+}
+
+// name 返回结构体在 components 中的名称，泛型参数以 _ 连接，重名时加上包名
+func (g *Generator) name(t reflect.Type) string {
+	parts := strings.FieldsFunc(t.Name(), func(r rune) bool { return r == '[' || r == ']' || r == ',' })
+	for i, p := range parts {
+		parts[i] = p[strings.LastIndex(p, ".")+1:]
+	}
+	name := strings.Join(parts, "_")
+	if _, taken := g.schemas[name]; !taken {
+		return name
+	}
+	name = path.Base(t.PkgPath()) + "_" + name
+	for i := 2; ; i++ {
+		if _, taken := g.schemas[name]; !taken {
+			return name
+		}
+		name = strings.TrimRight(name, "0123456789") + strconv.Itoa(i)
+	}
+}
+
+// eachField 遍历导出字段，没有 json 标签的嵌入结构体展开
+func eachField(t reflect.Type, fn func(reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Tag.Get("json") == "" && indirect(f.Type).Kind() == reflect.Struct {
+			eachField(indirect(f.Type), fn)
+			continue
+		}
+		if !f.IsExported() || f.Tag.Get("json") == "-" {
+			continue
+		}
+		fn(f)
+	}
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func tagName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func example(s, typ string) any {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+	return s
+}
+
+// bindingRules binding 标签中可以表达为 Schema 约束的规则
+type bindingRules struct {
+	required bool
+	enum     []string
+	min, max *float64
+	format   string
+}
+
+func parseBinding(tag string) bindingRules {
+	var r bindingRules
+	for _, rule := range strings.Split(tag, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			r.required = true
+		case "oneof":
+			r.enum = strings.Fields(param)
+		case "min", "gte":
+			r.min = parseFloat(param)
+		case "max", "lte":
+			r.max = parseFloat(param)
+		case "len":
+			r.min, r.max = parseFloat(param), parseFloat(param)
+		case "email":
+			r.format = "email"
+		case "url", "uri":
+			r.format = "uri"
+		case "uuid", "uuid4":
+			r.format = "uuid"
+		case "ip":
+			r.format = "ip"
+		case "datetime":
+			r.format = "date-time"
+		case "dive":
+			// 之后的规则作用于元素
+			return r
+		}
+	}
+	return r
+}
+
+func parseFloat(s string) *float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+func (r bindingRules) apply(s *Schema) {
+	if r.format != "" {
+		s.Format = r.format
+	}
+	for _, v := range r.enum {
+		s.Enum = append(s.Enum, example(v, s.Type))
+	}
+	switch s.Type {
+	case "integer", "number":
+		s.Minimum, s.Maximum = r.min, r.max
+	case "string":
+		if r.min != nil {
+			n := int(*r.min)
+			s.MinLength = &n
+		}
+		if r.max != nil {
+			n := int(*r.max)
+			s.MaxLength = &n
+		}
+	}
+}
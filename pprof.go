@@ -0,0 +1,83 @@
+package ginx
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gaoxin19/ginx/config"
+	"github.com/gaoxin19/ginx/middleware"
+)
+
+// mountPprof 按配置注册 pprof 与 expvar 调试接口，未单独指定地址时挂载到 router
+func (e *Engine) mountPprof(router gin.IRouter) error {
+	conf := e.options.Pprof
+	if conf == nil {
+		conf = &config.PprofOptions{}
+	}
+	prefix := "/" + strings.Trim(conf.Prefix, "/")
+	if prefix == "/" {
+		prefix = "/debug"
+	}
+
+	allowIPs := conf.AllowIPs
+	if len(allowIPs) == 0 && len(conf.Users) == 0 && !e.adminProtected(router, conf.Addr) {
+		// 调试接口会暴露堆、协程与命令行参数，未配置访问控制时只允许本机访问
+		allowIPs = defaultPprofAllowIPs
+		e.logger.Warn("Pprof has no access control configured, allowing loopback only")
+	}
+	handlers, err := e.accessControl("pprof", allowIPs, conf.Users)
+	if err != nil {
+		return err
+	}
+
+	if conf.Addr != "" {
+		r := gin.New()
+		// 独立调试端口不采信转发头，AllowIPs 始终按直连地址判断
+		if err := r.SetTrustedProxies(nil); err != nil {
+			return fmt.Errorf("failed to set pprof trusted proxies: %w", err)
+		}
+		r.Use(middleware.Recovery(e.logger))
+		router = r
+		e.AddServer("pprof", conf.Addr, &http.Server{Handler: r})
+	}
+	registerPprof(router.Group(prefix, handlers...))
+	return nil
+}
+
+// defaultPprofAllowIPs 未配置访问控制时 pprof 允许访问的地址
+var defaultPprofAllowIPs = []string{"127.0.0.1", "::1"}
+
+// adminProtected 判断 pprof 是否挂载在已配置访问控制的管理端口上
+func (e *Engine) adminProtected(router gin.IRouter, addr string) bool {
+	admin := e.options.Admin
+	return addr == "" && e.admin != nil && router == gin.IRouter(e.admin) &&
+		admin != nil && (len(admin.AllowIPs) > 0 || len(admin.Users) > 0)
+}
+
+func registerPprof(g gin.IRouter) {
+	g.GET("/vars", gin.WrapH(expvar.Handler()))
+	g.GET("/pprof/", gin.WrapF(pprof.Index))
+	g.GET("/pprof/:name", pprofHandler)
+	g.POST("/pprof/:name", pprofHandler)
+}
+
+// pprofHandler 按名称分发，不依赖 pprof.Index 对 /debug/pprof/ 前缀的假设
+func pprofHandler(c *gin.Context) {
+	switch name := c.Param("name"); name {
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,62 @@
+package ginx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gaoxin19/ginx/config"
+)
+
+// newTestEngine 创建关闭控制台日志的测试引擎
+func newTestEngine(t *testing.T, mutate func(*config.Options)) *Engine {
+	t.Helper()
+	opts := config.DefaultOptions()
+	opts.Logger.Console = false
+	if mutate != nil {
+		mutate(opts)
+	}
+	e, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return e
+}
+
+func serveFrom(h http.Handler, method, target, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestPprofDefaultsToLoopback(t *testing.T) {
+	e := newTestEngine(t, func(o *config.Options) { o.EnablePprof = true })
+
+	if w := serveFrom(e, http.MethodGet, "/debug/pprof/", "203.0.113.5:4000"); w.Code != http.StatusForbidden {
+		t.Fatalf("remote status = %d, want 403", w.Code)
+	}
+	if w := serveFrom(e, http.MethodGet, "/debug/pprof/", "127.0.0.1:4000"); w.Code != http.StatusOK {
+		t.Fatalf("loopback status = %d, want 200", w.Code)
+	}
+}
+
+func TestPprofAllowIPsIgnoreForwardedFor(t *testing.T) {
+	e := newTestEngine(t, func(o *config.Options) {
+		o.EnablePprof = true
+		o.Pprof = &config.PprofOptions{AllowIPs: []string{"10.0.0.0/8"}}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+	req.RemoteAddr = "203.0.113.5:4000"
+	req.Header.Set("X-Forwarded-For", "10.1.2.3")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("spoofed status = %d, want 403", w.Code)
+	}
+	if w := serveFrom(e, http.MethodGet, "/debug/pprof/", "10.1.2.3:4000"); w.Code != http.StatusOK {
+		t.Fatalf("allowed status = %d, want 200", w.Code)
+	}
+}
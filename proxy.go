@@ -0,0 +1,268 @@
+package ginx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/middleware"
+)
+
+// ProxyOptions 反向代理配置
+type ProxyOptions struct {
+	// Targets 额外的上游地址，与 target 一起轮询
+	Targets []string
+	// Rewrite 改写转发路径，默认去掉挂载路径前缀
+	Rewrite func(path string) string
+	// RequestHeaders 转发前设置的请求头，值为空时删除该请求头
+	RequestHeaders map[string]string
+	// ResponseHeaders 返回前设置的响应头，值为空时删除该响应头
+	ResponseHeaders map[string]string
+	// Retries 连接上游失败时改用其它上游重试的次数，仅对无请求体或可重放请求体的请求生效
+	Retries int
+	// HealthCheckPath 上游健康检查路径，为空时不检查；不健康的上游不参与轮询
+	HealthCheckPath string
+	// HealthCheckInterval 健康检查间隔，默认 10s
+	HealthCheckInterval time.Duration
+	// Transport 转发使用的传输，默认 http.DefaultTransport
+	Transport http.RoundTripper
+	// Handlers 仅作用于该代理路由的中间件，在全局中间件之后执行
+	Handlers []gin.HandlerFunc
+}
+
+// Proxy 将 path 及其子路径的请求转发到上游，请求会经过引擎的全局中间件
+func (e *Engine) Proxy(path, target string, opts ProxyOptions) error {
+	pool, err := newUpstreamPool(append([]string{target}, opts.Targets...))
+	if err != nil {
+		return err
+	}
+
+	prefix := "/" + strings.Trim(path, "/")
+	if opts.Rewrite == nil {
+		opts.Rewrite = func(p string) string {
+			if prefix == "/" {
+				return p
+			}
+			return "/" + strings.TrimPrefix(strings.TrimPrefix(p, prefix), "/")
+		}
+	}
+	next := opts.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	rp := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.Out.URL.Path = opts.Rewrite(pr.In.URL.Path)
+			pr.Out.URL.RawPath = ""
+			pr.SetURL(pool.pick(nil).url)
+			pr.SetXForwarded()
+			if c := GinContext(pr.In.Context()); c != nil {
+				if id := middleware.GetRequestID(c); id != "" {
+					pr.Out.Header.Set(middleware.RequestIDHeader, id)
+				}
+			}
+			setHeaders(pr.Out.Header, opts.RequestHeaders)
+		},
+		Transport: &proxyTransport{pool: pool, next: next, retries: opts.Retries},
+		ModifyResponse: func(resp *http.Response) error {
+			setHeaders(resp.Header, opts.ResponseHeaders)
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			c := GinContext(r.Context())
+			if c == nil || errors.Is(err, context.Canceled) {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			WriteError(c, NewError(http.StatusBadGateway, "bad_gateway", "upstream unavailable").Wrap(err))
+		},
+	}
+
+	if opts.HealthCheckPath != "" {
+		e.watchUpstreams(pool, opts)
+	}
+
+	handler := func(c *gin.Context) {
+		rp.ServeHTTP(c.Writer, c.Request.WithContext(RequestContext(c)))
+	}
+	handlers := append(append([]gin.HandlerFunc{}, opts.Handlers...), handler)
+	if prefix == "/" {
+		e.NoRoute(handlers...)
+		return nil
+	}
+	e.Any(prefix, handlers...)
+	e.Any(prefix+"/*proxypath", handlers...)
+	return nil
+}
+
+// watchUpstreams 在服务启动后定期检查上游健康状态，关闭时停止
+func (e *Engine) watchUpstreams(pool *upstreamPool, opts ProxyOptions) {
+	interval := opts.HealthCheckInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &http.Client{Timeout: interval / 2, Transport: opts.Transport}
+
+	e.RegisterOnStart("proxy-health-check", func(context.Context) error {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				for _, up := range pool.upstreams {
+					healthy := checkUpstream(ctx, client, up.url.JoinPath(opts.HealthCheckPath).String())
+					if up.healthy.Swap(healthy) != healthy {
+						e.logger.Warn("Upstream health changed",
+							zap.String("upstream", up.url.String()),
+							zap.Bool("healthy", healthy),
+						)
+					}
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+		return nil
+	})
+	e.RegisterOnShutdown("proxy-health-check", func(context.Context) error {
+		cancel()
+		return nil
+	})
+}
+
+func checkUpstream(ctx context.Context, client *http.Client, url string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+func setHeaders(h http.Header, values map[string]string) {
+	for k, v := range values {
+		if v == "" {
+			h.Del(k)
+			continue
+		}
+		h.Set(k, v)
+	}
+}
+
+type upstream struct {
+	url     *url.URL
+	healthy atomic.Bool
+}
+
+// upstreamPool 在健康的上游间轮询，全部不健康时退化为在所有上游间轮询
+type upstreamPool struct {
+	upstreams []*upstream
+	next      atomic.Uint64
+}
+
+func newUpstreamPool(targets []string) (*upstreamPool, error) {
+	pool := &upstreamPool{}
+	for _, t := range targets {
+		u, err := url.Parse(t)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy target %q", t)
+		}
+		up := &upstream{url: u}
+		up.healthy.Store(true)
+		pool.upstreams = append(pool.upstreams, up)
+	}
+	return pool, nil
+}
+
+// pick 轮询选择上游，exclude 为已尝试失败的主机
+func (p *upstreamPool) pick(exclude map[string]bool) *upstream {
+	n := uint64(len(p.upstreams))
+	start := p.next.Add(1)
+	var fallback *upstream
+	for i := range n {
+		up := p.upstreams[(start+i)%n]
+		if exclude[up.url.Host] {
+			continue
+		}
+		if up.healthy.Load() {
+			return up
+		}
+		if fallback == nil {
+			fallback = up
+		}
+	}
+	if fallback == nil {
+		return p.upstreams[start%n]
+	}
+	return fallback
+}
+
+func (p *upstreamPool) byHost(host string) *upstream {
+	for _, up := range p.upstreams {
+		if up.url.Host == host {
+			return up
+		}
+	}
+	return nil
+}
+
+// proxyTransport 连接上游失败时切换到其它上游重试
+type proxyTransport struct {
+	pool    *upstreamPool
+	next    http.RoundTripper
+	retries int
+}
+
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tried := map[string]bool{}
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if err == nil || attempt >= t.retries || !isDialError(err) || !replayable(req) {
+			return resp, err
+		}
+
+		failed := t.pool.byHost(req.URL.Host)
+		tried[req.URL.Host] = true
+		up := t.pool.pick(tried)
+		if tried[up.url.Host] {
+			return nil, err
+		}
+
+		// 替换为新上游的地址与基础路径
+		path := req.URL.Path
+		if failed != nil {
+			path = strings.TrimPrefix(path, strings.TrimSuffix(failed.url.Path, "/"))
+		}
+		req = req.Clone(req.Context())
+		req.URL.Scheme, req.URL.Host = up.url.Scheme, up.url.Host
+		req.URL.Path, req.URL.RawPath = up.url.JoinPath(path).Path, ""
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
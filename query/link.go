@@ -0,0 +1,85 @@
+package query
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Result 分页响应数据
+type Result struct {
+	Items      any    `json:"items"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Total      int64  `json:"total,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewResult 创建页码分页结果
+func NewResult(q *Query, items any, total int64) Result {
+	return Result{Items: items, Page: q.Page, Limit: q.Limit, Total: total}
+}
+
+// NewCursorResult 创建游标分页结果，next 为空表示没有下一页
+func NewCursorResult(q *Query, items any, next string) Result {
+	return Result{Items: items, Limit: q.Limit, NextCursor: next}
+}
+
+// Link 按 RFC 8288 生成页码分页的 Link 头，包含 first、prev、next、last
+func Link(u *url.URL, q *Query, total int64) string {
+	var links []string
+	last := 1
+	if q.Limit > 0 && total > 0 {
+		last = int((total + int64(q.Limit) - 1) / int64(q.Limit))
+	}
+
+	links = append(links, pageLink(u, 1, q.Limit, "first"))
+	if q.Page > 1 {
+		links = append(links, pageLink(u, min(q.Page-1, last), q.Limit, "prev"))
+	}
+	if q.Page < last {
+		links = append(links, pageLink(u, q.Page+1, q.Limit, "next"))
+	}
+	links = append(links, pageLink(u, last, q.Limit, "last"))
+	return strings.Join(links, ", ")
+}
+
+// CursorLink 生成游标分页的 Link 头，next 为空时返回空字符串
+func CursorLink(u *url.URL, q *Query, next string) string {
+	if next == "" {
+		return ""
+	}
+	v := u.Query()
+	v.Del("page")
+	v.Set("cursor", next)
+	v.Set("limit", strconv.Itoa(q.Limit))
+	return formatLink(u, v, "next")
+}
+
+// SetLink 为页码分页响应设置 Link 与 X-Total-Count 头
+func SetLink(c *gin.Context, q *Query, total int64) {
+	c.Header("Link", Link(c.Request.URL, q, total))
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+}
+
+// SetCursorLink 为游标分页响应设置 Link 头
+func SetCursorLink(c *gin.Context, q *Query, next string) {
+	if link := CursorLink(c.Request.URL, q, next); link != "" {
+		c.Header("Link", link)
+	}
+}
+
+func pageLink(u *url.URL, page, limit int, rel string) string {
+	v := u.Query()
+	v.Del("cursor")
+	v.Set("page", strconv.Itoa(page))
+	v.Set("limit", strconv.Itoa(limit))
+	return formatLink(u, v, rel)
+}
+
+func formatLink(u *url.URL, v url.Values, rel string) string {
+	ref := url.URL{Path: u.Path, RawQuery: v.Encode()}
+	return `<` + ref.String() + `>; rel="` + rel + `"`
+}
@@ -0,0 +1,195 @@
+// Package query 解析查询字符串中的分页、排序与过滤参数
+package query
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrInvalid 查询参数不合法，所有解析错误都包装该错误
+var ErrInvalid = errors.New("invalid query")
+
+// 默认分页大小
+const (
+	DefaultLimit    = 20
+	DefaultMaxLimit = 100
+)
+
+// 过滤操作符
+const (
+	OpEq   = "eq"
+	OpNe   = "ne"
+	OpGt   = "gt"
+	OpGte  = "gte"
+	OpLt   = "lt"
+	OpLte  = "lte"
+	OpLike = "like"
+	OpIn   = "in"
+)
+
+var ops = []string{OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpLike, OpIn}
+
+// Options 解析配置
+type Options struct {
+	// DefaultLimit 未指定 limit 时的分页大小，默认 20
+	DefaultLimit int
+	// MaxLimit 允许的最大分页大小，默认 100
+	MaxLimit int
+	// SortFields 允许排序的字段，为空时不允许排序
+	SortFields []string
+	// FilterFields 允许过滤的字段，为空时不允许过滤
+	FilterFields []string
+	// DefaultSort 未指定 sort 时的排序
+	DefaultSort []Sort
+}
+
+// Sort 排序条件
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+func (s Sort) String() string {
+	if s.Desc {
+		return s.Field + ":desc"
+	}
+	return s.Field + ":asc"
+}
+
+// Filter 过滤条件，in 操作符的多个值以逗号分隔后存于 Values
+type Filter struct {
+	Field  string
+	Op     string
+	Values []string
+}
+
+// Value 返回第一个值
+func (f Filter) Value() string {
+	if len(f.Values) == 0 {
+		return ""
+	}
+	return f.Values[0]
+}
+
+// Query 解析后的查询参数
+type Query struct {
+	// Page 页码，从 1 开始；使用游标分页时为 0
+	Page  int
+	Limit int
+	// Cursor 游标分页的起点，不为空时忽略 Page
+	Cursor  string
+	Sort    []Sort
+	Filters []Filter
+}
+
+// Offset 返回页码分页的偏移量
+func (q *Query) Offset() int {
+	if q.Page <= 1 {
+		return 0
+	}
+	return (q.Page - 1) * q.Limit
+}
+
+// Parse 从请求中解析查询参数，格式为
+//
+//	?page=2&limit=20
+//	?cursor=abc&limit=20
+//	?sort=created_at:desc,name
+//	?filter=status:eq:active&filter=age:gte:18&filter=role:in:admin,editor
+//
+// 参数不合法时返回包装 ErrInvalid 的错误
+func Parse(c *gin.Context, opts Options) (*Query, error) {
+	if opts.DefaultLimit <= 0 {
+		opts.DefaultLimit = DefaultLimit
+	}
+	if opts.MaxLimit <= 0 {
+		opts.MaxLimit = DefaultMaxLimit
+	}
+
+	q := &Query{Limit: opts.DefaultLimit, Cursor: c.Query("cursor")}
+
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("%w: limit must be a positive integer", ErrInvalid)
+		}
+		q.Limit = min(n, opts.MaxLimit)
+	}
+
+	if q.Cursor == "" {
+		q.Page = 1
+		if v := c.Query("page"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("%w: page must be a positive integer", ErrInvalid)
+			}
+			q.Page = n
+		}
+	}
+
+	sorts, err := parseSort(c.Query("sort"), opts.SortFields)
+	if err != nil {
+		return nil, err
+	}
+	if len(sorts) == 0 {
+		sorts = opts.DefaultSort
+	}
+	q.Sort = sorts
+
+	for _, v := range c.QueryArray("filter") {
+		f, err := parseFilter(v, opts.FilterFields)
+		if err != nil {
+			return nil, err
+		}
+		q.Filters = append(q.Filters, f)
+	}
+
+	return q, nil
+}
+
+func parseSort(s string, allowed []string) ([]Sort, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var sorts []Sort
+	for _, part := range strings.Split(s, ",") {
+		field, dir, _ := strings.Cut(strings.TrimSpace(part), ":")
+		if !slices.Contains(allowed, field) {
+			return nil, fmt.Errorf("%w: cannot sort by %q", ErrInvalid, field)
+		}
+		switch strings.ToLower(dir) {
+		case "", "asc":
+			sorts = append(sorts, Sort{Field: field})
+		case "desc":
+			sorts = append(sorts, Sort{Field: field, Desc: true})
+		default:
+			return nil, fmt.Errorf("%w: invalid sort direction %q", ErrInvalid, dir)
+		}
+	}
+	return sorts, nil
+}
+
+func parseFilter(s string, allowed []string) (Filter, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 {
+		return Filter{}, fmt.Errorf("%w: filter must be field:op:value", ErrInvalid)
+	}
+	field, op, value := parts[0], strings.ToLower(parts[1]), parts[2]
+	if !slices.Contains(allowed, field) {
+		return Filter{}, fmt.Errorf("%w: cannot filter by %q", ErrInvalid, field)
+	}
+	if !slices.Contains(ops, op) {
+		return Filter{}, fmt.Errorf("%w: unsupported filter operator %q", ErrInvalid, op)
+	}
+
+	values := []string{value}
+	if op == OpIn {
+		values = strings.Split(value, ",")
+	}
+	return Filter{Field: field, Op: op, Values: values}, nil
+}
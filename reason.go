@@ -0,0 +1,103 @@
+package ginx
+
+import (
+	"errors"
+)
+
+// ShutdownReason 服务停止的原因
+type ShutdownReason int
+
+const (
+	// ReasonNone 服务尚未停止
+	ReasonNone ShutdownReason = iota
+	// ReasonSignal 收到关闭信号、ctx 被取消或调用了 Shutdown
+	ReasonSignal
+	// ReasonUpgrade 升级完成，由新进程接管
+	ReasonUpgrade
+	// ReasonListenerError 创建监听器失败或服务运行中出错
+	ReasonListenerError
+	// ReasonHookFailure 启动阶段失败：启动钩子返回错误或就绪通知失败
+	ReasonHookFailure
+)
+
+func (r ShutdownReason) String() string {
+	switch r {
+	case ReasonSignal:
+		return "signal"
+	case ReasonUpgrade:
+		return "upgrade"
+	case ReasonListenerError:
+		return "listener_error"
+	case ReasonHookFailure:
+		return "hook_failure"
+	default:
+		return "none"
+	}
+}
+
+// 进程退出码，可配合 systemd 的 SuccessExitStatus、RestartPreventExitStatus 区分正常升级与崩溃
+const (
+	// ExitOK 正常关闭或升级完成
+	ExitOK = 0
+	// ExitFailure 其他错误，如配置错误
+	ExitFailure = 1
+	// ExitListenerError 监听失败或服务运行中出错
+	ExitListenerError = 3
+	// ExitHookFailure 启动阶段失败
+	ExitHookFailure = 4
+	// ExitUncleanShutdown 因信号或升级停止，但排空或关闭钩子出错
+	ExitUncleanShutdown = 5
+)
+
+// ShutdownError Run、RunContext、GracefulRun 与 GracefulServe 非正常结束时返回的错误
+type ShutdownError struct {
+	Reason ShutdownReason
+	Err    error
+}
+
+func (e *ShutdownError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode 返回 Run 等方法的结果对应的进程退出码，用法：os.Exit(ginx.ExitCode(e.Run()))
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var se *ShutdownError
+	if !errors.As(err, &se) {
+		return ExitFailure
+	}
+	switch se.Reason {
+	case ReasonListenerError:
+		return ExitListenerError
+	case ReasonHookFailure:
+		return ExitHookFailure
+	case ReasonSignal, ReasonUpgrade:
+		return ExitUncleanShutdown
+	default:
+		return ExitFailure
+	}
+}
+
+// ShutdownReason 返回最近一次运行停止的原因，运行中或尚未运行时为 ReasonNone
+func (e *Engine) ShutdownReason() ShutdownReason {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	return e.reason
+}
+
+// stopped 记录停止原因，err 不为空时包装为 ShutdownError
+func (e *Engine) stopped(reason ShutdownReason, err error) error {
+	e.hooksMu.Lock()
+	e.reason = reason
+	e.hooksMu.Unlock()
+	if err == nil {
+		return nil
+	}
+	return &ShutdownError{Reason: reason, Err: err}
+}
@@ -0,0 +1,166 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Mask 脱敏后的替换值
+const Mask = "***"
+
+// DefaultFields 默认脱敏字段
+var DefaultFields = []string{
+	"password", "passwd", "secret", "token", "access_token", "refresh_token",
+	"authorization", "api_key", "apikey", "credit_card",
+}
+
+// Redactor 按字段名与正则表达式脱敏
+type Redactor struct {
+	fields   map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+// New 创建脱敏器，fields 为大小写不敏感的字段名，patterns 匹配到的内容会被替换为 Mask
+func New(fields, patterns []string) (*Redactor, error) {
+	r := &Redactor{fields: make(map[string]struct{}, len(fields))}
+	for _, f := range fields {
+		r.fields[strings.ToLower(f)] = struct{}{}
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Empty 是否没有任何脱敏规则
+func (r *Redactor) Empty() bool {
+	return len(r.fields) == 0 && len(r.patterns) == 0
+}
+
+// IsSensitive 判断字段名是否需要脱敏
+func (r *Redactor) IsSensitive(key string) bool {
+	_, ok := r.fields[strings.ToLower(key)]
+	return ok
+}
+
+// String 对字符串应用正则脱敏
+func (r *Redactor) String(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, Mask)
+	}
+	return s
+}
+
+// Body 对 JSON 与表单内容中的敏感字段脱敏，其他内容仅应用正则脱敏
+func (r *Redactor) Body(contentType string, body []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasSuffix(mediaType, "json"):
+		var v any
+		if err := json.Unmarshal(body, &v); err == nil {
+			if out, err := json.Marshal(r.value(v)); err == nil {
+				return string(out)
+			}
+		}
+
+	case mediaType == "application/x-www-form-urlencoded":
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			for k, vs := range values {
+				if r.IsSensitive(k) {
+					values[k] = []string{Mask}
+					continue
+				}
+				for i := range vs {
+					vs[i] = r.String(vs[i])
+				}
+			}
+			return values.Encode()
+		}
+	}
+	return r.String(string(body))
+}
+
+func (r *Redactor) value(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if r.IsSensitive(k) {
+				t[k] = Mask
+				continue
+			}
+			t[k] = r.value(val)
+		}
+	case []any:
+		for i := range t {
+			t[i] = r.value(t[i])
+		}
+	case string:
+		return r.String(t)
+	}
+	return v
+}
+
+// Fields 对 zap 字段脱敏：敏感字段名整体替换，字符串值应用正则
+func (r *Redactor) Fields(fields []zapcore.Field) []zapcore.Field {
+	out := fields
+	copied := false
+	for i, f := range fields {
+		var nf zapcore.Field
+		switch {
+		case r.IsSensitive(f.Key):
+			nf = zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: Mask}
+		case f.Type == zapcore.StringType && len(r.patterns) > 0:
+			masked := r.String(f.String)
+			if masked == f.String {
+				continue
+			}
+			nf = f
+			nf.String = masked
+		default:
+			continue
+		}
+		if !copied {
+			out = make([]zapcore.Field, len(fields))
+			copy(out, fields)
+			copied = true
+		}
+		out[i] = nf
+	}
+	return out
+}
+
+// Core 包装 zapcore.Core，使所有写入任意输出的日志在编码前完成脱敏
+func (r *Redactor) Core(core zapcore.Core) zapcore.Core {
+	return &redactCore{Core: core, r: r}
+}
+
+type redactCore struct {
+	zapcore.Core
+	r *Redactor
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.r.Fields(fields)), r: c.r}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.r.String(ent.Message)
+	return c.Core.Write(ent, c.r.Fields(fields))
+}
@@ -0,0 +1,89 @@
+package ginx
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/config"
+	"github.com/gaoxin19/ginx/middleware"
+	"github.com/gaoxin19/ginx/redisx"
+)
+
+// OpenRedis 按配置创建 Redis 客户端，命令日志带上请求 ID 与 trace id（需使用 RequestContext 作为命令的 context）
+func (e *Engine) OpenRedis(opts *config.RedisOptions) (*redisx.Client, error) {
+	conf := redisx.Config{
+		Name:             opts.Name,
+		Addrs:            opts.Addrs,
+		MasterName:       opts.MasterName,
+		Cluster:          opts.Cluster,
+		Username:         opts.Username,
+		Password:         opts.Password,
+		SentinelUsername: opts.SentinelUsername,
+		SentinelPassword: opts.SentinelPassword,
+		DB:               opts.DB,
+		PoolSize:         opts.PoolSize,
+		MinIdleConns:     opts.MinIdleConns,
+		DialTimeout:      opts.DialTimeout,
+		ReadTimeout:      opts.ReadTimeout,
+		WriteTimeout:     opts.WriteTimeout,
+	}
+	if t := opts.TLS; t != nil {
+		conf.TLS = &redisx.TLSConfig{
+			CAFile:             t.CAFile,
+			CertFile:           t.CertFile,
+			KeyFile:            t.KeyFile,
+			ServerName:         t.ServerName,
+			InsecureSkipVerify: t.InsecureSkipVerify,
+		}
+	}
+	return redisx.New(conf, redisx.Options{
+		Logger:        e.logger,
+		SlowThreshold: opts.SlowThreshold,
+		Fields:        requestFields,
+	})
+}
+
+// requestFields 从请求 context 中取出请求 ID 与 trace id 作为日志字段
+func requestFields(ctx context.Context) []zap.Field {
+	c := ginContextFrom(ctx)
+	if c == nil {
+		return nil
+	}
+	fields := []zap.Field{zap.String("request_id", middleware.GetRequestID(c))}
+	if id := middleware.TraceID(c); id != "" {
+		fields = append(fields, zap.String("trace_id", id))
+	}
+	return fields
+}
+
+// UseRedis 启动时 Ping Redis，不可用时终止启动；将其加入就绪检查，配置了 RateLimit 时限流改为由 Redis 存储，
+// 多个实例共享同一限额；关闭时在其他关闭钩子之后关闭连接池
+func (e *Engine) UseRedis(c *redisx.Client) {
+	name := "redis-" + c.Name()
+	e.RegisterOnStart(name, c.Check, WithPriority(dbStartPriority))
+	e.RegisterOnShutdown(name, c.Stop, WithPriority(dbShutdownPriority))
+	e.health.Register(name, c.Check)
+
+	e.reloadMu.Lock()
+	defer e.reloadMu.Unlock()
+	e.rateStore = c.RateLimitStore("")
+	e.rateLimit.set(e.buildRateLimit(e.opts()))
+}
+
+// ProvideRedis 注册按 Options.Redis 创建 *redisx.Client 的构造函数，创建后自动调用 UseRedis
+func (c *Container) ProvideRedis() error {
+	return c.Provide(func(e *Engine) (*redisx.Client, error) {
+		opts := e.opts().Redis
+		if opts == nil {
+			return nil, errors.New("redis is not configured")
+		}
+		client, err := e.OpenRedis(opts)
+		if err != nil {
+			return nil, err
+		}
+		e.UseRedis(client)
+		return client, nil
+	})
+}
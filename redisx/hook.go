@@ -0,0 +1,90 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/metrics"
+)
+
+// hook 记录命令耗时指标与慢命令日志，redis.Nil 不视为错误
+type hook struct {
+	name string
+	opts Options
+}
+
+func (h *hook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			h.opts.Logger.Warn("Failed to connect redis", h.fields(ctx, zap.String("addr", addr), zap.Error(err))...)
+		}
+		return conn, err
+	}
+}
+
+func (h *hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.observe(ctx, cmd.Name(), start, err, zap.Any("args", truncate(cmd.Args())))
+		return err
+	}
+}
+
+func (h *hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.observe(ctx, "pipeline", start, err, zap.Int("commands", len(cmds)))
+		return err
+	}
+}
+
+func (h *hook) observe(ctx context.Context, command string, start time.Time, err error, field zap.Field) {
+	elapsed := time.Since(start)
+	status := "ok"
+	// Script.Run 在脚本未缓存时先收到 NOSCRIPT 再回退到 EVAL
+	if err != nil && !errors.Is(err, redis.Nil) && !redis.HasErrorPrefix(err, "NOSCRIPT") {
+		status = "error"
+	}
+	metrics.RedisCommands.WithLabelValues(h.name, command, status).Observe(elapsed.Seconds())
+
+	switch {
+	case status == "error":
+		h.opts.Logger.Debug("Redis command failed", h.fields(ctx, zap.String("command", command), zap.Duration("latency", elapsed), field, zap.Error(err))...)
+	case h.opts.SlowThreshold > 0 && elapsed >= h.opts.SlowThreshold:
+		h.opts.Logger.Warn("Slow redis command", h.fields(ctx, zap.String("command", command), zap.Duration("latency", elapsed), field)...)
+	}
+}
+
+func (h *hook) fields(ctx context.Context, fields ...zap.Field) []zap.Field {
+	fields = append(fields, zap.String("client", h.name))
+	if h.opts.Fields != nil {
+		fields = append(fields, h.opts.Fields(ctx)...)
+	}
+	return fields
+}
+
+// maxLoggedArg 日志中单个参数的最大长度
+const maxLoggedArg = 64
+
+// truncate 日志中只保留命令名与第一个参数（通常为键），并截断过长的参数，避免记录大值或敏感数据
+func truncate(args []any) []any {
+	if len(args) > 2 {
+		args = args[:2]
+	}
+	out := make([]any, len(args))
+	for i, arg := range args {
+		if s, ok := arg.(string); ok && len(s) > maxLoggedArg {
+			arg = s[:maxLoggedArg] + "..."
+		}
+		out[i] = arg
+	}
+	return out
+}
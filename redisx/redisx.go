@@ -0,0 +1,223 @@
+// Package redisx 按配置创建 go-redis 客户端并管理其生命周期。
+//
+// 支持单机、Sentinel（设置 MasterName）与 Cluster（设置 Cluster）三种部署，可启用 TLS；
+// 客户端内置记录 ginx_redis_* 指标与慢命令日志的钩子，Options.Fields 可为日志补充请求 ID、trace 等上下文字段，
+// 也可通过 Options.Hooks 接入 OpenTelemetry 等外部追踪钩子。
+// 通过 engine.UseRedis(client) 接入 ginx：启动时 Ping、加入就绪检查、为限流切换为分布式存储，关闭时关闭连接池。
+package redisx
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/middleware"
+	"github.com/gaoxin19/ginx/session"
+)
+
+// DefaultPingTimeout Ping 的默认超时
+const DefaultPingTimeout = 5 * time.Second
+
+// Config 客户端配置
+type Config struct {
+	// Name 客户端名称，用于指标、日志与健康检查，默认 default
+	Name string
+	// Addrs 节点地址，单机与 Cluster 为 Redis 地址，Sentinel 为哨兵地址
+	Addrs []string
+	// MasterName 不为空时通过 Sentinel 连接该主节点
+	MasterName string
+	// Cluster 使用 Cluster 客户端
+	Cluster  bool
+	Username string
+	Password string
+	// SentinelUsername 与 SentinelPassword 哨兵的认证信息
+	SentinelUsername string
+	SentinelPassword string
+	// DB 数据库编号，Cluster 下不支持
+	DB           int
+	PoolSize     int
+	MinIdleConns int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// TLS 为 nil 时不启用
+	TLS *TLSConfig
+}
+
+// TLSConfig TLS 配置
+type TLSConfig struct {
+	// CAFile 为空时使用系统根证书
+	CAFile string
+	// CertFile 与 KeyFile 启用双向认证时使用
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// Options 客户端选项
+type Options struct {
+	// Logger 为空时不记录日志
+	Logger *zap.Logger
+	// SlowThreshold 超过该耗时的命令记录 Warn 日志，为 0 时不记录
+	SlowThreshold time.Duration
+	// PingTimeout Ping 的超时，默认 5s
+	PingTimeout time.Duration
+	// Fields 从命令的 ctx 中提取日志字段，如请求 ID 与 trace id
+	Fields func(ctx context.Context) []zap.Field
+	// Hooks 附加的 go-redis 钩子，如 redisotel 的追踪钩子
+	Hooks []redis.Hook
+}
+
+// Client 带生命周期管理的 Redis 客户端，可直接传给 session.NewRedisStore、middleware.NewRedisCacheStore 等
+type Client struct {
+	redis.UniversalClient
+	name string
+	opts Options
+}
+
+// New 按配置创建客户端，不会建立连接，需调用 Check 确认 Redis 可用
+func New(conf Config, opts Options) (*Client, error) {
+	if conf.Name == "" {
+		conf.Name = "default"
+	}
+	if len(conf.Addrs) == 0 {
+		return nil, errors.New("redis addrs is required")
+	}
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	if opts.PingTimeout <= 0 {
+		opts.PingTimeout = DefaultPingTimeout
+	}
+
+	var tlsConf *tls.Config
+	if conf.TLS != nil {
+		var err error
+		if tlsConf, err = conf.TLS.build(); err != nil {
+			return nil, fmt.Errorf("failed to build redis tls config: %w", err)
+		}
+	}
+
+	var client redis.UniversalClient
+	switch {
+	case conf.Cluster:
+		client = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        conf.Addrs,
+			Username:     conf.Username,
+			Password:     conf.Password,
+			PoolSize:     conf.PoolSize,
+			MinIdleConns: conf.MinIdleConns,
+			DialTimeout:  conf.DialTimeout,
+			ReadTimeout:  conf.ReadTimeout,
+			WriteTimeout: conf.WriteTimeout,
+			TLSConfig:    tlsConf,
+		})
+	case conf.MasterName != "":
+		client = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       conf.MasterName,
+			SentinelAddrs:    conf.Addrs,
+			SentinelUsername: conf.SentinelUsername,
+			SentinelPassword: conf.SentinelPassword,
+			Username:         conf.Username,
+			Password:         conf.Password,
+			DB:               conf.DB,
+			PoolSize:         conf.PoolSize,
+			MinIdleConns:     conf.MinIdleConns,
+			DialTimeout:      conf.DialTimeout,
+			ReadTimeout:      conf.ReadTimeout,
+			WriteTimeout:     conf.WriteTimeout,
+			TLSConfig:        tlsConf,
+		})
+	default:
+		client = redis.NewClient(&redis.Options{
+			Addr:         conf.Addrs[0],
+			Username:     conf.Username,
+			Password:     conf.Password,
+			DB:           conf.DB,
+			PoolSize:     conf.PoolSize,
+			MinIdleConns: conf.MinIdleConns,
+			DialTimeout:  conf.DialTimeout,
+			ReadTimeout:  conf.ReadTimeout,
+			WriteTimeout: conf.WriteTimeout,
+			TLSConfig:    tlsConf,
+		})
+	}
+
+	client.AddHook(&hook{name: conf.Name, opts: opts})
+	for _, h := range opts.Hooks {
+		client.AddHook(h)
+	}
+	return &Client{UniversalClient: client, name: conf.Name, opts: opts}, nil
+}
+
+// Name 返回客户端名称
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Check 在 PingTimeout 内确认 Redis 可用，用于启动钩子与就绪检查
+func (c *Client) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.PingTimeout)
+	defer cancel()
+	if err := c.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping redis %s: %w", c.name, err)
+	}
+	return nil
+}
+
+// Stop 关闭连接池，用于关闭钩子（Shutdown 为 Redis 的 SHUTDOWN 命令）
+func (c *Client) Stop(context.Context) error {
+	if err := c.Close(); err != nil && !errors.Is(err, redis.ErrClosed) {
+		return fmt.Errorf("failed to close redis %s: %w", c.name, err)
+	}
+	return nil
+}
+
+// RateLimitStore 返回基于该客户端的分布式限流存储，prefix 为空时为 ginx:ratelimit:
+func (c *Client) RateLimitStore(prefix string) *middleware.RedisStore {
+	return middleware.NewRedisStore(c, prefix)
+}
+
+// CacheStore 返回基于该客户端的响应缓存存储，prefix 为空时为 ginx:cache:
+func (c *Client) CacheStore(prefix string) *middleware.RedisCacheStore {
+	return middleware.NewRedisCacheStore(c, prefix)
+}
+
+// SessionStore 返回基于该客户端的会话存储，prefix 为空时为 ginx:session:
+func (c *Client) SessionStore(prefix string) *session.RedisStore {
+	return session.NewRedisStore(c, prefix)
+}
+
+func (t *TLSConfig) build() (*tls.Config, error) {
+	conf := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.RootCAs = x509.NewCertPool()
+		if !conf.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", t.CAFile)
+		}
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
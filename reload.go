@@ -0,0 +1,181 @@
+package ginx
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/config"
+)
+
+// swapHandler 可在运行时替换的中间件，为空时直接放行
+type swapHandler struct {
+	h atomic.Pointer[gin.HandlerFunc]
+}
+
+func (s *swapHandler) set(h gin.HandlerFunc) {
+	if h == nil {
+		s.h.Store(nil)
+		return
+	}
+	s.h.Store(&h)
+}
+
+func (s *swapHandler) handle(c *gin.Context) {
+	if h := s.h.Load(); h != nil {
+		(*h)(c)
+		return
+	}
+	c.Next()
+}
+
+// reloadable 可热加载的配置项，其余配置项变更后需重启（或平滑升级）才能生效
+var reloadable = map[string]bool{
+	"TrustedProxies":      true, // 仅对 middleware.ClientIP 生效，c.ClientIP() 仍使用启动时的配置
+	"CORS":                true,
+	"RateLimit":           true,
+	"ShutdownTimeout":     true,
+	"ShutdownHookTimeout": true,
+	"DrainDelay":          true,
+	"Drain":               true,
+}
+
+// opts 返回当前生效的配置
+func (e *Engine) opts() *config.Options {
+	e.optsMu.RLock()
+	defer e.optsMu.RUnlock()
+	return e.options
+}
+
+// WatchConfig 在服务运行期间监听配置文件，文件变化时重新读取并应用可热加载的配置：
+// 日志级别、限流、CORS、可信代理与关闭相关的超时，其余变更的配置项记录为需重启
+func (e *Engine) WatchConfig(path string) {
+	e.WatchProvider(config.File(path))
+}
+
+// WatchProvider 在服务运行期间监听配置来源（如 etcd、Consul、Nacos、Apollo），
+// 配置变化时按 WatchConfig 的规则应用
+func (e *Engine) WatchProvider(p config.Provider) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	e.RegisterOnStart("config-watcher", func(context.Context) error {
+		go func() {
+			err := p.Watch(ctx, func(data []byte) {
+				next := config.ProfileDefaults(e.opts().Profile)
+				if err := config.Parse(data, next); err != nil {
+					e.logger.Error("Failed to parse config", zap.Error(err))
+					return
+				}
+				if err := config.ResolveSecrets(ctx, next); err != nil {
+					e.logger.Error("Failed to resolve config secrets", zap.Error(err))
+					return
+				}
+				if err := e.ApplyConfig(next); err != nil {
+					e.logger.Error("Failed to apply config", zap.Error(err))
+				}
+			})
+			if err != nil {
+				e.logger.Error("Config watcher stopped", zap.Error(err))
+			}
+		}()
+		return nil
+	})
+	e.RegisterOnShutdown("config-watcher", func(context.Context) error {
+		cancel()
+		return nil
+	})
+}
+
+// ReloadConfig 重新读取配置文件并应用可热加载的配置
+func (e *Engine) ReloadConfig(path string) error {
+	next, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	return e.ApplyConfig(next)
+}
+
+// ApplyConfig 应用 next 中可热加载的配置，并记录变更的配置项；
+// 任一配置项无效时不做任何修改
+func (e *Engine) ApplyConfig(next *config.Options) error {
+	if err := next.Validate(); err != nil {
+		return err
+	}
+
+	e.reloadMu.Lock()
+	defer e.reloadMu.Unlock()
+	cur := e.opts()
+
+	realIP, err := buildRealIP(next)
+	if err != nil {
+		return err
+	}
+
+	// 需重启的配置项保留当前值，使后续变更仍能被检测到
+	merged := *cur
+	mv, cv, nv := reflect.ValueOf(&merged).Elem(), reflect.ValueOf(cur).Elem(), reflect.ValueOf(next).Elem()
+	var changed, restart []string
+	var level *zap.AtomicLevel
+	for i := 0; i < cv.NumField(); i++ {
+		if !cv.Type().Field(i).IsExported() {
+			continue
+		}
+		name := cv.Type().Field(i).Name
+		if reflect.DeepEqual(cv.Field(i).Interface(), nv.Field(i).Interface()) {
+			continue
+		}
+		switch {
+		case name == "Logger":
+			// 日志配置只有 Level 可热加载
+			if cur.Logger == nil || next.Logger == nil {
+				restart = append(restart, name)
+				continue
+			}
+			if cur.Logger.Level != next.Logger.Level {
+				l, err := zap.ParseAtomicLevel(next.Logger.Level)
+				if err != nil {
+					return fmt.Errorf("invalid log level: %w", err)
+				}
+				level = &l
+				logger := *cur.Logger
+				logger.Level = next.Logger.Level
+				merged.Logger = &logger
+				changed = append(changed, "Logger.Level")
+			}
+			a, b := *cur.Logger, *next.Logger
+			a.Level, b.Level = "", ""
+			if !reflect.DeepEqual(a, b) {
+				restart = append(restart, name)
+			}
+		case reloadable[name]:
+			mv.Field(i).Set(nv.Field(i))
+			changed = append(changed, name)
+		default:
+			restart = append(restart, name)
+		}
+	}
+
+	if level != nil {
+		e.logLevel.SetLevel(level.Level())
+	}
+	merged.MergeSecrets(next)
+	e.realIP.set(realIP)
+	e.cors.set(buildCORS(&merged))
+	e.rateLimit.set(e.buildRateLimit(&merged))
+
+	e.optsMu.Lock()
+	e.options = &merged
+	e.optsMu.Unlock()
+
+	if len(changed) > 0 {
+		e.logger.Info("Config reloaded", zap.Strings("changed", changed))
+	}
+	if len(restart) > 0 {
+		e.logger.Warn("Config changes require a restart to take effect", zap.Strings("fields", restart))
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package ginx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+)
+
+// 内置支持的内容类型
+const (
+	MIMEJSON     = "application/json"
+	MIMEXML      = "application/xml"
+	MIMEMsgPack  = "application/msgpack"
+	MIMEProtobuf = "application/x-protobuf"
+)
+
+// Marshaler 将数据序列化为指定内容类型，无法处理该数据时返回 errors.ErrUnsupported 以尝试下一个候选类型
+type Marshaler func(v any) ([]byte, error)
+
+type marshalerEntry struct {
+	contentType string
+	aliases     []string
+	marshal     Marshaler
+}
+
+var (
+	marshalersMu sync.RWMutex
+	// marshalers 按注册顺序排列，第一个为客户端未指定 Accept 时的默认类型
+	marshalers = []*marshalerEntry{
+		{contentType: MIMEJSON, marshal: marshalJSON},
+		{contentType: MIMEXML, aliases: []string{"text/xml"}, marshal: marshalXML},
+		{contentType: MIMEMsgPack, aliases: []string{"application/x-msgpack"}, marshal: marshalMsgPack},
+		{contentType: MIMEProtobuf, aliases: []string{"application/protobuf"}, marshal: marshalProtobuf},
+	}
+)
+
+// RegisterMarshaler 注册或替换内容类型的序列化方法，aliases 为同样可匹配的其它类型
+func RegisterMarshaler(contentType string, m Marshaler, aliases ...string) {
+	marshalersMu.Lock()
+	defer marshalersMu.Unlock()
+	for _, e := range marshalers {
+		if e.contentType == contentType {
+			e.marshal = m
+			e.aliases = append(e.aliases, aliases...)
+			return
+		}
+	}
+	marshalers = append(marshalers, &marshalerEntry{contentType: contentType, aliases: aliases, marshal: m})
+}
+
+// Render 按 Accept 头协商内容类型并写出数据，没有可接受的类型时使用 JSON
+func Render(c *gin.Context, status int, data any) {
+	c.Header("Vary", "Accept")
+	for _, e := range negotiate(c.GetHeader("Accept")) {
+		body, err := e.marshal(data)
+		if errors.Is(err, errors.ErrUnsupported) {
+			continue
+		}
+		if err != nil {
+			WriteError(c, fmt.Errorf("failed to marshal %s response: %w", e.contentType, err))
+			return
+		}
+		c.Data(status, e.contentType+charset(e.contentType), body)
+		return
+	}
+
+	body, err := marshalJSON(data)
+	if err != nil {
+		WriteError(c, fmt.Errorf("failed to marshal %s response: %w", MIMEJSON, err))
+		return
+	}
+	c.Data(status, MIMEJSON+charset(MIMEJSON), body)
+}
+
+// negotiate 返回按客户端偏好排序的候选序列化方法
+func negotiate(accept string) []*marshalerEntry {
+	marshalersMu.RLock()
+	defer marshalersMu.RUnlock()
+	if accept == "" {
+		return marshalers[:1]
+	}
+
+	type candidate struct {
+		entry *marshalerEntry
+		q     float64
+		order int
+	}
+	var candidates []candidate
+	for i, e := range marshalers {
+		if q := acceptQuality(accept, e); q > 0 {
+			candidates = append(candidates, candidate{entry: e, q: q, order: i})
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return a.order - b.order
+	})
+
+	entries := make([]*marshalerEntry, 0, len(candidates))
+	for _, c := range candidates {
+		entries = append(entries, c.entry)
+	}
+	return entries
+}
+
+// acceptQuality 返回 Accept 头中与内容类型最精确匹配项的 q 值
+func acceptQuality(accept string, e *marshalerEntry) float64 {
+	best, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		s := matchMediaType(mediaType, e)
+		if s < 0 || s < specificity {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if s > specificity || q > best {
+			best, specificity = q, s
+		}
+	}
+	return best
+}
+
+// matchMediaType 返回匹配精确度：2 完全匹配，1 子类型通配，0 全通配，-1 不匹配
+func matchMediaType(mediaType string, e *marshalerEntry) int {
+	if mediaType == "*/*" {
+		return 0
+	}
+	for _, ct := range append([]string{e.contentType}, e.aliases...) {
+		if mediaType == ct {
+			return 2
+		}
+		if typ, sub, _ := strings.Cut(mediaType, "/"); sub == "*" && strings.HasPrefix(ct, typ+"/") {
+			return 1
+		}
+	}
+	return -1
+}
+
+func charset(contentType string) string {
+	if strings.HasPrefix(contentType, "text/") || contentType == MIMEJSON || contentType == MIMEXML {
+		return "; charset=utf-8"
+	}
+	return ""
+}
+
+func marshalXML(v any) ([]byte, error) {
+	b, err := xml.Marshal(v)
+	var unsupported *xml.UnsupportedTypeError
+	if errors.As(err, &unsupported) {
+		return nil, fmt.Errorf("%w: %w", errors.ErrUnsupported, err)
+	}
+	return b, err
+}
+
+var msgpackHandle = &codec.MsgpackHandle{}
+
+func marshalMsgPack(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := codec.NewEncoder(&buf, msgpackHandle).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func marshalProtobuf(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	return proto.Marshal(m)
+}
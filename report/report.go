@@ -0,0 +1,76 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gaoxin19/ginx/middleware"
+)
+
+// Event 一次需要上报的错误
+type Event struct {
+	// Err 错误，panic 时为 panic 值的包装
+	Err error
+	// Panic 是否由 panic 触发
+	Panic bool
+	// Stack panic 时的调用栈
+	Stack     middleware.Frames
+	Request   *http.Request
+	Status    int
+	Route     string
+	RequestID string
+	ClientIP  string
+}
+
+// ErrorReporter 错误上报接口，Sentry 等服务的适配器需实现该接口
+type ErrorReporter interface {
+	Report(ctx context.Context, event Event)
+	// Flush 在进程退出前发送所有缓冲中的事件
+	Flush(ctx context.Context) error
+}
+
+// Middleware 返回一个在响应为 5xx 时上报错误的中间件，错误信息取自 c.Errors
+func Middleware(r ErrorReporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusInternalServerError {
+			return
+		}
+		err := c.Errors.Last()
+		var reportErr error = fmt.Errorf("%s %s responded %d", c.Request.Method, c.FullPath(), status)
+		if err != nil {
+			reportErr = err.Err
+		}
+		r.Report(c.Request.Context(), newEvent(c, reportErr, status))
+	}
+}
+
+// PanicHook 返回在 panic 时上报错误的回调，可通过 Engine.RegisterPanicHook 注册
+func PanicHook(r ErrorReporter) middleware.PanicHook {
+	return func(c *gin.Context, err any, stack middleware.Frames) {
+		e, ok := err.(error)
+		if !ok {
+			e = fmt.Errorf("panic: %v", err)
+		}
+		event := newEvent(c, e, http.StatusInternalServerError)
+		event.Panic = true
+		event.Stack = stack
+		r.Report(c.Request.Context(), event)
+	}
+}
+
+func newEvent(c *gin.Context, err error, status int) Event {
+	return Event{
+		Err:       err,
+		Request:   c.Request,
+		Status:    status,
+		Route:     c.FullPath(),
+		RequestID: middleware.GetRequestID(c),
+		ClientIP:  middleware.ClientIP(c),
+	}
+}
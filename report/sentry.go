@@ -0,0 +1,98 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// SentryConfig Sentry 上报配置
+type SentryConfig struct {
+	DSN         string
+	Release     string
+	Environment string
+	// SampleRate 事件采样率，为 0 时全部上报
+	SampleRate float64
+}
+
+// Sentry 基于 sentry-go 的 ErrorReporter
+type Sentry struct {
+	hub *sentry.Hub
+}
+
+// NewSentry 创建 Sentry 上报器
+func NewSentry(conf SentryConfig) (*Sentry, error) {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         conf.DSN,
+		Release:     conf.Release,
+		Environment: conf.Environment,
+		SampleRate:  conf.SampleRate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init sentry: %w", err)
+	}
+	return &Sentry{hub: sentry.NewHub(client, sentry.NewScope())}, nil
+}
+
+func (s *Sentry) Report(_ context.Context, event Event) {
+	hub := s.hub.Clone()
+	hub.WithScope(func(scope *sentry.Scope) {
+		if event.Request != nil {
+			scope.SetRequest(event.Request)
+		}
+		scope.SetTag("route", event.Route)
+		scope.SetTag("status", strconv.Itoa(event.Status))
+		if event.RequestID != "" {
+			scope.SetTag("request_id", event.RequestID)
+		}
+		if event.ClientIP != "" {
+			scope.SetUser(sentry.User{IPAddress: event.ClientIP})
+		}
+
+		if !event.Panic {
+			hub.CaptureException(event.Err)
+			return
+		}
+
+		scope.SetLevel(sentry.LevelFatal)
+		ev := sentry.NewEvent()
+		ev.Level = sentry.LevelFatal
+		ev.Message = event.Err.Error()
+		ev.Exception = []sentry.Exception{{
+			Type:       "panic",
+			Value:      event.Err.Error(),
+			Stacktrace: sentryStacktrace(event),
+		}}
+		hub.CaptureEvent(ev)
+	})
+}
+
+func (s *Sentry) Flush(ctx context.Context) error {
+	timeout := 5 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if !s.hub.Flush(timeout) {
+		return errors.New("sentry: flush timed out")
+	}
+	return nil
+}
+
+// sentryStacktrace 转换调用栈，Sentry 要求最内层帧在最后
+func sentryStacktrace(event Event) *sentry.Stacktrace {
+	frames := make([]sentry.Frame, 0, len(event.Stack))
+	for i := len(event.Stack) - 1; i >= 0; i-- {
+		f := event.Stack[i]
+		frames = append(frames, sentry.Frame{
+			Function: f.Function,
+			AbsPath:  f.File,
+			Lineno:   f.Line,
+			InApp:    true,
+		})
+	}
+	return &sentry.Stacktrace{Frames: frames}
+}
@@ -0,0 +1,81 @@
+package ginx
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Response 标准响应包装
+type Response struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data any    `json:"data,omitempty"`
+}
+
+// Page 分页数据
+type Page struct {
+	Items any   `json:"items"`
+	Page  int   `json:"page"`
+	Total int64 `json:"total"`
+}
+
+// ResponseConfig 响应包装配置
+type ResponseConfig struct {
+	// Bare 为 true 时成功响应直接返回数据，失败响应仅包含 code 与 msg
+	Bare bool
+	// SuccessCode 成功时的业务码，默认 0
+	SuccessCode int
+	// SuccessMsg 成功时的提示，默认 "ok"
+	SuccessMsg string
+	// FailStatus Fail 使用的 HTTP 状态码，默认 200；Bare 模式下默认 400
+	FailStatus int
+}
+
+var responseConfig atomic.Pointer[ResponseConfig]
+
+func init() {
+	SetResponseConfig(ResponseConfig{})
+}
+
+// SetResponseConfig 设置全局响应包装方式
+func SetResponseConfig(conf ResponseConfig) {
+	if conf.SuccessMsg == "" {
+		conf.SuccessMsg = "ok"
+	}
+	if conf.FailStatus == 0 {
+		conf.FailStatus = http.StatusOK
+		if conf.Bare {
+			conf.FailStatus = http.StatusBadRequest
+		}
+	}
+	responseConfig.Store(&conf)
+}
+
+// OK 写出成功响应
+func OK(c *gin.Context, data any) {
+	respond(c, http.StatusOK, data)
+}
+
+// Fail 写出业务失败响应并中止请求，与 OK 使用相同的内容协商与 JSON 引擎
+func Fail(c *gin.Context, code int, msg string) {
+	conf := responseConfig.Load()
+	c.Abort()
+	Render(c, conf.FailStatus, Response{Code: code, Msg: msg})
+}
+
+// Paginated 写出分页响应
+func Paginated(c *gin.Context, items any, page int, total int64) {
+	OK(c, Page{Items: items, Page: page, Total: total})
+}
+
+// respond 按全局配置包装数据，并按 Accept 协商内容类型写出
+func respond(c *gin.Context, status int, data any) {
+	conf := responseConfig.Load()
+	if conf.Bare {
+		Render(c, status, data)
+		return
+	}
+	Render(c, status, Response{Code: conf.SuccessCode, Msg: conf.SuccessMsg, Data: data})
+}
@@ -0,0 +1,58 @@
+package ginx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// markerJSON 在输出前追加标记，用于确认响应经过了当前 JSON 引擎
+type markerJSON struct{}
+
+func (markerJSON) Marshal(v any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	return append([]byte(" "), b...), err
+}
+func (markerJSON) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+func TestResponsesUseJSONEngine(t *testing.T) {
+	RegisterJSONEngine("marker", markerJSON{})
+	if err := SetJSONEngine("marker"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = SetJSONEngine(JSONStd) })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/ok", func(c *gin.Context) { OK(c, "data") })
+	r.GET("/fail", func(c *gin.Context) { Fail(c, 1001, "bad") })
+	r.GET("/error", func(c *gin.Context) { WriteError(c, NewError(http.StatusNotFound, "not_found", "missing")) })
+	r.GET("/internal", func(c *gin.Context) { WriteError(c, errors.New("boom")) })
+
+	for path, want := range map[string]int{
+		"/ok":       http.StatusOK,
+		"/fail":     responseConfig.Load().FailStatus,
+		"/error":    http.StatusNotFound,
+		"/internal": http.StatusInternalServerError,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Errorf("%s: status = %d, want %d", path, w.Code, want)
+		}
+		if !strings.HasPrefix(w.Body.String(), " {") {
+			t.Errorf("%s: body %q was not encoded by the selected engine", path, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/error", nil))
+	if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("problem Content-Type = %q", ct)
+	}
+}
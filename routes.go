@@ -0,0 +1,90 @@
+package ginx
+
+import (
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+// RouteInfo 路由信息，Middlewares 为路由处理函数之前依次执行的中间件
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Handler     string   `json:"handler"`
+	Middlewares []string `json:"middlewares"`
+}
+
+// RoutesInfo 返回已注册的路由及其中间件，用于审计路由表
+func (e *Engine) RoutesInfo() []RouteInfo {
+	chains := handlerChains(e.Engine)
+	routes := e.Routes()
+	out := make([]RouteInfo, 0, len(routes))
+	for _, r := range routes {
+		info := RouteInfo{Method: r.Method, Path: r.Path, Handler: r.Handler}
+		if chain := chains[r.Method+" "+r.Path]; len(chain.names) > 0 {
+			info.Middlewares = chain.names[:len(chain.names)-1]
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+// handlerChain 路由的处理链，handler 为最后一个处理函数的闭包地址，用于查找 Handle 记录的类型
+type handlerChain struct {
+	names   []string
+	handler unsafe.Pointer
+}
+
+// handlerChains 读取 gin 路由树中每个路由的完整处理链，gin 未公开该信息，
+// 内部结构变化时返回空结果，RoutesInfo 退化为不含中间件
+func handlerChains(engine any) map[string]handlerChain {
+	chains := make(map[string]handlerChain)
+	v := reflect.ValueOf(engine)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return chains
+	}
+	trees := v.Elem().FieldByName("trees")
+	if trees.Kind() != reflect.Slice {
+		return chains
+	}
+	for i := 0; i < trees.Len(); i++ {
+		tree := trees.Index(i)
+		method, root := tree.FieldByName("method"), tree.FieldByName("root")
+		if method.Kind() != reflect.String || root.Kind() != reflect.Pointer {
+			continue
+		}
+		walkNode(root, method.String(), chains)
+	}
+	return chains
+}
+
+func walkNode(n reflect.Value, method string, chains map[string]handlerChain) {
+	if n.IsNil() {
+		return
+	}
+	n = n.Elem()
+	handlers, fullPath, children := n.FieldByName("handlers"), n.FieldByName("fullPath"), n.FieldByName("children")
+	if handlers.Kind() == reflect.Slice && handlers.Len() > 0 && fullPath.Kind() == reflect.String {
+		names := make([]string, handlers.Len())
+		for i := range names {
+			names[i] = funcName(handlers.Index(i).Pointer())
+		}
+		last := handlers.Index(handlers.Len() - 1)
+		chains[method+" "+fullPath.String()] = handlerChain{
+			names:   names,
+			handler: *(*unsafe.Pointer)(unsafe.Pointer(last.UnsafeAddr())),
+		}
+	}
+	if children.Kind() == reflect.Slice {
+		for i := 0; i < children.Len(); i++ {
+			walkNode(children.Index(i), method, chains)
+		}
+	}
+}
+
+func funcName(pc uintptr) string {
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
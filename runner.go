@@ -0,0 +1,98 @@
+package ginx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Runner 与服务共享生命周期的长期运行任务，如消息队列消费者
+type Runner interface {
+	// Run 持续运行直到 Stop 被调用，正常停止时返回 nil；ctx 在关闭超时后被取消，用于中断仍在处理的任务
+	Run(ctx context.Context) error
+	// Stop 停止接收新任务并等待处理中的任务完成，ctx 结束时应放弃等待
+	Stop(ctx context.Context) error
+}
+
+type namedRunner struct {
+	name   string
+	runner Runner
+}
+
+// AddRunner 注册长期运行任务，在启动钩子执行完成、服务就绪之后启动，
+// 关闭或升级时与 HTTP 服务关闭并发停止；Run 意外返回错误时服务随之关闭
+func (e *Engine) AddRunner(name string, r Runner) {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	e.runners = append(e.runners, namedRunner{name: name, runner: r})
+}
+
+// startRunners 启动所有 Runner，Run 意外返回错误时把错误发送到 errChan
+func (e *Engine) startRunners(errChan chan<- error) {
+	e.hooksMu.Lock()
+	runners := append([]namedRunner(nil), e.runners...)
+	ctx, cancel := context.WithCancel(context.Background())
+	e.runnerCancel = cancel
+	e.runnerDone = make(chan struct{})
+	done := e.runnerDone
+	e.hooksMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, r := range runners {
+		e.logger.Info("Runner is starting", zap.String("runner", r.name))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.runner.Run(ctx); err != nil && ctx.Err() == nil {
+				e.logger.Error("Runner stopped unexpectedly", zap.String("runner", r.name), zap.Error(err))
+				select {
+				case errChan <- fmt.Errorf("%s: %w", r.name, err):
+				default:
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+}
+
+// stopRunners 并发停止所有 Runner 并等待 Run 返回，ctx 结束时取消 Run 的 ctx
+func (e *Engine) stopRunners(ctx context.Context) error {
+	e.hooksMu.Lock()
+	runners := append([]namedRunner(nil), e.runners...)
+	cancel, done := e.runnerCancel, e.runnerDone
+	e.runnerCancel, e.runnerDone = nil, nil
+	e.hooksMu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	defer cancel()
+
+	errs := make([]error, len(runners))
+	var wg sync.WaitGroup
+	for i, r := range runners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.runner.Stop(ctx); err != nil {
+				e.logger.Error("Runner stop error", zap.String("runner", r.name), zap.Error(err))
+				errs[i] = fmt.Errorf("runner %s stop error: %w", r.name, err)
+				return
+			}
+			e.logger.Info("Runner stopped", zap.String("runner", r.name))
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("runners: %w", ctx.Err()))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,156 @@
+package ginx
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RuntimeStats 运行时统计信息
+type RuntimeStats struct {
+	Goroutines int    `json:"goroutines"`
+	Threads    int    `json:"threads"`
+	CPUs       int    `json:"cpus"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+	Memory     Memory `json:"memory"`
+	GC         GC     `json:"gc"`
+}
+
+// Memory 内存统计，单位字节
+type Memory struct {
+	Alloc       uint64 `json:"alloc"`
+	TotalAlloc  uint64 `json:"total_alloc"`
+	Sys         uint64 `json:"sys"`
+	HeapAlloc   uint64 `json:"heap_alloc"`
+	HeapInuse   uint64 `json:"heap_inuse"`
+	HeapIdle    uint64 `json:"heap_idle"`
+	HeapObjects uint64 `json:"heap_objects"`
+	StackInuse  uint64 `json:"stack_inuse"`
+}
+
+// GC 垃圾回收统计
+type GC struct {
+	NumGC        uint32        `json:"num_gc"`
+	LastGC       time.Time     `json:"last_gc"`
+	PauseTotal   time.Duration `json:"pause_total_ns"`
+	RecentPauses []string      `json:"recent_pauses"`
+	NextGC       uint64        `json:"next_gc"`
+	CPUFraction  float64       `json:"cpu_fraction"`
+}
+
+// ReadRuntimeStats 采集当前运行时统计信息
+func ReadRuntimeStats() RuntimeStats {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	var gc debug.GCStats
+	gc.Pause = make([]time.Duration, 0, 10)
+	debug.ReadGCStats(&gc)
+	pauses := make([]string, 0, min(len(gc.Pause), 10))
+	for _, p := range gc.Pause[:min(len(gc.Pause), 10)] {
+		pauses = append(pauses, p.String())
+	}
+
+	threads, _ := runtime.ThreadCreateProfile(nil)
+	return RuntimeStats{
+		Goroutines: runtime.NumGoroutine(),
+		Threads:    threads,
+		CPUs:       runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Memory: Memory{
+			Alloc:       ms.Alloc,
+			TotalAlloc:  ms.TotalAlloc,
+			Sys:         ms.Sys,
+			HeapAlloc:   ms.HeapAlloc,
+			HeapInuse:   ms.HeapInuse,
+			HeapIdle:    ms.HeapIdle,
+			HeapObjects: ms.HeapObjects,
+			StackInuse:  ms.StackInuse,
+		},
+		GC: GC{
+			NumGC:        ms.NumGC,
+			LastGC:       gc.LastGC,
+			PauseTotal:   gc.PauseTotal,
+			RecentPauses: pauses,
+			NextGC:       ms.NextGC,
+			CPUFraction:  ms.GCCPUFraction,
+		},
+	}
+}
+
+// registerRuntime 注册运行时统计与转储接口：
+// GET stats 返回统计信息，POST gc 触发一次 GC，POST dump/goroutine、dump/heap 生成转储
+func registerRuntime(g *gin.RouterGroup, dumpDir string) {
+	g.GET("/stats", func(c *gin.Context) {
+		c.JSON(http.StatusOK, ReadRuntimeStats())
+	})
+	g.POST("/gc", func(c *gin.Context) {
+		runtime.GC()
+		c.JSON(http.StatusOK, ReadRuntimeStats())
+	})
+	g.POST("/dump/:profile", func(c *gin.Context) {
+		name := c.Param("profile")
+		if name != "goroutine" && name != "heap" {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if name == "heap" && c.Query("gc") == "1" {
+			runtime.GC()
+		}
+		debugLevel := 0
+		if name == "goroutine" && c.Query("debug") != "0" {
+			// 默认输出可读的完整堆栈
+			debugLevel = 2
+		}
+
+		if dumpDir == "" {
+			if debugLevel > 0 {
+				c.Header("Content-Type", "text/plain; charset=utf-8")
+			} else {
+				c.Header("Content-Type", "application/octet-stream")
+				c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pprof"`, name))
+			}
+			if err := pprof.Lookup(name).WriteTo(c.Writer, debugLevel); err != nil {
+				L().Error("Failed to write profile", zap.String("profile", name), zap.Error(err))
+			}
+			return
+		}
+
+		path, err := dumpProfile(dumpDir, name, debugLevel)
+		if err != nil {
+			L().Error("Failed to dump profile", zap.String("profile", name), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"file": path})
+	})
+}
+
+// dumpProfile 将 profile 写入 dir 下带时间戳与 PID 的文件
+func dumpProfile(dir, name string, debugLevel int) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create dump directory: %w", err)
+	}
+	ext := "pprof"
+	if debugLevel > 0 {
+		ext = "txt"
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%d-%s.%s", name, os.Getpid(), time.Now().Format("20060102T150405"), ext))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create dump file: %w", err)
+	}
+	defer f.Close()
+	if err := pprof.Lookup(name).WriteTo(f, debugLevel); err != nil {
+		return "", fmt.Errorf("failed to write %s profile: %w", name, err)
+	}
+	return path, nil
+}
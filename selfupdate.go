@@ -0,0 +1,46 @@
+package ginx
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/gaoxin19/ginx/config"
+	"github.com/gaoxin19/ginx/updater"
+)
+
+// setupUpdater 按配置创建自动更新器
+func (e *Engine) setupUpdater(opts *config.UpdateOptions) error {
+	key, err := base64.StdEncoding.DecodeString(opts.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to decode update public key: %w", err)
+	}
+	u, err := updater.New(updater.Config{
+		Source:    updater.HTTPSource(opts.ManifestURL, nil),
+		Version:   GetBuildInfo().Version,
+		PublicKey: ed25519.PublicKey(key),
+		Interval:  opts.Interval,
+		Dir:       opts.Dir,
+		Logger:    e.logger,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create updater: %w", err)
+	}
+	e.UseUpdater(u)
+	return nil
+}
+
+// UseUpdater 在服务启动后运行自动更新器，新版本通过 UpgradeTo 平滑升级，关闭时停止
+func (e *Engine) UseUpdater(u *updater.Updater) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	e.RegisterOnStart("self-update", func(context.Context) error {
+		go u.Run(ctx, e.UpgradeTo)
+		return nil
+	})
+	e.RegisterOnShutdown("self-update", func(context.Context) error {
+		cancel()
+		return nil
+	})
+}
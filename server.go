@@ -0,0 +1,162 @@
+package ginx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/soheilhy/cmux"
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Server 与 HTTP 服务共享生命周期的附加服务，如 gRPC 服务
+type Server interface {
+	// Serve 在 ln 上提供服务，直到 Shutdown 被调用
+	Serve(ln net.Listener) error
+	// Shutdown 优雅关闭服务，ctx 超时后应强制关闭
+	Shutdown(ctx context.Context) error
+}
+
+type extraServer struct {
+	name   string
+	addr   string
+	server Server
+	// matchers 不为空时与 HTTP 服务共用主监听器，按匹配规则分流连接
+	matchers []cmux.MatchWriter
+}
+
+// AddServer 注册附加服务，随引擎在 addr 上监听、启动与优雅关闭；
+// 通过 Run 运行时其监听器同样由升级器管理，平滑重启时由新进程继承
+func (e *Engine) AddServer(name, addr string, srv Server) {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	e.servers = append(e.servers, extraServer{name: name, addr: addr, server: srv})
+}
+
+// GRPCMatcher 匹配 gRPC 连接（HTTP/2 且 content-type 为 application/grpc），
+// 会先发送 SETTINGS 帧，以兼容等待服务端设置后才发送请求头的 grpc-go 客户端
+func GRPCMatcher() cmux.MatchWriter {
+	return cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc")
+}
+
+// AddMuxServer 注册与 HTTP 服务共用同一端口的附加服务，连接按 matchers 分流，默认匹配 gRPC；
+// 主监听器由升级器管理，因此单端口部署同样支持平滑重启。启用后 HTTP 服务同时支持明文 HTTP/2（h2c）
+func (e *Engine) AddMuxServer(name string, srv Server, matchers ...cmux.MatchWriter) {
+	if len(matchers) == 0 {
+		matchers = []cmux.MatchWriter{GRPCMatcher()}
+	}
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	e.servers = append(e.servers, extraServer{name: name, server: srv, matchers: matchers})
+}
+
+func (e *Engine) extraServers() []extraServer {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	return append([]extraServer(nil), e.servers...)
+}
+
+// listenServers 为使用独立端口的附加服务创建监听器，共用主端口的服务对应位置为 nil，
+// 任一失败时关闭已创建的监听器
+func (e *Engine) listenServers(listen func(network, addr string) (net.Listener, error)) ([]net.Listener, error) {
+	servers := e.extraServers()
+	lns := make([]net.Listener, len(servers))
+	for i, s := range servers {
+		if len(s.matchers) > 0 {
+			continue
+		}
+		ln, err := listen("tcp", s.addr)
+		if err != nil {
+			for _, l := range lns {
+				if l != nil {
+					l.Close()
+				}
+			}
+			return nil, fmt.Errorf("failed to create listener for %s: %w", s.name, err)
+		}
+		lns[i] = ln
+	}
+	return lns, nil
+}
+
+// httpListener 为 HTTP 服务安装连接跟踪与在途请求统计，并按需拆分主监听器，返回 HTTP 服务使用的监听器。
+// 跟踪监听器包在 cmux 之后，使 ConnState 回调与 Close 作用于同一个连接对象
+func (e *Engine) httpListener(server *http.Server, ln net.Listener, lns []net.Listener) net.Listener {
+	server.ConnState = e.conns.hook(server.ConnState)
+	server.Handler = e.inflight.wrap(server.Handler)
+	return e.conns.listener(e.multiplex(server, ln, lns))
+}
+
+// multiplex 存在共用主端口的附加服务时用 cmux 拆分主监听器，填充 lns 中对应的位置，
+// 返回 HTTP 服务使用的监听器；关闭返回的监听器会同时关闭主监听器
+func (e *Engine) multiplex(server *http.Server, ln net.Listener, lns []net.Listener) net.Listener {
+	servers := e.extraServers()
+	var m cmux.CMux
+	for i, s := range servers[:len(lns)] {
+		if len(s.matchers) == 0 {
+			continue
+		}
+		if m == nil {
+			m = cmux.New(ln)
+		}
+		lns[i] = m.MatchWithWriters(s.matchers...)
+	}
+	if m == nil {
+		return ln
+	}
+
+	httpLn := m.Match(cmux.Any())
+	server.Handler = h2c.NewHandler(server.Handler, &http2.Server{})
+	go func() {
+		if err := m.Serve(); err != nil && !errors.Is(err, net.ErrClosed) {
+			e.logger.Debug("Connection multiplexer stopped", zap.Error(err))
+		}
+	}()
+	return httpLn
+}
+
+// startServers 启动附加服务，服务异常退出时把错误发送到 errChan
+func (e *Engine) startServers(lns []net.Listener, errChan chan<- error) {
+	for i, s := range e.extraServers()[:len(lns)] {
+		if lns[i] == nil {
+			continue
+		}
+		e.logger.Info("Server is starting",
+			zap.String("server", s.name),
+			zap.String("addr", lns[i].Addr().String()),
+		)
+		go func() {
+			if err := s.server.Serve(lns[i]); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				select {
+				case errChan <- fmt.Errorf("%s: %w", s.name, err):
+				default:
+				}
+			}
+		}()
+	}
+}
+
+// shutdownServers 并发关闭所有附加服务
+func (e *Engine) shutdownServers(ctx context.Context) error {
+	servers := e.extraServers()
+	errs := make([]error, len(servers))
+
+	var wg sync.WaitGroup
+	for i, s := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.server.Shutdown(ctx); err != nil {
+				e.logger.Error("Server shutdown error", zap.String("server", s.name), zap.Error(err))
+				errs[i] = fmt.Errorf("%s shutdown error: %w", s.name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
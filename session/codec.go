@@ -0,0 +1,86 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errInvalidValue = errors.New("session: invalid cookie value")
+
+// codec 对 cookie 值进行加密（AES-GCM，可选）和签名（HMAC-SHA256）
+type codec struct {
+	hashKey []byte
+	aead    cipher.AEAD
+}
+
+func newCodec(hashKey, blockKey []byte) (*codec, error) {
+	if len(hashKey) < 32 {
+		return nil, errors.New("session: HashKey must be at least 32 bytes")
+	}
+	c := &codec{hashKey: hashKey}
+	if len(blockKey) > 0 {
+		block, err := aes.NewCipher(blockKey)
+		if err != nil {
+			return nil, fmt.Errorf("session: invalid BlockKey: %w", err)
+		}
+		c.aead, err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("session: failed to init cipher: %w", err)
+		}
+	}
+	return c, nil
+}
+
+func (c *codec) encode(name string, plaintext []byte) (string, error) {
+	data := plaintext
+	if c.aead != nil {
+		nonce := make([]byte, c.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", fmt.Errorf("session: failed to generate nonce: %w", err)
+		}
+		data = c.aead.Seal(nonce, nonce, plaintext, []byte(name))
+	}
+	body := base64.RawURLEncoding.EncodeToString(data)
+	return body + "." + base64.RawURLEncoding.EncodeToString(c.mac(name, body)), nil
+}
+
+func (c *codec) decode(name, value string) ([]byte, error) {
+	body, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, errInvalidValue
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, c.mac(name, body)) {
+		return nil, errInvalidValue
+	}
+	data, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, errInvalidValue
+	}
+	if c.aead == nil {
+		return data, nil
+	}
+
+	size := c.aead.NonceSize()
+	if len(data) < size {
+		return nil, errInvalidValue
+	}
+	plaintext, err := c.aead.Open(nil, data[:size], data[size:], []byte(name))
+	if err != nil {
+		return nil, errInvalidValue
+	}
+	return plaintext, nil
+}
+
+func (c *codec) mac(name, body string) []byte {
+	h := hmac.New(sha256.New, c.hashKey)
+	h.Write([]byte(name + "|" + body))
+	return h.Sum(nil)
+}
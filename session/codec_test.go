@@ -0,0 +1,64 @@
+package session
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestCodec(t *testing.T) {
+	hashKey := bytes.Repeat([]byte("h"), 32)
+	blockKey := bytes.Repeat([]byte("b"), 32)
+	plaintext := []byte(`{"user":"alice"}`)
+
+	if _, err := newCodec(hashKey[:16], nil); err == nil {
+		t.Fatal("short HashKey accepted")
+	}
+	if _, err := newCodec(hashKey, []byte("short")); err == nil {
+		t.Fatal("invalid BlockKey accepted")
+	}
+
+	for _, blockKey := range [][]byte{nil, blockKey} {
+		c, err := newCodec(hashKey, blockKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := c.encode("sid", plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.decode("sid", value)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Fatalf("decode = %q, %v; want %q", got, err, plaintext)
+		}
+
+		body, sig, _ := strings.Cut(value, ".")
+		raw, _ := base64.RawURLEncoding.DecodeString(body)
+		if blockKey != nil && bytes.Contains(raw, plaintext) {
+			t.Fatal("plaintext visible in encrypted value")
+		}
+
+		raw[len(raw)-1] ^= 1
+		tampered := base64.RawURLEncoding.EncodeToString(raw) + "." + sig
+		invalid := map[string]string{
+			"tampered body": tampered,
+			"missing sig":   body,
+			"bad sig":       body + ".!!",
+			"empty":         "",
+		}
+		for name, v := range invalid {
+			if _, err := c.decode("sid", v); err != errInvalidValue {
+				t.Errorf("%s: err = %v, want errInvalidValue", name, err)
+			}
+		}
+		if _, err := c.decode("other", value); err != errInvalidValue {
+			t.Errorf("value accepted under another cookie name: %v", err)
+		}
+
+		other, _ := newCodec(bytes.Repeat([]byte("x"), 32), blockKey)
+		if _, err := other.decode("sid", value); err != errInvalidValue {
+			t.Errorf("value accepted with another HashKey: %v", err)
+		}
+	}
+}
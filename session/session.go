@@ -0,0 +1,285 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const contextKey = "ginx.session"
+
+// Options 会话配置
+type Options struct {
+	// Store 服务端存储，为 nil 时会话数据整体保存在 cookie 中
+	Store Store
+	// HashKey cookie 签名密钥，至少 32 字节
+	HashKey []byte
+	// BlockKey cookie 加密密钥（AES，16/24/32 字节），为空时仅签名不加密
+	BlockKey []byte
+
+	// CookieName 默认 ginx_session
+	CookieName string
+	Path       string
+	Domain     string
+	Secure     bool
+	SameSite   http.SameSite
+
+	// IdleTimeout 空闲超时，超过该时间未访问则会话失效，默认 30 分钟
+	IdleTimeout time.Duration
+	// AbsoluteTimeout 绝对超时，自创建起超过该时间会话失效，默认 24 小时
+	AbsoluteTimeout time.Duration
+}
+
+// Manager 会话管理器
+type Manager struct {
+	opts  Options
+	codec *codec
+}
+
+// New 创建会话管理器
+func New(opts Options) (*Manager, error) {
+	c, err := newCodec(opts.HashKey, opts.BlockKey)
+	if err != nil {
+		return nil, err
+	}
+	if opts.CookieName == "" {
+		opts.CookieName = "ginx_session"
+	}
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+	if opts.SameSite == 0 {
+		opts.SameSite = http.SameSiteLaxMode
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = 30 * time.Minute
+	}
+	if opts.AbsoluteTimeout <= 0 {
+		opts.AbsoluteTimeout = 24 * time.Hour
+	}
+	return &Manager{opts: opts, codec: c}, nil
+}
+
+// Session 单个会话，值经过 JSON 序列化，读取时数字类型为 float64
+type Session struct {
+	ID         string         `json:"id"`
+	Values     map[string]any `json:"values"`
+	CreatedAt  time.Time      `json:"created_at"`
+	LastAccess time.Time      `json:"last_access"`
+
+	dirty     bool
+	destroyed bool
+}
+
+// Get 获取值
+func (s *Session) Get(key string) any {
+	return s.Values[key]
+}
+
+// Set 设置值
+func (s *Session) Set(key string, value any) {
+	s.Values[key] = value
+	s.dirty = true
+}
+
+// Delete 删除值
+func (s *Session) Delete(key string) {
+	delete(s.Values, key)
+	s.dirty = true
+}
+
+// Destroy 销毁会话并清除 cookie
+func (s *Session) Destroy() {
+	s.Values = map[string]any{}
+	s.destroyed = true
+	s.dirty = true
+}
+
+// RenewID 重新生成会话 ID，登录等权限变化后应调用以防止会话固定攻击
+func (s *Session) RenewID() {
+	s.ID = newID()
+	s.dirty = true
+}
+
+// state 单个请求内的会话状态，首次访问时才加载
+type state struct {
+	m     *Manager
+	c     *gin.Context
+	once  sync.Once
+	sess  *Session
+	oldID string
+	saved bool
+}
+
+// Middleware 返回会话中间件，会话在首次调用 Get 时加载，在响应头写出前自动保存
+func (m *Manager) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		st := &state{m: m, c: c}
+		c.Set(contextKey, st)
+		c.Writer = &saveWriter{ResponseWriter: c.Writer, st: st}
+		c.Next()
+		st.save()
+	}
+}
+
+// Get 获取当前请求的会话，需挂载 Middleware
+func Get(c *gin.Context) *Session {
+	v, ok := c.Get(contextKey)
+	if !ok {
+		panic("session: Middleware is not installed")
+	}
+	st := v.(*state)
+	st.once.Do(st.load)
+	return st.sess
+}
+
+func (st *state) load() {
+	now := time.Now()
+	if value, err := st.c.Cookie(st.m.opts.CookieName); err == nil {
+		if sess, err := st.m.decode(st.c, value); err == nil && st.m.valid(sess, now) {
+			st.oldID = sess.ID
+			sess.LastAccess = now
+			sess.dirty = true
+			st.sess = sess
+			return
+		}
+	}
+	st.sess = &Session{
+		ID:         newID(),
+		Values:     map[string]any{},
+		CreatedAt:  now,
+		LastAccess: now,
+	}
+}
+
+func (st *state) save() {
+	if st.saved || st.sess == nil || !st.sess.dirty {
+		return
+	}
+	st.saved = true
+	if err := st.m.save(st.c, st.sess, st.oldID); err != nil {
+		_ = st.c.Error(err)
+	}
+}
+
+func (m *Manager) valid(sess *Session, now time.Time) bool {
+	return now.Sub(sess.LastAccess) <= m.opts.IdleTimeout &&
+		now.Sub(sess.CreatedAt) <= m.opts.AbsoluteTimeout
+}
+
+func (m *Manager) decode(c *gin.Context, value string) (*Session, error) {
+	data, err := m.codec.decode(m.opts.CookieName, value)
+	if err != nil {
+		return nil, err
+	}
+	if m.opts.Store != nil {
+		data, err = m.opts.Store.Get(c.Request.Context(), string(data))
+		if err != nil {
+			return nil, err
+		}
+	}
+	var sess Session
+	if err := json.Unmarshal(data, &sess); err != nil {
+		return nil, fmt.Errorf("session: failed to decode: %w", err)
+	}
+	if sess.Values == nil {
+		sess.Values = map[string]any{}
+	}
+	return &sess, nil
+}
+
+func (m *Manager) save(c *gin.Context, sess *Session, oldID string) error {
+	ctx := c.Request.Context()
+	if m.opts.Store != nil && oldID != "" && (sess.destroyed || oldID != sess.ID) {
+		if err := m.opts.Store.Delete(ctx, oldID); err != nil {
+			return fmt.Errorf("session: failed to delete: %w", err)
+		}
+	}
+	if sess.destroyed {
+		m.setCookie(c, "", -1)
+		return nil
+	}
+
+	data, err := json.Marshal(sess)
+	if err != nil {
+		return fmt.Errorf("session: failed to encode: %w", err)
+	}
+
+	// 剩余有效期取空闲超时与绝对超时的较小值
+	ttl := min(m.opts.IdleTimeout, time.Until(sess.CreatedAt.Add(m.opts.AbsoluteTimeout)))
+	payload := data
+	if m.opts.Store != nil {
+		if err := m.opts.Store.Set(ctx, sess.ID, data, ttl); err != nil {
+			return fmt.Errorf("session: failed to save: %w", err)
+		}
+		payload = []byte(sess.ID)
+	}
+
+	value, err := m.codec.encode(m.opts.CookieName, payload)
+	if err != nil {
+		return err
+	}
+	m.setCookie(c, value, int(ttl/time.Second))
+	return nil
+}
+
+func (m *Manager) setCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     m.opts.CookieName,
+		Value:    value,
+		Path:     m.opts.Path,
+		Domain:   m.opts.Domain,
+		MaxAge:   maxAge,
+		Secure:   m.opts.Secure,
+		HttpOnly: true,
+		SameSite: m.opts.SameSite,
+	})
+}
+
+// saveWriter 在响应头真正写出前保存会话（gin 的 WriteHeader 只记录状态码，不触发保存）
+type saveWriter struct {
+	gin.ResponseWriter
+	st *state
+}
+
+func (w *saveWriter) WriteHeaderNow() {
+	w.st.save()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *saveWriter) Write(b []byte) (int, error) {
+	w.st.save()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *saveWriter) WriteString(s string) (int, error) {
+	w.st.save()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func newID() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// CSRFStore 将 CSRF token 保存在会话中，可作为 middleware.CSRFConfig.Store 使用同步器令牌模式
+type CSRFStore struct{}
+
+const csrfKey = "_csrf"
+
+func (CSRFStore) Token(c *gin.Context) (string, error) {
+	token, _ := Get(c).Get(csrfKey).(string)
+	return token, nil
+}
+
+func (CSRFStore) SetToken(c *gin.Context, token string) error {
+	Get(c).Set(csrfKey, token)
+	return nil
+}
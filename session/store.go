@@ -0,0 +1,17 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound 会话不存在或已过期
+var ErrNotFound = errors.New("session: not found")
+
+// Store 服务端会话存储，cookie 中只保存会话 ID
+type Store interface {
+	Get(ctx context.Context, id string) ([]byte, error)
+	Set(ctx context.Context, id string, data []byte, ttl time.Duration) error
+	Delete(ctx context.Context, id string) error
+}
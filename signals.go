@@ -0,0 +1,62 @@
+package ginx
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gaoxin19/ginx/config"
+)
+
+// signals 解析后的信号映射
+type signals struct {
+	upgrade   []os.Signal
+	shutdown  []os.Signal
+	logLevel  []os.Signal
+	logRotate []os.Signal
+}
+
+// parseSignals 解析信号映射，同一信号不能对应多个操作
+func parseSignals(conf *config.SignalOptions) (signals, error) {
+	if conf == nil {
+		conf = &config.SignalOptions{}
+	}
+	var (
+		s    signals
+		err  error
+		seen = make(map[os.Signal]string)
+	)
+	parse := func(action string, names []string, defaults ...string) []os.Signal {
+		if err != nil {
+			return nil
+		}
+		if names == nil {
+			names = defaults
+		}
+		sigs := make([]os.Signal, 0, len(names))
+		for _, name := range names {
+			key := strings.ToUpper(strings.TrimSpace(name))
+			if !strings.HasPrefix(key, "SIG") {
+				key = "SIG" + key
+			}
+			sig, ok := signalNames[key]
+			if !ok {
+				err = fmt.Errorf("unknown signal %q for %s", name, action)
+				return nil
+			}
+			if prev, ok := seen[sig]; ok && prev != action {
+				err = fmt.Errorf("signal %s is mapped to both %s and %s", key, prev, action)
+				return nil
+			}
+			seen[sig] = action
+			sigs = append(sigs, sig)
+		}
+		return sigs
+	}
+
+	s.upgrade = parse("upgrade", conf.Upgrade, "SIGHUP")
+	s.shutdown = parse("shutdown", conf.Shutdown, "SIGINT", "SIGTERM", "SIGQUIT")
+	s.logLevel = parse("log level", conf.LogLevel, defaultLogLevelSignals...)
+	s.logRotate = parse("log rotate", conf.LogRotate)
+	return s, err
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package ginx
+
+import (
+	"os"
+	"syscall"
+)
+
+// signalNames 支持在配置中使用的信号名
+var signalNames = map[string]os.Signal{
+	"SIGHUP":   syscall.SIGHUP,
+	"SIGINT":   syscall.SIGINT,
+	"SIGQUIT":  syscall.SIGQUIT,
+	"SIGTERM":  syscall.SIGTERM,
+	"SIGUSR1":  syscall.SIGUSR1,
+	"SIGUSR2":  syscall.SIGUSR2,
+	"SIGWINCH": syscall.SIGWINCH,
+}
+
+// defaultLogLevelSignals 默认切换日志级别的信号
+var defaultLogLevelSignals = []string{"SIGUSR1"}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package ginx
+
+import (
+	"os"
+	"syscall"
+)
+
+// signalNames 支持在配置中使用的信号名，Windows 上通常只会收到 SIGINT（Ctrl+C）
+var signalNames = map[string]os.Signal{
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGTERM": syscall.SIGTERM,
+}
+
+// defaultLogLevelSignals Windows 没有 SIGUSR1，默认不监听
+var defaultLogLevelSignals []string
@@ -0,0 +1,64 @@
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event 服务端事件
+type Event struct {
+	// ID 事件 ID，客户端重连时通过 Last-Event-ID 头带回
+	ID string
+	// Event 事件类型，为空时客户端按 message 处理
+	Event string
+	// Data 事件数据，string 与 []byte 原样写出，其它类型编码为 JSON
+	Data any
+	// Retry 建议客户端的重连间隔
+	Retry time.Duration
+}
+
+// WriteTo 按 text/event-stream 格式写出事件
+func (ev Event) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	if ev.ID != "" {
+		b.WriteString("id: " + singleLine(ev.ID) + "\n")
+	}
+	if ev.Event != "" {
+		b.WriteString("event: " + singleLine(ev.Event) + "\n")
+	}
+	if ev.Retry > 0 {
+		b.WriteString("retry: " + formatMillis(ev.Retry) + "\n")
+	}
+
+	data, err := encodeData(ev.Data)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode event data: %w", err)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func encodeData(data any) (string, error) {
+	switch v := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	b, err := json.Marshal(data)
+	return string(b), err
+}
+
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
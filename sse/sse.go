@@ -0,0 +1,263 @@
+// Package sse 提供 Server-Sent Events 推送，支持心跳、断线续传与优雅关闭。
+//
+// 通过 engine.RegisterOnDrain("sse", broker.Shutdown) 接入 ginx 的关闭流程，
+// 进程退出或升级时已有连接会收到 Options.Final 事件后断开。
+package sse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrClosed 客户端连接已关闭
+	ErrClosed = errors.New("sse: client closed")
+	// ErrBufferFull 客户端缓冲区已满，通常是客户端消费过慢
+	ErrBufferFull = errors.New("sse: client buffer full")
+)
+
+// 默认配置
+const (
+	DefaultHeartbeat = 15 * time.Second
+	DefaultBuffer    = 32
+)
+
+// Options Broker 配置
+type Options struct {
+	// Heartbeat 心跳间隔，用于防止代理断开空闲连接，默认 15s，负数关闭心跳
+	Heartbeat time.Duration
+	// Buffer 每个客户端的事件缓冲数量，默认 32
+	Buffer int
+	// Retry 连接建立时告知客户端的重连间隔，0 表示使用浏览器默认值
+	Retry time.Duration
+	// Final 关闭时发送给所有客户端的最后一个事件，为空时直接断开
+	Final *Event
+}
+
+// Client 单个 SSE 连接
+type Client struct {
+	lastEventID string
+	events      chan Event
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+// LastEventID 返回客户端重连时携带的 Last-Event-ID，可用于补发错过的事件
+func (cl *Client) LastEventID() string {
+	return cl.lastEventID
+}
+
+// Send 将事件放入客户端缓冲区，不会阻塞
+func (cl *Client) Send(ev Event) error {
+	select {
+	case <-cl.done:
+		return ErrClosed
+	default:
+	}
+	select {
+	case cl.events <- ev:
+		return nil
+	case <-cl.done:
+		return ErrClosed
+	default:
+		return ErrBufferFull
+	}
+}
+
+// Done 返回连接结束时关闭的通道
+func (cl *Client) Done() <-chan struct{} {
+	return cl.done
+}
+
+// Close 结束连接
+func (cl *Client) Close() {
+	cl.closeOnce.Do(func() { close(cl.done) })
+}
+
+// Broker 管理所有 SSE 连接
+type Broker struct {
+	opts Options
+
+	mu       sync.Mutex
+	clients  map[*Client]struct{}
+	closing  chan struct{}
+	shutdown sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewBroker 创建 Broker
+func NewBroker(opts Options) *Broker {
+	if opts.Heartbeat == 0 {
+		opts.Heartbeat = DefaultHeartbeat
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultBuffer
+	}
+	return &Broker{
+		opts:    opts,
+		clients: make(map[*Client]struct{}),
+		closing: make(chan struct{}),
+	}
+}
+
+// Stream 将请求转为事件流并阻塞至客户端断开或 Broker 关闭，
+// onOpen 在连接建立后调用，可用于订阅或按 LastEventID 补发事件
+func (b *Broker) Stream(c *gin.Context, onOpen func(*Client)) {
+	cl, ok := b.add(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+	defer b.remove(cl)
+
+	// 长连接不受服务端 WriteTimeout 限制
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	h := c.Writer.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if b.opts.Retry > 0 {
+		if _, err := io.WriteString(c.Writer, "retry: "+formatMillis(b.opts.Retry)+"\n\n"); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	if onOpen != nil {
+		onOpen(cl)
+	}
+
+	var heartbeat <-chan time.Time
+	if b.opts.Heartbeat > 0 {
+		ticker := time.NewTicker(b.opts.Heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case ev := <-cl.events:
+			if _, err := ev.WriteTo(c.Writer); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-heartbeat:
+			if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		case <-cl.done:
+			return
+		case <-b.closing:
+			b.finish(c, cl)
+			return
+		}
+	}
+}
+
+// finish 关闭前写出缓冲中的事件与最终事件
+func (b *Broker) finish(c *gin.Context, cl *Client) {
+	for {
+		select {
+		case ev := <-cl.events:
+			if _, err := ev.WriteTo(c.Writer); err != nil {
+				return
+			}
+		default:
+			if b.opts.Final != nil {
+				_, _ = b.opts.Final.WriteTo(c.Writer)
+			}
+			c.Writer.Flush()
+			return
+		}
+	}
+}
+
+// Broadcast 向所有客户端发送事件，缓冲区已满的客户端会被断开，由其携带 Last-Event-ID 重连
+func (b *Broker) Broadcast(ev Event) {
+	b.mu.Lock()
+	clients := make([]*Client, 0, len(b.clients))
+	for cl := range b.clients {
+		clients = append(clients, cl)
+	}
+	b.mu.Unlock()
+
+	for _, cl := range clients {
+		if err := cl.Send(ev); errors.Is(err, ErrBufferFull) {
+			cl.Close()
+		}
+	}
+}
+
+// Len 返回当前连接数
+func (b *Broker) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.clients)
+}
+
+// Shutdown 拒绝新连接，向已有连接发送最终事件并等待其结束，直到 ctx 超时
+func (b *Broker) Shutdown(ctx context.Context) error {
+	b.shutdown.Do(func() {
+		b.mu.Lock()
+		close(b.closing)
+		b.mu.Unlock()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Broker) add(c *gin.Context) (*Client, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-b.closing:
+		return nil, false
+	default:
+	}
+
+	cl := &Client{
+		lastEventID: c.GetHeader("Last-Event-ID"),
+		events:      make(chan Event, b.opts.Buffer),
+		done:        make(chan struct{}),
+	}
+	b.clients[cl] = struct{}{}
+	b.wg.Add(1)
+	return cl, true
+}
+
+func (b *Broker) remove(cl *Client) {
+	b.mu.Lock()
+	delete(b.clients, cl)
+	b.mu.Unlock()
+	cl.Close()
+	b.wg.Done()
+}
+
+func formatMillis(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10)
+}
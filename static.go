@@ -0,0 +1,217 @@
+package ginx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultImmutablePattern 默认的指纹文件名规则，如 app.3f2a9c1d.js、chunk-5d41402a.css
+var DefaultImmutablePattern = regexp.MustCompile(`[.-][0-9a-fA-F]{8,}\.[^./]+$`)
+
+// StaticOptions 静态资源配置
+type StaticOptions struct {
+	// Index 目录默认文件，默认 index.html
+	Index string
+	// SPA 为 true 时，未找到文件且路径不含扩展名的请求回退到 Index，用于前端 history 路由
+	SPA bool
+	// Immutable 匹配指纹文件名的规则，匹配的文件设置一年的 immutable 缓存，默认 DefaultImmutablePattern
+	Immutable *regexp.Regexp
+	// MaxAge 其它文件的缓存时间，为 0 时要求客户端每次协商
+	MaxAge time.Duration
+	// Precompressed 为 true 时优先返回同名的 .br、.gz 预压缩文件
+	Precompressed bool
+}
+
+// Assets 在 prefix 下提供 fsys 中的静态资源，fsys 可以是 embed.FS 或 os.DirFS；
+// prefix 为 "/" 时作为 NoRoute 处理器注册，避免与其它路由冲突
+func (e *Engine) Assets(prefix string, fsys fs.FS, opts StaticOptions) {
+	handler := StaticHandler(fsys, opts)
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix == "/" {
+		e.NoRoute(func(c *gin.Context) {
+			if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+				c.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+			c.Params = append(c.Params, gin.Param{Key: "filepath", Value: c.Request.URL.Path})
+			handler(c)
+		})
+		return
+	}
+
+	pattern := prefix + "/*filepath"
+	e.GET(pattern, handler)
+	e.HEAD(pattern, handler)
+}
+
+// AssetsDir 在 prefix 下提供磁盘目录中的静态资源
+func (e *Engine) AssetsDir(prefix, dir string, opts StaticOptions) {
+	e.Assets(prefix, os.DirFS(dir), opts)
+}
+
+// StaticHandler 返回提供 fsys 中静态资源的处理器，文件路径取自 filepath 路由参数，
+// 支持 ETag/Last-Modified 条件请求与 Range 请求
+func StaticHandler(fsys fs.FS, opts StaticOptions) gin.HandlerFunc {
+	if opts.Index == "" {
+		opts.Index = "index.html"
+	}
+	if opts.Immutable == nil {
+		opts.Immutable = DefaultImmutablePattern
+	}
+	s := &staticServer{fsys: fsys, opts: opts}
+	return s.serve
+}
+
+type staticServer struct {
+	fsys fs.FS
+	opts StaticOptions
+	// etags 缓存文件内容的 ETag，键包含大小与修改时间，磁盘文件变化后自动失效
+	etags sync.Map
+}
+
+func (s *staticServer) serve(c *gin.Context) {
+	name := strings.TrimPrefix(path.Clean("/"+c.Param("filepath")), "/")
+	if name == "" {
+		name = s.opts.Index
+	}
+
+	info, err := fs.Stat(s.fsys, name)
+	if err == nil && info.IsDir() {
+		name = path.Join(name, s.opts.Index)
+		info, err = fs.Stat(s.fsys, name)
+	}
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) || !s.opts.SPA || path.Ext(name) != "" {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		name = s.opts.Index
+		if info, err = fs.Stat(s.fsys, name); err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+	}
+
+	h := c.Writer.Header()
+	h.Set("Cache-Control", s.cacheControl(name))
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		h.Set("Content-Type", ct)
+	}
+
+	file := name
+	if s.opts.Precompressed {
+		h.Add("Vary", "Accept-Encoding")
+		if variant, encoding, variantInfo := s.precompressed(c, name); variant != "" {
+			file, info = variant, variantInfo
+			h.Set("Content-Encoding", encoding)
+		}
+	}
+
+	content, err := s.open(file)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if closer, ok := content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	if etag, err := s.etag(file, info, content); err == nil {
+		h.Set("ETag", etag)
+	}
+	http.ServeContent(c.Writer, c.Request, name, info.ModTime(), content)
+}
+
+func (s *staticServer) cacheControl(name string) string {
+	switch {
+	case name == s.opts.Index || path.Base(name) == s.opts.Index:
+		return "no-cache"
+	case s.opts.Immutable.MatchString(name):
+		return "public, max-age=31536000, immutable"
+	case s.opts.MaxAge > 0:
+		return "public, max-age=" + strconv.Itoa(int(s.opts.MaxAge.Seconds()))
+	}
+	return "no-cache"
+}
+
+// precompressed 按 Accept-Encoding 查找预压缩文件
+func (s *staticServer) precompressed(c *gin.Context, name string) (string, string, fs.FileInfo) {
+	accept := c.GetHeader("Accept-Encoding")
+	for _, v := range []struct{ encoding, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+		if !acceptsEncoding(accept, v.encoding) {
+			continue
+		}
+		if info, err := fs.Stat(s.fsys, name+v.ext); err == nil && !info.IsDir() {
+			return name + v.ext, v.encoding, info
+		}
+	}
+	return "", "", nil
+}
+
+// open 打开文件，不支持 Seek 的实现会被读入内存
+func (s *staticServer) open(name string) (io.ReadSeeker, error) {
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if rs, ok := f.(io.ReadSeeker); ok {
+		return rs, nil
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// etag 基于文件内容计算强 ETag，并按文件大小与修改时间缓存
+func (s *staticServer) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	key := name + "\x00" + strconv.FormatInt(info.Size(), 10) + "\x00" + strconv.FormatInt(info.ModTime().UnixNano(), 10)
+	if v, ok := s.etags.Load(key); ok {
+		return v.(string), nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	s.etags.Store(key, etag)
+	return etag, nil
+}
+
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		token = strings.ToLower(strings.TrimSpace(token))
+		if token != encoding && token != "*" {
+			continue
+		}
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if q, err := strconv.ParseFloat(v, 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
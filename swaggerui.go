@@ -0,0 +1,144 @@
+package ginx
+
+import (
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+	"go.uber.org/zap"
+)
+
+// RedocBundle 提供 redoc.standalone.js 的文件系统，由应用通过 //go:embed 内嵌后设置，
+// 文档页面从本服务加载脚本，不依赖外部 CDN
+var RedocBundle fs.FS
+
+// redocBundleFile Redoc 页面加载的脚本文件名
+const redocBundleFile = "redoc.standalone.js"
+
+// SpecSource 文档页面展示的 OpenAPI 文档来源
+type SpecSource struct {
+	url  string
+	file string
+}
+
+// SpecURL 使用 url 处的文档，可以是绝对地址或本服务的路径
+func SpecURL(url string) SpecSource {
+	return SpecSource{url: url}
+}
+
+// SpecFile 使用本地的 JSON 或 YAML 文档，每次请求时读取
+func SpecFile(path string) SpecSource {
+	return SpecSource{file: path}
+}
+
+// GeneratedSpec 使用 Engine.OpenAPI 生成的文档
+var GeneratedSpec = SpecSource{}
+
+// swaggerAssets 文档页面需要的 Swagger UI 文件
+var swaggerAssets = map[string]bool{
+	"/swagger-ui.css":                  true,
+	"/swagger-ui-bundle.js":            true,
+	"/swagger-ui-standalone-preset.js": true,
+	"/index.css":                       true,
+	"/favicon-16x16.png":               true,
+	"/favicon-32x32.png":               true,
+}
+
+// MountSwaggerUI 在 path 下提供内嵌的 Swagger UI，展示 spec 指定的文档，handlers 可用于认证等访问控制
+func (e *Engine) MountSwaggerUI(path string, spec SpecSource, handlers ...gin.HandlerFunc) {
+	e.mountDocsUI(path, swaggerPage, spec, handlers)
+}
+
+// MountRedoc 在 path 下提供 Redoc 文档页面，脚本从 RedocBundle 加载；
+// 未设置 RedocBundle 时改用内嵌的 Swagger UI，避免从第三方地址加载脚本
+func (e *Engine) MountRedoc(path string, spec SpecSource, handlers ...gin.HandlerFunc) {
+	if RedocBundle == nil {
+		e.logger.Warn("Redoc bundle is not configured, serving Swagger UI instead", zap.String("path", path))
+		e.mountDocsUI(path, swaggerPage, spec, handlers)
+		return
+	}
+	e.mountDocsUI(path, redocPage, spec, handlers)
+}
+
+func (e *Engine) mountDocsUI(path string, page *template.Template, spec SpecSource, handlers []gin.HandlerFunc) {
+	title := "API"
+	if conf := e.options.OpenAPI; conf != nil && conf.Title != "" {
+		title = conf.Title
+	}
+	specName := "/openapi.json"
+	if ext := filepath.Ext(spec.file); ext != "" {
+		specName = "/openapi" + ext
+	}
+	specURL := spec.url
+	if specURL == "" {
+		specURL = "." + specName
+	}
+
+	assets := http.FS(swaggerFiles.FS)
+	if page == redocPage {
+		assets = http.FS(RedocBundle)
+	}
+	e.Group(path, handlers...).GET("/*file", func(c *gin.Context) {
+		switch name := c.Param("file"); {
+		case name == "/":
+			c.Header("Content-Type", "text/html; charset=utf-8")
+			page.Execute(c.Writer, map[string]string{"Title": title, "Spec": specURL})
+		case spec.url == "" && name == specName:
+			if spec.file != "" {
+				c.File(spec.file)
+				return
+			}
+			c.JSON(http.StatusOK, e.cachedOpenAPI())
+		case page == swaggerPage && swaggerAssets[name],
+			page == redocPage && name == "/"+redocBundleFile:
+			c.Header("Cache-Control", "public, max-age=86400")
+			c.FileFromFS(name, assets)
+		default:
+			c.Status(http.StatusNotFound)
+		}
+	})
+}
+
+var swaggerPage = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="./swagger-ui.css">
+<link rel="stylesheet" href="./index.css">
+<link rel="icon" type="image/png" href="./favicon-32x32.png" sizes="32x32">
+<link rel="icon" type="image/png" href="./favicon-16x16.png" sizes="16x16">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="./swagger-ui-bundle.js"></script>
+<script src="./swagger-ui-standalone-preset.js"></script>
+<script>
+window.ui = SwaggerUIBundle({
+  url: {{.Spec}},
+  dom_id: "#swagger-ui",
+  deepLinking: true,
+  presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+  plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+  layout: "StandaloneLayout"
+});
+</script>
+</body>
+</html>
+`))
+
+var redocPage = template.Must(template.New("redoc").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<redoc spec-url="{{.Spec}}"></redoc>
+<script src="./redoc.standalone.js"></script>
+</body>
+</html>
+`))
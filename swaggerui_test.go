@@ -0,0 +1,41 @@
+package ginx
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMountRedocServesLocalBundle(t *testing.T) {
+	RedocBundle = fstest.MapFS{redocBundleFile: {Data: []byte("/* redoc */")}}
+	t.Cleanup(func() { RedocBundle = nil })
+
+	e := newTestEngine(t, nil)
+	e.MountRedoc("/docs", SpecURL("/openapi.json"))
+
+	w := serveFrom(e, http.MethodGet, "/docs/", "127.0.0.1:4000")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `src="./redoc.standalone.js"`) {
+		t.Fatalf("page = %d %q", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "https://") {
+		t.Fatalf("page loads a remote script: %q", w.Body.String())
+	}
+	w = serveFrom(e, http.MethodGet, "/docs/redoc.standalone.js", "127.0.0.1:4000")
+	if w.Code != http.StatusOK || w.Body.String() != "/* redoc */" {
+		t.Fatalf("bundle = %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestMountRedocWithoutBundleFallsBackToSwaggerUI(t *testing.T) {
+	e := newTestEngine(t, nil)
+	e.MountRedoc("/docs", SpecURL("/openapi.json"))
+
+	w := serveFrom(e, http.MethodGet, "/docs/", "127.0.0.1:4000")
+	if !strings.Contains(w.Body.String(), "swagger-ui-bundle.js") {
+		t.Fatalf("page = %q, want Swagger UI", w.Body.String())
+	}
+	if w := serveFrom(e, http.MethodGet, "/docs/swagger-ui-bundle.js", "127.0.0.1:4000"); w.Code != http.StatusOK {
+		t.Fatalf("swagger asset status = %d", w.Code)
+	}
+}
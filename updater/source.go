@@ -0,0 +1,68 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// Release 一个可供升级的版本
+type Release struct {
+	// Version 版本号，与当前进程版本不同时视为新版本
+	Version string `json:"version"`
+	// URL 可执行文件的下载地址，可以是 S3 等对象存储的预签名地址
+	URL string `json:"url"`
+	// SHA256 可执行文件的 SHA-256（十六进制）
+	SHA256 string `json:"sha256"`
+	// Signature 使用 Ed25519 私钥对可执行文件 SHA-256 摘要（32 字节）的签名，JSON 中为 base64
+	Signature []byte `json:"signature"`
+}
+
+// Source 查询最新版本的来源，返回 nil 表示暂无可用版本；
+// 可自行实现以对接 OCI 仓库等发布渠道
+type Source interface {
+	Latest(ctx context.Context) (*Release, error)
+}
+
+// SourceFunc 函数形式的 Source
+type SourceFunc func(ctx context.Context) (*Release, error)
+
+func (f SourceFunc) Latest(ctx context.Context) (*Release, error) {
+	return f(ctx)
+}
+
+// HTTPSource 从 url 获取 JSON 格式的 Release 清单，url 中的 {os} 与 {arch} 会被替换为当前平台，
+// 清单不存在（404）时视为暂无可用版本
+func HTTPSource(url string, client *http.Client) Source {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	url = strings.NewReplacer("{os}", runtime.GOOS, "{arch}", runtime.GOARCH).Replace(url)
+
+	return SourceFunc(func(ctx context.Context) (*Release, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create manifest request: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch manifest: unexpected status %d", resp.StatusCode)
+		}
+		var r Release
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			return nil, fmt.Errorf("failed to decode manifest: %w", err)
+		}
+		return &r, nil
+	})
+}
@@ -0,0 +1,195 @@
+// Package updater 定期查询发布渠道，下载并校验新版本的可执行文件，交由平滑升级切换到新版本，
+// 适用于无部署系统的边缘环境
+package updater
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultInterval 默认检查间隔
+const DefaultInterval = 10 * time.Minute
+
+// Config 自动更新配置
+type Config struct {
+	// Source 版本来源
+	Source Source
+	// Version 当前进程的版本，Release.Version 与之不同时才会更新
+	Version string
+	// PublicKey 验证 Release.Signature 的 Ed25519 公钥
+	PublicKey ed25519.PublicKey
+	// Interval 检查间隔，为 0 时使用 DefaultInterval
+	Interval time.Duration
+	// Dir 新版本的下载目录，为空时使用当前可执行文件所在目录
+	Dir string
+	// Client 下载使用的 HTTP 客户端，为 nil 时使用 http.DefaultClient
+	Client *http.Client
+	Logger *zap.Logger
+}
+
+// Updater 自动更新器
+type Updater struct {
+	conf Config
+
+	mu sync.Mutex
+	// failed 升级失败的版本，不再重复尝试
+	failed map[string]bool
+}
+
+// New 创建自动更新器
+func New(conf Config) (*Updater, error) {
+	if conf.Source == nil {
+		return nil, errors.New("updater: source is required")
+	}
+	if conf.Version == "" {
+		return nil, errors.New("updater: current version is required")
+	}
+	if len(conf.PublicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("updater: invalid ed25519 public key")
+	}
+	if conf.Interval <= 0 {
+		conf.Interval = DefaultInterval
+	}
+	if conf.Dir == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get executable path: %w", err)
+		}
+		conf.Dir = filepath.Dir(exe)
+	}
+	if conf.Client == nil {
+		conf.Client = http.DefaultClient
+	}
+	if conf.Logger == nil {
+		conf.Logger = zap.NewNop()
+	}
+	return &Updater{conf: conf, failed: make(map[string]bool)}, nil
+}
+
+// Run 每隔 Interval 检查一次新版本，下载校验后调用 apply(path, sha256) 升级，直到 ctx 被取消
+func (u *Updater) Run(ctx context.Context, apply func(path, checksum string) error) {
+	ticker := time.NewTicker(u.conf.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := u.Check(ctx, apply); err != nil && ctx.Err() == nil {
+			u.conf.Logger.Error("Self update failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check 检查一次新版本，有新版本时下载校验并调用 apply，返回是否执行了升级
+func (u *Updater) Check(ctx context.Context, apply func(path, checksum string) error) (bool, error) {
+	r, err := u.conf.Source.Latest(ctx)
+	if err != nil {
+		return false, err
+	}
+	if r == nil || r.Version == "" || r.Version == u.conf.Version {
+		return false, nil
+	}
+	u.mu.Lock()
+	failed := u.failed[r.Version]
+	u.mu.Unlock()
+	if failed {
+		return false, nil
+	}
+
+	logger := u.conf.Logger.With(zap.String("version", r.Version), zap.String("current", u.conf.Version))
+	logger.Info("New release found, downloading", zap.String("url", r.URL))
+	path, err := u.download(ctx, r)
+	if err != nil {
+		return false, err
+	}
+
+	logger.Info("Release verified, upgrading", zap.String("path", path))
+	if err := apply(path, r.SHA256); err != nil {
+		u.mu.Lock()
+		u.failed[r.Version] = true
+		u.mu.Unlock()
+		os.Remove(path)
+		return false, fmt.Errorf("failed to upgrade to %s: %w", r.Version, err)
+	}
+	return true, nil
+}
+
+// download 下载 Release 到临时文件，校验摘要与签名后重命名为最终路径
+func (u *Updater) download(ctx context.Context, r *Release) (string, error) {
+	if r.URL == "" {
+		return "", fmt.Errorf("release %s has no download url", r.Version)
+	}
+	want, err := hex.DecodeString(r.SHA256)
+	if err != nil || len(want) != sha256.Size {
+		return "", fmt.Errorf("release %s has invalid sha256", r.Version)
+	}
+	if !ed25519.Verify(u.conf.PublicKey, want, r.Signature) {
+		return "", fmt.Errorf("release %s has invalid signature", r.Version)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.URL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := u.conf.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download release: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download release: unexpected status %d", resp.StatusCode)
+	}
+
+	tmp, err := os.CreateTemp(u.conf.Dir, ".ginx-update-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), resp.Body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download release: %w", err)
+	}
+	if got := h.Sum(nil); !strings.EqualFold(hex.EncodeToString(got), r.SHA256) {
+		return "", fmt.Errorf("checksum mismatch for release %s: got %x", r.Version, got)
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return "", fmt.Errorf("failed to chmod release: %w", err)
+	}
+
+	exe, _ := os.Executable()
+	path := filepath.Join(u.conf.Dir, fmt.Sprintf("%s-%s", filepath.Base(exe), sanitize(r.Version)))
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to install release: %w", err)
+	}
+	return path, nil
+}
+
+// sanitize 去除版本号中不适合出现在文件名中的字符
+func sanitize(version string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, version)
+}
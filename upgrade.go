@@ -0,0 +1,145 @@
+package ginx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/metrics"
+	"github.com/gaoxin19/ginx/report"
+	"github.com/gaoxin19/ginx/upgrader"
+)
+
+// ListenPacket 创建数据报套接字（如 UDP），以 Run 或 GracefulRun 运行时升级后新进程会继承同一套接字，
+// 需在启动钩子中调用；服务未运行时直接创建新的套接字
+func (e *Engine) ListenPacket(network, addr string) (net.PacketConn, error) {
+	e.hooksMu.Lock()
+	listen := e.listenPacket
+	e.hooksMu.Unlock()
+	if listen == nil {
+		listen = net.ListenPacket
+	}
+	return listen(network, addr)
+}
+
+// Handoff 返回升级时向新进程传递文件与状态数据的入口：在启动钩子中通过 File、State 取回父进程传递的内容，
+// 通过 AddFile、SetState 登记需要传递的内容；未继承的文件会在就绪后关闭。
+// 以 GracefulServe 运行或服务未运行时返回 nil
+func (e *Engine) Handoff() upgrader.Handoff {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	return e.handoff
+}
+
+// UpgradeStatus 当前进程的平滑升级统计
+type UpgradeStatus struct {
+	Attempts    int        `json:"attempts"`
+	Successes   int        `json:"successes"`
+	Failures    int        `json:"failures"`
+	LastUpgrade *time.Time `json:"last_upgrade,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	PID         int        `json:"pid"`
+	// ParentPID 由升级启动时为旧进程 PID
+	ParentPID int `json:"parent_pid,omitempty"`
+	// ChildPID 最近一次升级启动的新进程 PID，无法获取时为 0
+	ChildPID int `json:"child_pid,omitempty"`
+	// LastDrain 最近一次排空耗时
+	LastDrain string `json:"last_drain,omitempty"`
+}
+
+// upgradeStats 升级统计，同时更新 Prometheus 指标
+type upgradeStats struct {
+	mu     sync.Mutex
+	status UpgradeStatus
+}
+
+func (s *upgradeStats) started(hasParent bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.PID = os.Getpid()
+	if hasParent {
+		s.status.ParentPID = os.Getppid()
+	}
+}
+
+func (s *upgradeStats) succeeded(childPID int) {
+	now := time.Now()
+	metrics.UpgradeAttempts.Inc()
+	metrics.UpgradeSuccesses.Inc()
+	metrics.LastUpgradeTimestamp.Set(float64(now.Unix()))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Attempts++
+	s.status.Successes++
+	s.status.LastUpgrade = &now
+	s.status.LastError = ""
+	s.status.ChildPID = childPID
+}
+
+func (s *upgradeStats) failed(err error) {
+	metrics.UpgradeAttempts.Inc()
+	metrics.UpgradeFailures.Inc()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Attempts++
+	s.status.Failures++
+	s.status.LastError = err.Error()
+}
+
+func (s *upgradeStats) drained(d time.Duration) {
+	metrics.DrainDuration.Observe(d.Seconds())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.LastDrain = d.String()
+}
+
+func (s *upgradeStats) snapshot() UpgradeStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.status
+	if status.PID == 0 {
+		status.PID = os.Getpid()
+	}
+	return status
+}
+
+// UpgradeStatus 返回当前进程的平滑升级统计
+func (e *Engine) UpgradeStatus() UpgradeStatus {
+	return e.upgrades.snapshot()
+}
+
+func (e *Engine) onUpgradeSuccess(childPID int) {
+	e.upgrades.succeeded(childPID)
+	e.logger.Info("Upgrade succeeded", zap.Int("child_pid", childPID))
+}
+
+// onUpgradeFailure 记录升级失败统计，恢复被新进程接管的控制套接字，并通过已启用的错误上报发送告警
+func (e *Engine) onUpgradeFailure(attempt int, err error) {
+	e.upgrades.failed(err)
+	if e.control != nil {
+		e.control.restore()
+	}
+
+	e.hooksMu.Lock()
+	reporters := e.reporters
+	e.hooksMu.Unlock()
+	for _, r := range reporters {
+		r.Report(context.Background(), report.Event{Err: fmt.Errorf("upgrade attempt %d failed: %w", attempt, err)})
+	}
+}
+
+// SetReloadOptions 设置每次平滑重启前调用的函数，用于调整新进程的可执行文件、参数与环境变量，
+// 仅 graceful 升级方式支持，需在 Run 或 GracefulRun 之前调用
+func (e *Engine) SetReloadOptions(fn func(opts *upgrader.ReloadOptions) error) {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	e.prepareReload = fn
+}
@@ -0,0 +1,48 @@
+package upgrader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// ErrBinaryUnsupported 当前升级方式不支持切换可执行文件
+var ErrBinaryUnsupported = errors.New("upgrading into another binary is not supported")
+
+// ErrChecksumRequired 升级到其它可执行文件时未提供 SHA-256
+var ErrChecksumRequired = errors.New("sha256 checksum is required to upgrade into another binary")
+
+// VerifyBinary 检查 path 是可执行的普通文件并校验其 SHA-256（十六进制），checksum 必填
+func VerifyBinary(path, checksum string) error {
+	if checksum == "" {
+		return ErrChecksumRequired
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat binary: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("binary %s is not a regular file", path)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0 {
+		return fmt.Errorf("binary %s is not executable", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open binary: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("failed to read binary: %w", err)
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(sum, checksum) {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", path, sum, checksum)
+	}
+	return nil
+}
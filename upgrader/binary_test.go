@@ -0,0 +1,38 @@
+package upgrader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeBinary(t *testing.T, content string) (string, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app")
+	if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(content))
+	return path, hex.EncodeToString(sum[:])
+}
+
+func TestVerifyBinary(t *testing.T) {
+	path, sum := writeBinary(t, "#!/bin/sh\nexit 0\n")
+
+	if err := VerifyBinary(path, ""); !errors.Is(err, ErrChecksumRequired) {
+		t.Fatalf("empty checksum: err = %v, want ErrChecksumRequired", err)
+	}
+	if err := VerifyBinary(path, strings.Repeat("0", 64)); err == nil {
+		t.Fatal("mismatched checksum accepted")
+	}
+	if err := VerifyBinary(path, strings.ToUpper(sum)); err != nil {
+		t.Fatalf("valid checksum rejected: %v", err)
+	}
+	if err := VerifyBinary(filepath.Join(t.TempDir(), "missing"), sum); err == nil {
+		t.Fatal("missing binary accepted")
+	}
+}
@@ -0,0 +1,212 @@
+package upgrader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// fallback 不支持 fork 与文件描述符继承的平台（如 Windows）上的降级实现：
+// 升级时在当前进程内重新创建监听器，已建立的连接与正在处理的请求不受影响
+type fallback struct {
+	logger *zap.Logger
+	opts   Options
+
+	mu  sync.Mutex
+	lns []*relistener
+	// upgrading 防止并发的升级交错重建监听器
+	upgrading atomic.Bool
+
+	exit     chan struct{}
+	stopOnce sync.Once
+}
+
+func newFallback(logger *zap.Logger, opts Options) *fallback {
+	return &fallback{
+		logger: logger,
+		opts:   opts,
+		exit:   make(chan struct{}),
+	}
+}
+
+func (f *fallback) Listen(network, addr string) (net.Listener, error) {
+	ln, err := listenReusable(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create listener: %w", err)
+	}
+	// 记录实际绑定的地址，端口为 0 时重新监听仍使用同一端口
+	rl := &relistener{network: network, addr: ln.Addr().String(), ln: ln}
+	f.mu.Lock()
+	f.lns = append(f.lns, rl)
+	f.mu.Unlock()
+	return rl, nil
+}
+
+func (f *fallback) ListenPacket(network, addr string) (net.PacketConn, error) {
+	conn, err := net.ListenPacket(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create packet conn: %w", err)
+	}
+	return conn, nil
+}
+
+func (f *fallback) HasParent() bool {
+	return false
+}
+
+func (f *fallback) Ready() error {
+	if f.opts.PIDFile == "" {
+		return nil
+	}
+	return WritePIDFile(f.opts.PIDFile)
+}
+
+// Upgrade 依次重新创建所有监听器，已有升级进行中时返回 ErrUpgradeInProgress
+func (f *fallback) Upgrade() error {
+	if !f.upgrading.CompareAndSwap(false, true) {
+		return ErrUpgradeInProgress
+	}
+	defer f.upgrading.Store(false)
+
+	return retryUpgrade(f.logger, f.opts, func() (int, error) {
+		f.mu.Lock()
+		lns := append([]*relistener(nil), f.lns...)
+		f.mu.Unlock()
+
+		start := time.Now()
+		for _, rl := range lns {
+			if err := rl.relisten(); err != nil {
+				return 0, err
+			}
+		}
+		f.logger.Info("Listeners recreated in process",
+			zap.Int("count", len(lns)),
+			zap.Duration("elapsed", time.Since(start)),
+		)
+		// 没有派生新进程
+		return 0, nil
+	})
+}
+
+// UpgradeBinary 进程内重启无法切换可执行文件
+func (f *fallback) UpgradeBinary(path string) error {
+	return ErrBinaryUnsupported
+}
+
+func (f *fallback) WatchSignal() {
+	if len(f.opts.signals()) == 0 {
+		return
+	}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, f.opts.signals()...)
+		defer signal.Stop(sig)
+
+		for {
+			select {
+			case <-sig:
+				if err := f.Upgrade(); err != nil {
+					f.logger.Error("Upgrade failed", zap.Error(err))
+				}
+			case <-f.exit:
+				return
+			}
+		}
+	}()
+}
+
+func (f *fallback) Exit() <-chan struct{} {
+	return f.exit
+}
+
+func (f *fallback) Stop() {
+	f.stopOnce.Do(func() {
+		close(f.exit)
+	})
+}
+
+func (f *fallback) ShutdownTimeout() time.Duration {
+	return f.opts.shutdownTimeout()
+}
+
+// 进程内重启不需要交接，文件与状态保留在当前进程中
+func (f *fallback) AddFile(name string, file *os.File) error { return nil }
+func (f *fallback) File(name string) (*os.File, error)       { return nil, nil }
+func (f *fallback) SetState(fn func() ([]byte, error))       {}
+func (f *fallback) State() ([]byte, error)                   { return nil, nil }
+
+// relistener 可在原地址重新监听的监听器，替换期间 Accept 不会返回错误
+type relistener struct {
+	network, addr string
+
+	mu     sync.Mutex
+	ln     net.Listener
+	gen    int
+	closed bool
+}
+
+func (r *relistener) Accept() (net.Conn, error) {
+	for {
+		r.mu.Lock()
+		ln, gen := r.ln, r.gen
+		r.mu.Unlock()
+
+		conn, err := ln.Accept()
+		if err == nil {
+			return conn, nil
+		}
+		r.mu.Lock()
+		swapped := r.gen != gen && !r.closed
+		r.mu.Unlock()
+		if !swapped {
+			return nil, err
+		}
+	}
+}
+
+// relisten 先在同一地址创建新监听器，成功后再替换并关闭旧监听器；
+// 创建失败时保留旧监听器继续服务
+func (r *relistener) relisten() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return errors.New("listener closed")
+	}
+
+	ln, err := listenReusable(r.network, r.addr)
+	if err != nil {
+		return fmt.Errorf("failed to relisten on %s: %w", r.addr, err)
+	}
+	old := r.ln
+	r.ln = ln
+	r.gen++
+	old.Close()
+	return nil
+}
+
+// listenReusable 以地址复用方式监听，使 relisten 期间新旧监听器可以同时存在
+func listenReusable(network, addr string) (net.Listener, error) {
+	lc := net.ListenConfig{Control: reuseControl}
+	return lc.Listen(context.Background(), network, addr)
+}
+
+func (r *relistener) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return r.ln.Close()
+}
+
+func (r *relistener) Addr() net.Addr {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ln.Addr()
+}
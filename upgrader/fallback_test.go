@@ -0,0 +1,65 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package upgrader
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestFallbackRelisten(t *testing.T) {
+	f := newFallback(zap.NewNop(), Options{})
+	ln, err := f.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	addr := ln.Addr().String()
+
+	accepted := make(chan error, 1)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				accepted <- err
+				return
+			}
+			conn.Close()
+			accepted <- nil
+		}
+	}()
+	dial := func() {
+		t.Helper()
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+		if err := <-accepted; err != nil {
+			t.Fatalf("accept: %v", err)
+		}
+	}
+
+	dial()
+	if err := f.Upgrade(); err != nil {
+		t.Fatal(err)
+	}
+	if got := ln.Addr().String(); got != addr {
+		t.Fatalf("addr after upgrade = %s, want %s", got, addr)
+	}
+	dial()
+
+	f.upgrading.Store(true)
+	if err := f.Upgrade(); !errors.Is(err, ErrUpgradeInProgress) {
+		t.Fatalf("concurrent upgrade err = %v, want ErrUpgradeInProgress", err)
+	}
+	f.upgrading.Store(false)
+
+	ln.Close()
+	if err := <-accepted; !errors.Is(err, net.ErrClosed) {
+		t.Fatalf("accept after close = %v, want net.ErrClosed", err)
+	}
+}
@@ -0,0 +1,58 @@
+package upgrader
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// envFDs 父进程传递给新进程的文件描述符表，格式为 "network|addr=fd,..."
+const envFDs = "GRACEFUL_FDS"
+
+// filer 可转换为文件描述符的监听器或连接
+type filer interface {
+	File() (*os.File, error)
+}
+
+func fdKey(network, addr string) string {
+	return network + "|" + addr
+}
+
+// parseFDs 解析从父进程继承的文件描述符表；只设置了 GRACEFUL_RESTART 时兼容旧格式，
+// 第一个监听器使用 fd 3
+func parseFDs() (map[string]*os.File, bool, error) {
+	if os.Getenv(envRestart) != "true" {
+		return nil, false, nil
+	}
+	value, ok := os.LookupEnv(envFDs)
+	if !ok {
+		return nil, true, nil
+	}
+
+	files := make(map[string]*os.File)
+	for _, item := range strings.Split(value, ",") {
+		if item == "" {
+			continue
+		}
+		i := strings.LastIndexByte(item, '=')
+		if i < 0 {
+			return nil, false, fmt.Errorf("invalid %s entry %q", envFDs, item)
+		}
+		fd, err := strconv.Atoi(item[i+1:])
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid %s entry %q: %w", envFDs, item, err)
+		}
+		files[item[:i]] = os.NewFile(uintptr(fd), item[:i])
+	}
+	return files, false, nil
+}
+
+// encodeFDs 编码文件描述符表，keys 依次对应从 3 开始的描述符
+func encodeFDs(keys []string) string {
+	items := make([]string, len(keys))
+	for i, key := range keys {
+		items[i] = key + "=" + strconv.Itoa(3+i)
+	}
+	return strings.Join(items, ",")
+}
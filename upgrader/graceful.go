@@ -1,50 +1,99 @@
 package upgrader
 
 import (
-	"context"
 	"fmt"
 	"net"
 	"os"
 	"os/signal"
-	"syscall"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 )
 
+const (
+	// envRestart 标记当前进程由平滑重启启动，需继承父进程的监听器
+	envRestart = "GRACEFUL_RESTART"
+	// envReadyFD 新进程用于通知父进程就绪的管道文件描述符
+	envReadyFD = "GRACEFUL_READY_FD"
+)
+
 type GracefulUpgrader struct {
 	logger *zap.Logger
-	ln     net.Listener
 	pid    int
 	ppid   int
+	opts   Options
+
+	mu sync.Mutex
+	// inherited 从父进程继承、尚未被使用的文件
+	inherited map[string]*os.File
+	// legacy 父进程只传递了 fd 3
+	legacy bool
+	parent bool
+	// keys 与 fds 按创建顺序记录当前进程的监听器，升级时依次传递给新进程
+	keys []string
+	fds  map[string]filer
+	// files 通过 AddFile 登记的文件，由调用方负责关闭
+	files map[string]*os.File
+	state func() ([]byte, error)
+	// prepare 每次重启前调整新进程的启动参数
+	prepare func(*ReloadOptions) error
+	// binary 由 UpgradeBinary 指定的可执行文件，仅在本次升级中生效
+	binary string
+	// upgrading 防止多个升级同时派生新进程，升级成功后保持置位，当前进程即将退出
+	upgrading atomic.Bool
+
+	exit     chan struct{}
+	stopOnce sync.Once
 }
 
-func NewGracefulUpgrader(logger *zap.Logger) *GracefulUpgrader {
-	return &GracefulUpgrader{
+func NewGracefulUpgrader(logger *zap.Logger, opts Options) *GracefulUpgrader {
+	g := &GracefulUpgrader{
 		logger: logger,
+		opts:   opts,
 		pid:    os.Getpid(),
 		ppid:   os.Getppid(),
+		fds:    make(map[string]filer),
+		files:  make(map[string]*os.File),
+		exit:   make(chan struct{}),
+
+		prepare: opts.Reload,
 	}
+	inherited, legacy, err := parseFDs()
+	if err != nil {
+		logger.Error("Failed to parse inherited fds", zap.Error(err))
+	}
+	g.inherited, g.legacy = inherited, legacy
+	g.parent = os.Getenv(envRestart) == "true"
+	return g
 }
 
-// Listen 创建或继承 listener
+// Listen 创建或继承 listener，同一 network 与 address 在平滑重启后从父进程继承
 func (g *GracefulUpgrader) Listen(network, address string) (net.Listener, error) {
-	// 检查是否从父进程继承了文件描述符
-	if os.Getenv("GRACEFUL_RESTART") == "true" {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := fdKey(network, address)
+	if _, ok := g.fds[key]; ok {
+		return nil, fmt.Errorf("listener %s %s already exists", network, address)
+	}
+
+	f := g.takeInherited(key)
+	if f != nil {
 		g.logger.Info("Inheriting listener from parent process",
+			zap.String("addr", address),
 			zap.Int("pid", g.pid),
 			zap.Int("ppid", g.ppid),
 		)
-
-		// 获取继承的文件描述符
-		f := os.NewFile(3, "") // 3 是第一个可用的文件描述符
 		ln, err := net.FileListener(f)
+		f.Close()
 		if err != nil {
 			return nil, fmt.Errorf("failed to inherit listener: %w", err)
 		}
-		f.Close()
-		g.ln = ln
-		return ln, nil
+		return ln, g.track(key, ln)
 	}
 
 	// 创建新的 listener
@@ -52,89 +101,442 @@ func (g *GracefulUpgrader) Listen(network, address string) (net.Listener, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create listener: %w", err)
 	}
-	g.ln = ln
-	return ln, nil
+	return ln, g.track(key, ln)
+}
+
+// ListenPacket 创建或继承数据报套接字，同一 network 与 address 在平滑重启后从父进程继承
+func (g *GracefulUpgrader) ListenPacket(network, address string) (net.PacketConn, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := fdKey(network, address)
+	if _, ok := g.fds[key]; ok {
+		return nil, fmt.Errorf("packet conn %s %s already exists", network, address)
+	}
+
+	if f, ok := g.inherited[key]; ok {
+		delete(g.inherited, key)
+		g.logger.Info("Inheriting packet conn from parent process",
+			zap.String("addr", address),
+			zap.Int("pid", g.pid),
+			zap.Int("ppid", g.ppid),
+		)
+		conn, err := net.FilePacketConn(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to inherit packet conn: %w", err)
+		}
+		return conn, g.track(key, conn)
+	}
+
+	conn, err := net.ListenPacket(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create packet conn: %w", err)
+	}
+	return conn, g.track(key, conn)
+}
+
+// AddFile 登记升级时需要传递给新进程的文件，同名文件会被覆盖
+func (g *GracefulUpgrader) AddFile(name string, f *os.File) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.files[fdKey("file", name)] = f
+	return nil
+}
+
+// File 返回从父进程继承的文件，不存在时返回 nil
+func (g *GracefulUpgrader) File(name string) (*os.File, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	f, ok := g.inherited[fdKey("file", name)]
+	if !ok {
+		return nil, nil
+	}
+	delete(g.inherited, fdKey("file", name))
+	return f, nil
+}
+
+// SetState 设置升级前调用的状态导出函数
+func (g *GracefulUpgrader) SetState(fn func() ([]byte, error)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.state = fn
+}
+
+// State 返回父进程导出的状态数据，不存在时返回 nil
+func (g *GracefulUpgrader) State() ([]byte, error) {
+	f, err := g.File(stateName)
+	if err != nil {
+		return nil, err
+	}
+	return readState(f)
+}
+
+// takeInherited 取出继承的文件，兼容只传递 fd 3 的旧版本父进程
+func (g *GracefulUpgrader) takeInherited(key string) *os.File {
+	if g.legacy {
+		g.legacy = false
+		return os.NewFile(3, key)
+	}
+	f, ok := g.inherited[key]
+	if ok {
+		delete(g.inherited, key)
+	}
+	return f
 }
 
-// Reload 执行平滑重启
+func (g *GracefulUpgrader) track(key string, v any) error {
+	f, ok := v.(filer)
+	if !ok {
+		return fmt.Errorf("%s can not be passed to child process", key)
+	}
+	g.keys = append(g.keys, key)
+	g.fds[key] = f
+	return nil
+}
+
+// closeUnused 关闭继承后未被使用的文件
+func (g *GracefulUpgrader) closeUnused() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.legacy = false
+	for key, f := range g.inherited {
+		f.Close()
+		delete(g.inherited, key)
+	}
+}
+
+// ReloadOptions 平滑重启时新进程的启动参数
+type ReloadOptions struct {
+	// Executable 可执行文件路径，默认为当前进程的可执行文件
+	Executable string
+	// Args 进程参数，包含 argv[0]，默认为 os.Args
+	Args []string
+	// Env 环境变量，格式为 KEY=VALUE，默认为当前进程的环境变量；
+	// 继承监听器所需的变量会在此基础上覆盖
+	Env []string
+	// Dir 工作目录，为空时与当前进程相同
+	Dir string
+}
+
+// SetReloadOptions 设置每次平滑重启前调用的函数，可修改新进程的可执行文件、参数、环境变量与工作目录，
+// 如递增代数计数、切换配置文件或指向新版本的可执行文件；返回错误时取消本次重启
+func (g *GracefulUpgrader) SetReloadOptions(fn func(opts *ReloadOptions) error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.prepare = fn
+}
+
+// reloadOptions 返回以当前进程为默认值、经 SetReloadOptions 调整后的启动参数
+func (g *GracefulUpgrader) reloadOptions() (*ReloadOptions, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executable path: %w", err)
+	}
+	g.mu.Lock()
+	prepare, binary := g.prepare, g.binary
+	g.mu.Unlock()
+	if binary != "" {
+		executable = binary
+	}
+
+	ro := &ReloadOptions{
+		Executable: executable,
+		Args:       append([]string(nil), os.Args...),
+		Env:        os.Environ(),
+	}
+	if prepare != nil {
+		if err := prepare(ro); err != nil {
+			return nil, fmt.Errorf("failed to prepare reload: %w", err)
+		}
+	}
+	if len(ro.Args) == 0 {
+		ro.Args = []string{ro.Executable}
+	}
+	return ro, nil
+}
+
+// HasParent 当前进程是否由平滑重启启动
+func (g *GracefulUpgrader) HasParent() bool {
+	return g.parent
+}
+
+// Ready 标记当前进程已就绪：配置了 PIDFile 时写入当前 PID，由平滑重启启动时通知父进程
+func (g *GracefulUpgrader) Ready() error {
+	g.closeUnused()
+
+	if g.opts.PIDFile != "" {
+		if err := WritePIDFile(g.opts.PIDFile); err != nil {
+			return err
+		}
+	}
+
+	fd := os.Getenv(envReadyFD)
+	if fd == "" {
+		return nil
+	}
+	os.Unsetenv(envReadyFD)
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", envReadyFD, err)
+	}
+	f := os.NewFile(uintptr(n), "ready")
+	defer f.Close()
+	if _, err := f.Write([]byte{1}); err != nil {
+		return fmt.Errorf("failed to notify parent process: %w", err)
+	}
+	return nil
+}
+
+// Reload 执行平滑重启：启动新进程并等待其就绪，新进程退出或超时未就绪时终止新进程并返回错误，
+// 当前进程继续服务
 func (g *GracefulUpgrader) Reload() error {
+	_, err := g.reload()
+	return err
+}
+
+// reload 执行平滑重启并返回新进程 PID
+func (g *GracefulUpgrader) reload() (int, error) {
 	g.logger.Info("Starting graceful reload",
 		zap.Int("old_pid", g.pid),
 	)
 
-	// 获取当前可执行文件路径
-	executable, err := os.Executable()
+	ro, err := g.reloadOptions()
 	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
+		return 0, err
 	}
 
-	// 将 listener 转换为文件描述符
-	listenerFile, err := g.ln.(*net.TCPListener).File()
-	if err != nil {
-		return fmt.Errorf("failed to get listener file: %w", err)
+	// 将 listener 转换为文件描述符，依次占用从 3 开始的描述符
+	g.mu.Lock()
+	keys := append([]string(nil), g.keys...)
+	files := []*os.File{os.Stdin, os.Stdout, os.Stderr}
+	for _, key := range keys {
+		f, err := g.fds[key].File()
+		if err != nil {
+			g.mu.Unlock()
+			closeFiles(files[3:])
+			return 0, fmt.Errorf("failed to get file of %s: %w", key, err)
+		}
+		files = append(files, f)
 	}
-	defer listenerFile.Close()
+	owned := len(files)
+	for key, f := range g.files {
+		keys = append(keys, key)
+		files = append(files, f)
+	}
+	state := g.state
+	g.mu.Unlock()
+	defer closeFiles(files[3:owned])
 
-	// 准备环境变量
-	env := append(os.Environ(), "GRACEFUL_RESTART=true")
+	if state != nil {
+		f, err := stateFile(state)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		keys = append(keys, fdKey("file", stateName))
+		files = append(files, f)
+	}
+
+	// 新进程就绪后向管道写入一个字节
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return 0, fmt.Errorf("failed to create ready pipe: %w", err)
+	}
+	defer readyR.Close()
 
 	// 创建新进程
-	process, err := os.StartProcess(executable, os.Args, &os.ProcAttr{
-		Env:   env,
-		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr, listenerFile},
+	process, err := os.StartProcess(ro.Executable, ro.Args, &os.ProcAttr{
+		Dir: ro.Dir,
+		Env: childEnv(ro.Env, map[string]string{
+			envRestart: "true",
+			envFDs:     encodeFDs(keys),
+			envReadyFD: strconv.Itoa(len(files)),
+		}),
+		Files: append(files, readyW),
 	})
+	readyW.Close()
 	if err != nil {
-		return fmt.Errorf("failed to start new process: %w", err)
+		return 0, fmt.Errorf("failed to start new process: %w", err)
 	}
 
-	g.logger.Info("Started new process",
+	g.logger.Info("Started new process, waiting for it to become ready",
 		zap.Int("new_pid", process.Pid),
 	)
+	exited, err := g.waitReady(process, readyR)
+	if err != nil {
+		return 0, err
+	}
+	if err := g.waitStable(process, exited); err != nil {
+		return 0, err
+	}
 
-	return nil
+	g.logger.Info("New process is ready",
+		zap.Int("new_pid", process.Pid),
+	)
+	return process.Pid, nil
 }
 
-// WaitForSignal 等待信号并处理
-func (g *GracefulUpgrader) WaitForSignal(server interface {
-	Shutdown(context.Context) error
-}) error {
-	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGINT)
-
-	for {
-		sig := <-signalChan
-		switch sig {
-		case syscall.SIGHUP:
-			// 收到 HUP 信号，执行平滑重启
-			if err := g.Reload(); err != nil {
-				g.logger.Error("Failed to reload", zap.Error(err))
-				continue
-			}
+// waitReady 等待新进程通过管道报告就绪，失败时终止新进程；返回的通道在新进程退出时收到原因
+func (g *GracefulUpgrader) waitReady(process *os.Process, ready *os.File) (<-chan error, error) {
+	exited := make(chan error, 1)
+	go func() {
+		state, err := process.Wait()
+		if err == nil {
+			err = fmt.Errorf("new process exited: %s", state)
+		}
+		exited <- err
+	}()
 
-			// 等待新进程启动后优雅关闭当前进程
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			defer cancel()
+	notified := make(chan error, 1)
+	go func() {
+		_, err := ready.Read(make([]byte, 1))
+		notified <- err
+	}()
 
-			if err := server.Shutdown(ctx); err != nil {
-				g.logger.Error("Failed to shutdown", zap.Error(err))
-				return err
-			}
+	timer := time.NewTimer(g.opts.upgradeTimeout())
+	defer timer.Stop()
 
-			g.logger.Info("Graceful reload completed", zap.Int("pid", g.pid))
-			return nil
+	select {
+	case err := <-notified:
+		if err == nil {
+			return exited, nil
+		}
+		// 管道在未写入时被关闭，说明新进程已退出
+		process.Kill()
+		return nil, <-exited
+	case err := <-exited:
+		return nil, err
+	case <-timer.C:
+		process.Kill()
+		return nil, fmt.Errorf("new process not ready within %s", g.opts.upgradeTimeout())
+	}
+}
 
-		case syscall.SIGTERM, syscall.SIGINT:
-			// 收到终止信号，执行优雅关闭
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			defer cancel()
+// waitStable 在 StableWindow 内观察已就绪的新进程，期间退出视为升级失败，并恢复当前进程的 PID 文件
+func (g *GracefulUpgrader) waitStable(process *os.Process, exited <-chan error) error {
+	window := g.opts.Retry.StableWindow
+	if window <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(window)
+	defer timer.Stop()
 
-			if err := server.Shutdown(ctx); err != nil {
-				g.logger.Error("Failed to shutdown", zap.Error(err))
-				return err
+	select {
+	case <-timer.C:
+		return nil
+	case err := <-exited:
+		if g.opts.PIDFile != "" {
+			if werr := WritePIDFile(g.opts.PIDFile); werr != nil {
+				g.logger.Error("Failed to restore pid file", zap.Error(werr))
 			}
+		}
+		return fmt.Errorf("new process %d exited within stable window: %w", process.Pid, err)
+	}
+}
 
-			g.logger.Info("Graceful shutdown completed", zap.Int("pid", g.pid))
-			return nil
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
+
+// childEnv 返回覆盖了指定变量的环境变量，重复的变量以最后出现的为准
+func childEnv(base []string, vars map[string]string) []string {
+	env := make([]string, 0, len(base)+len(vars))
+	index := make(map[string]int, len(base)+len(vars))
+	set := func(key, kv string) {
+		if i, ok := index[key]; ok {
+			env[i] = kv
+			return
 		}
+		index[key] = len(env)
+		env = append(env, kv)
+	}
+	for _, kv := range base {
+		key, _, _ := strings.Cut(kv, "=")
+		set(key, kv)
+	}
+	for k, v := range vars {
+		set(k, k+"="+v)
+	}
+	return env
+}
+
+// UpgradeBinary 以 path 指定的可执行文件启动新进程并完成升级
+func (g *GracefulUpgrader) UpgradeBinary(path string) error {
+	if !g.upgrading.CompareAndSwap(false, true) {
+		return ErrUpgradeInProgress
+	}
+
+	g.mu.Lock()
+	g.binary = path
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		g.binary = ""
+		g.mu.Unlock()
+	}()
+	return g.upgrade()
+}
+
+// Upgrade 按重试策略启动新进程，成功后关闭 Exit 通道，使当前进程开始优雅关闭；
+// 已有升级进行中时返回 ErrUpgradeInProgress
+func (g *GracefulUpgrader) Upgrade() error {
+	if !g.upgrading.CompareAndSwap(false, true) {
+		return ErrUpgradeInProgress
 	}
+	return g.upgrade()
+}
+
+// upgrade 调用前需已置位 upgrading，失败时复位以允许重试
+func (g *GracefulUpgrader) upgrade() error {
+	if err := retryUpgrade(g.logger, g.opts, g.reload); err != nil {
+		g.upgrading.Store(false)
+		return err
+	}
+	g.Stop()
+	return nil
+}
+
+// WatchSignal 监听升级信号（默认 SIGHUP），收到后执行平滑重启，新进程启动后关闭 Exit 通道
+func (g *GracefulUpgrader) WatchSignal() {
+	if len(g.opts.signals()) == 0 {
+		return
+	}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, g.opts.signals()...)
+		defer signal.Stop(sig)
+
+		for {
+			select {
+			case <-sig:
+				if err := g.Upgrade(); err != nil {
+					g.logger.Error("Failed to reload", zap.Error(err))
+					continue
+				}
+				return
+			case <-g.exit:
+				return
+			}
+		}
+	}()
+}
+
+// Exit 返回一个通道，在平滑重启完成或 Stop 被调用后关闭
+func (g *GracefulUpgrader) Exit() <-chan struct{} {
+	return g.exit
+}
+
+// Stop 停止监听信号并关闭 Exit 通道
+func (g *GracefulUpgrader) Stop() {
+	g.stopOnce.Do(func() {
+		close(g.exit)
+	})
+}
+
+// ShutdownTimeout 返回优雅关闭超时时间
+func (g *GracefulUpgrader) ShutdownTimeout() time.Duration {
+	return g.opts.shutdownTimeout()
 }
@@ -0,0 +1,115 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package upgrader
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// newShellUpgrader 返回以 sh -c script 作为新进程的 GracefulUpgrader，新进程通过 fd 3 报告就绪
+func newShellUpgrader(t *testing.T, script string, opts Options) *GracefulUpgrader {
+	t.Helper()
+	opts.Reload = func(ro *ReloadOptions) error {
+		ro.Executable = "/bin/sh"
+		ro.Args = []string{"sh", "-c", script}
+		return nil
+	}
+	return NewGracefulUpgrader(zap.NewNop(), opts)
+}
+
+func readPID(t *testing.T, path string) int {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pid
+}
+
+func TestGracefulRollbackWithinStableWindow(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "app.pid")
+	var failures int
+	// 新进程就绪后改写 PID 文件，随后在稳定窗口内退出
+	g := newShellUpgrader(t, `printf x >&3; echo $$ > "$PID_FILE"; exit 3`, Options{
+		PIDFile:   pidFile,
+		Retry:     RetryPolicy{StableWindow: 5 * time.Second},
+		OnFailure: func(int, error) { failures++ },
+	})
+	t.Setenv("PID_FILE", pidFile)
+
+	err := g.Upgrade()
+	if err == nil || !strings.Contains(err.Error(), "stable window") {
+		t.Fatalf("err = %v, want stable window failure", err)
+	}
+	if failures != 1 {
+		t.Fatalf("failures = %d, want 1", failures)
+	}
+	if pid := readPID(t, pidFile); pid != os.Getpid() {
+		t.Fatalf("pid file = %d, want restored to %d", pid, os.Getpid())
+	}
+	select {
+	case <-g.Exit():
+		t.Fatal("exit closed after rollback")
+	default:
+	}
+	// 回滚后允许再次升级
+	if err := g.Upgrade(); errors.Is(err, ErrUpgradeInProgress) {
+		t.Fatal("upgrade still marked in progress after rollback")
+	}
+}
+
+func TestGracefulUpgradeSucceeds(t *testing.T) {
+	var child int
+	g := newShellUpgrader(t, `printf x >&3; exec sleep 10`, Options{
+		Retry:     RetryPolicy{StableWindow: 50 * time.Millisecond},
+		OnSuccess: func(pid int) { child = pid },
+	})
+
+	if err := g.Upgrade(); err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Kill(child, syscall.SIGKILL)
+	if child <= 0 {
+		t.Fatalf("child pid = %d", child)
+	}
+	select {
+	case <-g.Exit():
+	default:
+		t.Fatal("exit not closed after upgrade")
+	}
+	if err := g.Upgrade(); !errors.Is(err, ErrUpgradeInProgress) {
+		t.Fatalf("second upgrade err = %v, want ErrUpgradeInProgress", err)
+	}
+}
+
+func TestGracefulUpgradeNotReady(t *testing.T) {
+	tests := map[string]struct {
+		script string
+		want   string
+	}{
+		"exits before ready": {`exit 3`, "exited"},
+		"ready timeout":      {`exec sleep 10`, "not ready within"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := newShellUpgrader(t, tt.script, Options{UpgradeTimeout: 200 * time.Millisecond})
+			err := g.Upgrade()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package upgrader
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// stateName 状态数据在继承文件中的名称
+const stateName = "ginx-state"
+
+// Handoff 升级时从旧进程向新进程传递文件与状态数据
+type Handoff interface {
+	// AddFile 登记升级时需要传递给新进程的文件，新进程通过 File 以相同名称取回
+	AddFile(name string, f *os.File) error
+	// File 返回从父进程继承的文件，不存在时返回 nil
+	File(name string) (*os.File, error)
+	// SetState 设置升级前调用的状态导出函数，返回的数据由新进程通过 State 读取
+	SetState(fn func() ([]byte, error))
+	// State 返回父进程导出的状态数据，不存在时返回 nil
+	State() ([]byte, error)
+}
+
+// stateFile 将状态数据写入已删除的临时文件，只能通过返回的文件描述符访问
+func stateFile(fn func() ([]byte, error)) (*os.File, error) {
+	data, err := fn()
+	if err != nil {
+		return nil, fmt.Errorf("failed to export state: %w", err)
+	}
+	f, err := os.CreateTemp("", stateName+"-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state file: %w", err)
+	}
+	os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write state file: %w", err)
+	}
+	return f, nil
+}
+
+// readState 从继承的状态文件中读取全部数据
+func readState(f *os.File) ([]byte, error) {
+	if f == nil {
+		return nil, nil
+	}
+	defer f.Close()
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	return data, nil
+}
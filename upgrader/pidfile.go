@@ -0,0 +1,55 @@
+package upgrader
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// WritePIDFile 原子地写入当前进程 PID：先写临时文件再重命名，格式与 tableflip 一致
+func WritePIDFile(path string) error {
+	dir, file := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, file+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create pid file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(strconv.Itoa(os.Getpid())); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write pid file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write pid file: %w", err)
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to chmod pid file: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename pid file: %w", err)
+	}
+	return nil
+}
+
+// RemovePIDFile 仅当 PID 文件记录的是当前进程时删除，避免删掉升级后新进程写入的文件
+func RemovePIDFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read pid file: %w", err)
+	}
+	if string(bytes.TrimSpace(data)) != strconv.Itoa(os.Getpid()) {
+		return nil
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove pid file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package upgrader
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RetryPolicy 升级失败时的重试策略
+type RetryPolicy struct {
+	// MaxAttempts 最多尝试次数，为 0 时只尝试一次
+	MaxAttempts int
+	// Backoff 首次重试前的等待时间，之后每次翻倍
+	Backoff time.Duration
+	// MaxBackoff 重试等待时间上限，为 0 时不限制
+	MaxBackoff time.Duration
+	// StableWindow 新进程就绪后需持续运行的时长，期间新进程退出视为升级失败，
+	// 当前进程继续服务；仅 GracefulUpgrader 支持
+	StableWindow time.Duration
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts <= 0 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.Backoff << (attempt - 1)
+	if p.MaxBackoff > 0 && (d > p.MaxBackoff || d <= 0) {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// retryUpgrade 依次执行 BeforeUpgrade、按重试策略执行升级，成功后调用 OnSuccess 并执行 AfterUpgrade，
+// 每次失败都会记录日志并调用 OnFailure；upgrade 返回新进程的 PID，未知时为 0
+func retryUpgrade(logger *zap.Logger, opts Options, upgrade func() (int, error)) error {
+	if opts.BeforeUpgrade != nil {
+		if err := opts.BeforeUpgrade(); err != nil {
+			return fmt.Errorf("upgrade aborted: %w", err)
+		}
+	}
+
+	var err error
+	attempts := opts.Retry.attempts()
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var pid int
+		if pid, err = upgrade(); err == nil {
+			if opts.OnSuccess != nil {
+				opts.OnSuccess(pid)
+			}
+			if opts.AfterUpgrade != nil {
+				opts.AfterUpgrade()
+			}
+			return nil
+		}
+
+		logger.Error("Upgrade attempt failed",
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", attempts),
+			zap.Error(err),
+		)
+		if opts.OnFailure != nil {
+			opts.OnFailure(attempt, err)
+		}
+		if attempt < attempts {
+			time.Sleep(opts.Retry.backoff(attempt))
+		}
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+package upgrader
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestRetryUpgrade(t *testing.T) {
+	var (
+		calls    int
+		failures []int
+		success  int
+		after    bool
+	)
+	opts := Options{
+		Retry:        RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+		OnFailure:    func(attempt int, err error) { failures = append(failures, attempt) },
+		OnSuccess:    func(pid int) { success = pid },
+		AfterUpgrade: func() { after = true },
+	}
+	err := retryUpgrade(zap.NewNop(), opts, func() (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errors.New("child died")
+		}
+		return 42, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 || len(failures) != 2 || failures[1] != 2 || success != 42 || !after {
+		t.Fatalf("calls = %d, failures = %v, success = %d, after = %v", calls, failures, success, after)
+	}
+
+	// 重试次数用尽时返回最后一次的错误，不调用 AfterUpgrade
+	after, calls = false, 0
+	last := errors.New("last")
+	err = retryUpgrade(zap.NewNop(), opts, func() (int, error) {
+		calls++
+		return 0, last
+	})
+	if !errors.Is(err, last) || calls != 3 || after {
+		t.Fatalf("err = %v, calls = %d, after = %v", err, calls, after)
+	}
+
+	// BeforeUpgrade 失败时不执行升级
+	opts.BeforeUpgrade = func() error { return errors.New("draining") }
+	calls = 0
+	if err := retryUpgrade(zap.NewNop(), opts, func() (int, error) { calls++; return 0, nil }); err == nil || calls != 0 {
+		t.Fatalf("err = %v, calls = %d; want aborted", err, calls)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{Backoff: time.Second, MaxBackoff: 3 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for i, w := range want {
+		if got := p.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+	if got := p.backoff(80); got != 3*time.Second {
+		t.Errorf("overflowed backoff = %s, want cap", got)
+	}
+}
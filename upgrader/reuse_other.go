@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly || windows)
+
+package upgrader
+
+import (
+	"syscall"
+)
+
+// reuseControl 当前平台不支持地址复用，进程内升级时新监听器会绑定失败并保留旧监听器
+var reuseControl func(network, address string, c syscall.RawConn) error
@@ -0,0 +1,19 @@
+//go:build windows
+
+package upgrader
+
+import (
+	"syscall"
+)
+
+// reuseControl 开启 SO_REUSEADDR，Windows 上允许新监听器绑定仍被占用的地址
+func reuseControl(network, address string, c syscall.RawConn) error {
+	var opErr error
+	err := c.Control(func(fd uintptr) {
+		opErr = syscall.SetsockoptInt(syscall.Handle(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+	})
+	if err != nil {
+		return err
+	}
+	return opErr
+}
@@ -1,33 +1,145 @@
 package upgrader
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/cloudflare/tableflip"
 	"go.uber.org/zap"
 )
 
+// DefaultShutdownTimeout 默认优雅关闭超时时间
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ErrUpgradeInProgress 已有升级正在进行或已完成
+var ErrUpgradeInProgress = errors.New("upgrade in progress")
+
+// Strategy 升级方式
+type Strategy string
+
+const (
+	// StrategyTableflip 基于 tableflip 的升级，平台不支持时退化为 StrategyInProcess
+	StrategyTableflip Strategy = "tableflip"
+	// StrategyGraceful 基于 GracefulUpgrader 的升级，支持 ReloadOptions 与 StableWindow
+	StrategyGraceful Strategy = "graceful"
+	// StrategyInProcess 进程内重新监听，不切换可执行文件，适用于不支持文件描述符继承的平台
+	StrategyInProcess Strategy = "inprocess"
+)
+
+// Options 升级器配置
+type Options struct {
+	// Strategy 升级方式，为空时使用 StrategyTableflip
+	Strategy Strategy
+	// ShutdownTimeout 优雅关闭超时时间，为 0 时使用 DefaultShutdownTimeout
+	ShutdownTimeout time.Duration
+	// UpgradeTimeout 升级时等待新进程就绪的最长时间，超时后终止新进程，为 0 时使用 tableflip.DefaultUpgradeTimeout
+	UpgradeTimeout time.Duration
+	// PIDFile 进程就绪后写入 PID 的文件，升级时由新进程原子地覆盖，为空时不写入
+	PIDFile string
+	// Retry 升级失败时的重试策略
+	Retry RetryPolicy
+	// OnFailure 每次升级尝试失败时调用，可用于计数与告警
+	OnFailure func(attempt int, err error)
+	// OnSuccess 升级成功时调用，childPID 为新进程 PID，无法获取时为 0
+	OnSuccess func(childPID int)
+	// Signals 触发升级的信号，为 nil 时使用 SIGHUP，空切片表示不监听
+	Signals []os.Signal
+	// BeforeUpgrade 启动新进程之前调用，返回错误时取消本次升级
+	BeforeUpgrade func() error
+	// AfterUpgrade 新进程就绪之后、当前进程开始关闭之前调用
+	AfterUpgrade func()
+	// Reload 每次启动新进程前调整其启动参数，仅 StrategyGraceful 支持
+	Reload func(opts *ReloadOptions) error
+}
+
+func (o Options) shutdownTimeout() time.Duration {
+	if o.ShutdownTimeout <= 0 {
+		return DefaultShutdownTimeout
+	}
+	return o.ShutdownTimeout
+}
+
+func (o Options) signals() []os.Signal {
+	if o.Signals == nil {
+		return []os.Signal{syscall.SIGHUP}
+	}
+	return o.Signals
+}
+
+func (o Options) upgradeTimeout() time.Duration {
+	if o.UpgradeTimeout <= 0 {
+		return tableflip.DefaultUpgradeTimeout
+	}
+	return o.UpgradeTimeout
+}
+
 // Upgrader 优雅重启接口
 type Upgrader interface {
+	Handoff
 	Listen(network, addr string) (net.Listener, error)
+	// ListenPacket 创建或继承数据报套接字（如 UDP），升级时传递给新进程
+	ListenPacket(network, addr string) (net.PacketConn, error)
 	Ready() error
+	// HasParent 当前进程是否由升级启动
+	HasParent() bool
 	Exit() <-chan struct{}
 	Stop()
+	// Upgrade 立即执行一次升级，与收到 SIGHUP 的效果相同
+	Upgrade() error
+	// UpgradeBinary 升级到 path 指定的可执行文件，用于新版本与旧版本并存的部署方式
+	UpgradeBinary(path string) error
 	WatchSignal()
+	ShutdownTimeout() time.Duration
 }
 
 type upgrader struct {
 	upg    *tableflip.Upgrader
 	logger *zap.Logger
+	opts   Options
+
+	mu    sync.Mutex
+	state func() ([]byte, error)
+	// upgrading 防止信号、管理接口与控制套接字同时触发升级，同时保护对 os.Args[0] 的修改
+	upgrading atomic.Bool
+}
+
+var (
+	_ Upgrader = (*upgrader)(nil)
+	_ Upgrader = (*GracefulUpgrader)(nil)
+	_ Upgrader = (*fallback)(nil)
+)
+
+// New 按 Options.Strategy 创建升级器
+func New(logger *zap.Logger, opts Options) (Upgrader, error) {
+	switch opts.Strategy {
+	case "", StrategyTableflip:
+		return newTableflip(logger, opts)
+	case StrategyGraceful:
+		return NewGracefulUpgrader(logger, opts), nil
+	case StrategyInProcess:
+		return newFallback(logger, opts), nil
+	default:
+		return nil, fmt.Errorf("unknown upgrade strategy %q", opts.Strategy)
+	}
 }
 
-// New 创建新的升级器
-func New(logger *zap.Logger) (Upgrader, error) {
-	upg, err := tableflip.New(tableflip.Options{})
+// newTableflip 创建基于 tableflip 的升级器，平台不支持时退化为进程内重启
+func newTableflip(logger *zap.Logger, opts Options) (Upgrader, error) {
+	upg, err := tableflip.New(tableflip.Options{
+		UpgradeTimeout: opts.UpgradeTimeout,
+		PIDFile:        opts.PIDFile,
+	})
+	if errors.Is(err, tableflip.ErrNotSupported) {
+		logger.Warn("Graceful upgrade is not supported on this platform, falling back to in-process restart")
+		return newFallback(logger, opts), nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create upgrader: %w", err)
 	}
@@ -35,6 +147,7 @@ func New(logger *zap.Logger) (Upgrader, error) {
 	return &upgrader{
 		upg:    upg,
 		logger: logger,
+		opts:   opts,
 	}, nil
 }
 
@@ -46,19 +159,104 @@ func (u *upgrader) Listen(network, addr string) (net.Listener, error) {
 	return ln, nil
 }
 
+func (u *upgrader) ListenPacket(network, addr string) (net.PacketConn, error) {
+	conn, err := u.upg.Fds.ListenPacket(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create packet conn: %w", err)
+	}
+	return conn, nil
+}
+
 func (u *upgrader) WatchSignal() {
+	if len(u.opts.signals()) == 0 {
+		return
+	}
 	go func() {
 		sig := make(chan os.Signal, 1)
-		signal.Notify(sig, syscall.SIGHUP)
+		signal.Notify(sig, u.opts.signals()...)
 		for range sig {
-			err := u.upg.Upgrade()
-			if err != nil {
+			if err := u.Upgrade(); err != nil {
 				u.logger.Error("Upgrade failed", zap.Error(err))
 			}
 		}
 	}()
 }
 
+func (u *upgrader) Upgrade() error {
+	if !u.upgrading.CompareAndSwap(false, true) {
+		return ErrUpgradeInProgress
+	}
+	defer u.upgrading.Store(false)
+	return u.upgrade()
+}
+
+func (u *upgrader) upgrade() error {
+	return retryUpgrade(u.logger, u.opts, func() (int, error) {
+		if err := u.exportState(); err != nil {
+			return 0, err
+		}
+		return 0, u.upg.Upgrade()
+	})
+}
+
+// UpgradeBinary tableflip 以 os.Args[0] 启动新进程，升级期间将其替换为新的可执行文件路径
+func (u *upgrader) UpgradeBinary(path string) error {
+	if !u.upgrading.CompareAndSwap(false, true) {
+		return ErrUpgradeInProgress
+	}
+	defer u.upgrading.Store(false)
+
+	orig := os.Args[0]
+	os.Args[0] = path
+	err := u.upgrade()
+	if err != nil {
+		os.Args[0] = orig
+	}
+	return err
+}
+
+func (u *upgrader) AddFile(name string, f *os.File) error {
+	return u.upg.Fds.AddFile(name, f)
+}
+
+func (u *upgrader) File(name string) (*os.File, error) {
+	return u.upg.Fds.File(name)
+}
+
+func (u *upgrader) SetState(fn func() ([]byte, error)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.state = fn
+}
+
+func (u *upgrader) State() ([]byte, error) {
+	f, err := u.upg.Fds.File(stateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inherit state: %w", err)
+	}
+	return readState(f)
+}
+
+// exportState 调用状态导出函数并登记状态文件
+func (u *upgrader) exportState() error {
+	u.mu.Lock()
+	fn := u.state
+	u.mu.Unlock()
+	if fn == nil {
+		return nil
+	}
+	f, err := stateFile(fn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return u.upg.Fds.AddFile(stateName, f)
+}
+
+func (u *upgrader) HasParent() bool {
+	return u.upg.HasParent()
+}
+
 func (u *upgrader) Ready() error {
 	return u.upg.Ready()
 }
@@ -70,3 +268,7 @@ func (u *upgrader) Exit() <-chan struct{} {
 func (u *upgrader) Stop() {
 	u.upg.Stop()
 }
+
+func (u *upgrader) ShutdownTimeout() time.Duration {
+	return u.opts.shutdownTimeout()
+}
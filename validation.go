@@ -0,0 +1,115 @@
+package ginx
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entrans "github.com/go-playground/validator/v10/translations/en"
+	zhtrans "github.com/go-playground/validator/v10/translations/zh"
+)
+
+var (
+	validatorOnce sync.Once
+	translators   *ut.UniversalTranslator
+)
+
+// setupValidator 为 gin 的校验器注册中英文翻译，并使用 json 标签作为字段名
+func setupValidator() {
+	validatorOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			for _, tag := range []string{"json", "form", "uri"} {
+				name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return f.Name
+		})
+
+		enLocale := en.New()
+		translators = ut.New(enLocale, enLocale, zh.New())
+		if trans, ok := translators.GetTranslator("en"); ok {
+			_ = entrans.RegisterDefaultTranslations(v, trans)
+		}
+		if trans, ok := translators.GetTranslator("zh"); ok {
+			_ = zhtrans.RegisterDefaultTranslations(v, trans)
+		}
+	})
+}
+
+// RegisterTranslation 为指定语言注册额外的校验翻译，如自定义校验规则的提示
+func RegisterTranslation(locale string, register func(v *validator.Validate, trans ut.Translator) error) error {
+	setupValidator()
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok || translators == nil {
+		return errors.New("validator engine is not go-playground/validator")
+	}
+	trans, found := translators.GetTranslator(locale)
+	if !found {
+		return errors.New("unsupported locale " + locale)
+	}
+	return register(v, trans)
+}
+
+// translatorFor 根据 Accept-Language 选择翻译器，默认英文
+func translatorFor(c *gin.Context) ut.Translator {
+	setupValidator()
+	if translators == nil {
+		return nil
+	}
+
+	var locales []string
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		// zh-CN -> zh_CN, zh
+		tag = strings.ReplaceAll(strings.ToLower(tag), "-", "_")
+		locales = append(locales, tag)
+		if base, _, ok := strings.Cut(tag, "_"); ok {
+			locales = append(locales, base)
+		}
+	}
+	trans, _ := translators.FindTranslator(locales...)
+	return trans
+}
+
+// localizeError 返回字段校验提示已翻译为客户端语言的副本，无需翻译时原样返回
+func localizeError(c *gin.Context, e *Error) *Error {
+	var ve validator.ValidationErrors
+	if !errors.As(e.Err, &ve) {
+		return e
+	}
+	fields, ok := e.Details.([]FieldError)
+	if !ok || len(fields) != len(ve) {
+		return e
+	}
+	trans := translatorFor(c)
+	if trans == nil {
+		return e
+	}
+
+	localized := make([]FieldError, len(fields))
+	for i, fe := range ve {
+		localized[i] = fields[i]
+		localized[i].Message = fe.Translate(trans)
+	}
+	return e.WithDetails(localized)
+}
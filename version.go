@@ -0,0 +1,131 @@
+package ginx
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gaoxin19/ginx/config"
+)
+
+// DefaultAPIPrefix 未配置 API.Prefix 时版本路由组的前缀
+const DefaultAPIPrefix = "/api"
+
+// Version 返回挂载在 /api/<version> 下的路由组；版本在 API.Deprecated 中时，
+// 响应会带上 Deprecation、Sunset 与 Link 头
+func (e *Engine) Version(version string, handlers ...gin.HandlerFunc) *gin.RouterGroup {
+	api := e.options.API
+	prefix := DefaultAPIPrefix
+	if api != nil && api.Prefix != "" {
+		prefix = api.Prefix
+	}
+
+	e.hooksMu.Lock()
+	if e.versions == nil {
+		e.versions = make(map[string]bool)
+	}
+	e.versions[version] = true
+	e.hooksMu.Unlock()
+
+	if api != nil {
+		if d, ok := api.Deprecated[version]; ok {
+			handlers = append([]gin.HandlerFunc{deprecationHeaders(d)}, handlers...)
+		}
+	}
+	return e.Group(path.Join(prefix, version), handlers...)
+}
+
+// deprecationHeaders 按 RFC 9745 与 RFC 8594 输出弃用与下线信息
+func deprecationHeaders(d config.DeprecationOptions) gin.HandlerFunc {
+	deprecation := "true"
+	if !d.Date.IsZero() {
+		deprecation = "@" + strconv.FormatInt(d.Date.Unix(), 10)
+	}
+	var sunset string
+	if !d.Sunset.IsZero() {
+		sunset = d.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("Deprecation", deprecation)
+		if sunset != "" {
+			h.Set("Sunset", sunset)
+		}
+		if d.Link != "" {
+			h.Add("Link", "<"+d.Link+`>; rel="deprecation"`)
+		}
+		c.Next()
+	}
+}
+
+// negotiateVersion 对未带版本前缀的请求，按 API.Header 或 API.Query 指定的版本改写到对应的版本路由；
+// 未注册的版本以及能匹配到非版本路由的请求（如 /healthz）不改写，路由需在服务启动前注册
+func (e *Engine) negotiateVersion(next http.Handler) http.Handler {
+	api := e.options.API
+	prefix := DefaultAPIPrefix
+	if api.Prefix != "" {
+		prefix = api.Prefix
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	var (
+		once   sync.Once
+		routes gin.RoutesInfo
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == prefix || strings.HasPrefix(r.URL.Path, prefix+"/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		once.Do(func() { routes = e.Routes() })
+		for _, route := range routes {
+			if route.Method == r.Method && matchRoute(route.Path, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		var version string
+		if api.Header != "" {
+			version = r.Header.Get(api.Header)
+		}
+		if version == "" && api.Query != "" {
+			version = r.URL.Query().Get(api.Query)
+		}
+		if version != "" && e.hasVersion(version) {
+			r.URL.Path = prefix + "/" + version + r.URL.Path
+			r.URL.RawPath = ""
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// matchRoute 判断 path 是否匹配 gin 路由模式，:name 匹配一段，*name 匹配剩余部分
+func matchRoute(pattern, path string) bool {
+	for {
+		pattern, path = strings.TrimPrefix(pattern, "/"), strings.TrimPrefix(path, "/")
+		if strings.HasPrefix(pattern, "*") {
+			return true
+		}
+		if pattern == "" || path == "" {
+			return pattern == path
+		}
+		ps, pr, _ := strings.Cut(pattern, "/")
+		ss, sr, _ := strings.Cut(path, "/")
+		if !strings.HasPrefix(ps, ":") && ps != ss {
+			return false
+		}
+		pattern, path = pr, sr
+	}
+}
+
+func (e *Engine) hasVersion(version string) bool {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	return e.versions[version]
+}
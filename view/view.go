@@ -0,0 +1,193 @@
+// Package view 提供支持布局、局部模板、embed.FS 加载与开发模式热加载的 HTML 模板引擎。
+//
+// 目录约定（均可配置）：
+//
+//	layouts/base.html     布局，通过 {{block "content" .}}{{end}} 引用页面内容
+//	partials/nav.html     局部模板，通过 {{template "nav" .}} 引用
+//	pages/users/show.html 页面，通过 {{define "content"}}...{{end}} 填充布局
+//
+// 通过 engine.HTMLRender = v 接入 gin，之后 c.HTML(200, "users/show", data) 使用默认布局渲染，
+// c.HTML(200, "admin:users/show", data) 使用 admin 布局，c.HTML(200, ":users/show", data) 不使用布局，直接渲染页面中 define 之外的内容。
+package view
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// Options 模板配置
+type Options struct {
+	// FS 模板所在的文件系统，可以是 embed.FS 或 os.DirFS
+	FS fs.FS
+	// LayoutsDir 布局目录，默认 layouts
+	LayoutsDir string
+	// PartialsDir 局部模板目录，默认 partials
+	PartialsDir string
+	// PagesDir 页面目录，默认 pages
+	PagesDir string
+	// Extension 模板文件扩展名，默认 .html
+	Extension string
+	// DefaultLayout 默认布局名，为空时不使用布局
+	DefaultLayout string
+	// Funcs 模板函数
+	Funcs template.FuncMap
+	// Delims 模板分隔符，默认 {{ }}
+	Delims render.Delims
+	// Reload 为 true 时每次渲染都重新解析模板，用于开发模式
+	Reload bool
+}
+
+// View 模板引擎，实现 gin 的 render.HTMLRender
+type View struct {
+	opts Options
+
+	mu    sync.RWMutex
+	pages map[string]*template.Template
+}
+
+// New 创建模板引擎并解析所有模板
+func New(opts Options) (*View, error) {
+	if opts.FS == nil {
+		return nil, errors.New("view: FS is required")
+	}
+	if opts.LayoutsDir == "" {
+		opts.LayoutsDir = "layouts"
+	}
+	if opts.PartialsDir == "" {
+		opts.PartialsDir = "partials"
+	}
+	if opts.PagesDir == "" {
+		opts.PagesDir = "pages"
+	}
+	if opts.Extension == "" {
+		opts.Extension = ".html"
+	}
+
+	v := &View{opts: opts}
+	if err := v.Load(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Load 重新解析所有模板，解析失败时保留原有模板
+func (v *View) Load() error {
+	base := template.New("").Delims(v.opts.Delims.Left, v.opts.Delims.Right).Funcs(v.opts.Funcs)
+	for _, dir := range []string{v.opts.LayoutsDir, v.opts.PartialsDir} {
+		if err := v.parseDir(base, dir, nil); err != nil {
+			return err
+		}
+	}
+
+	pages := make(map[string]*template.Template)
+	err := v.parseDir(nil, v.opts.PagesDir, func(name, content string) error {
+		t, err := base.Clone()
+		if err != nil {
+			return err
+		}
+		if _, err := t.New(name).Parse(content); err != nil {
+			return fmt.Errorf("failed to parse page %s: %w", name, err)
+		}
+		pages[name] = t
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.pages = pages
+	v.mu.Unlock()
+	return nil
+}
+
+// parseDir 遍历目录中的模板文件，fn 为空时解析到 t 中
+func (v *View) parseDir(t *template.Template, dir string, fn func(name, content string) error) error {
+	err := fs.WalkDir(v.opts.FS, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != v.opts.Extension {
+			return nil
+		}
+		b, err := fs.ReadFile(v.opts.FS, p)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(p, dir+"/"), v.opts.Extension)
+		if fn != nil {
+			return fn(name, string(b))
+		}
+		if _, err := t.New(name).Parse(string(b)); err != nil {
+			return fmt.Errorf("failed to parse template %s: %w", p, err)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to load templates from %s: %w", dir, err)
+	}
+	return nil
+}
+
+// Execute 渲染页面，name 的格式为 [layout:]page
+func (v *View) Execute(w io.Writer, name string, data any) error {
+	if v.opts.Reload {
+		if err := v.Load(); err != nil {
+			return err
+		}
+	}
+
+	layout, page := v.opts.DefaultLayout, name
+	if l, p, ok := strings.Cut(name, ":"); ok {
+		layout, page = l, p
+	}
+
+	v.mu.RLock()
+	t, ok := v.pages[page]
+	v.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("view: page %q not found", page)
+	}
+	if layout == "" {
+		return t.ExecuteTemplate(w, page, data)
+	}
+	return t.ExecuteTemplate(w, layout, data)
+}
+
+// Instance 实现 render.HTMLRender
+func (v *View) Instance(name string, data any) render.Render {
+	return &html{view: v, name: name, data: data}
+}
+
+type html struct {
+	view *View
+	name string
+	data any
+}
+
+// Render 先渲染到缓冲区，避免模板执行出错时写出不完整的页面
+func (r *html) Render(w http.ResponseWriter) error {
+	var buf bytes.Buffer
+	if err := r.view.Execute(&buf, r.name, r.data); err != nil {
+		return err
+	}
+	r.WriteContentType(w)
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func (r *html) WriteContentType(w http.ResponseWriter) {
+	if h := w.Header(); len(h["Content-Type"]) == 0 {
+		h["Content-Type"] = []string{"text/html; charset=utf-8"}
+	}
+}
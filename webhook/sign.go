@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 请求头
+const (
+	HeaderID        = "Webhook-Id"
+	HeaderEvent     = "Webhook-Event"
+	HeaderTimestamp = "Webhook-Timestamp"
+	// HeaderSignature 格式为 t=<unix 秒>,v1=<hex>，签名为 HMAC-SHA256(secret, "<t>.<payload>")
+	HeaderSignature = "Webhook-Signature"
+)
+
+// DefaultTolerance Verify 允许的签名时间偏差
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	// ErrInvalidSignature 签名格式错误或不匹配
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrSignatureExpired 签名时间超出允许的偏差，可能是重放请求
+	ErrSignatureExpired = errors.New("webhook signature expired")
+)
+
+// Sign 返回 HeaderSignature 的值，时间戳参与签名以防止重放
+func Sign(secret string, t time.Time, payload []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + signature(secret, ts, payload)
+}
+
+// Verify 供接收方校验 HeaderSignature，tolerance 为 0 时使用 DefaultTolerance；
+// 轮换密钥期间可对新旧密钥分别校验
+func Verify(secret, header string, payload []byte, tolerance time.Duration) error {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	var ts string
+	var sigs []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return ErrInvalidSignature
+	}
+	if d := time.Since(time.Unix(sec, 0)); d > tolerance || d < -tolerance {
+		return ErrSignatureExpired
+	}
+
+	expected := signature(secret, ts, payload)
+	for _, sig := range sigs {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func signature(secret, ts string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte{'.'})
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
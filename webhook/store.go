@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store 投递记录的持久化，多实例共用同一存储时由 Claim 保证同一投递不会被并发发送
+type Store interface {
+	// Create 保存新的投递
+	Create(ctx context.Context, d *Delivery) error
+	// Claim 取出 NextAttempt 不晚于 now 的待投递记录，最多 limit 条，
+	// 并原子地将其 NextAttempt 推迟到 now+lease，使其他实例在租期内不会重复取出
+	Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*Delivery, error)
+	// Update 保存投递状态
+	Update(ctx context.Context, d *Delivery) error
+	// AddAttempt 记录一次投递尝试
+	AddAttempt(ctx context.Context, a *Attempt) error
+	// Get 返回投递记录
+	Get(ctx context.Context, id string) (*Delivery, error)
+	// Attempts 按时间顺序返回投递的所有尝试
+	Attempts(ctx context.Context, id string) ([]*Attempt, error)
+}
+
+// MemoryStore 进程内存储，进程退出后未完成的投递会丢失，适用于单实例或开发环境
+type MemoryStore struct {
+	mu         sync.Mutex
+	deliveries map[string]*Delivery
+	attempts   map[string][]*Attempt
+}
+
+// NewMemoryStore 创建进程内存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		deliveries: map[string]*Delivery{},
+		attempts:   map[string][]*Attempt{},
+	}
+}
+
+func (s *MemoryStore) Create(_ context.Context, d *Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.deliveries[d.ID]; ok {
+		return fmt.Errorf("webhook delivery %s already exists", d.ID)
+	}
+	cp := *d
+	s.deliveries[d.ID] = &cp
+	return nil
+}
+
+func (s *MemoryStore) Claim(_ context.Context, now time.Time, limit int, lease time.Duration) ([]*Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []*Delivery
+	for _, d := range s.deliveries {
+		if d.Status == StatusPending && !d.NextAttempt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttempt.Before(due[j].NextAttempt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	out := make([]*Delivery, len(due))
+	for i, d := range due {
+		d.NextAttempt = now.Add(lease)
+		cp := *d
+		out[i] = &cp
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) Update(_ context.Context, d *Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.deliveries[d.ID]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, d.ID)
+	}
+	cp := *d
+	s.deliveries[d.ID] = &cp
+	return nil
+}
+
+func (s *MemoryStore) AddAttempt(_ context.Context, a *Attempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp := *a
+	s.attempts[a.DeliveryID] = append(s.attempts[a.DeliveryID], &cp)
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deliveries[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	cp := *d
+	return &cp, nil
+}
+
+func (s *MemoryStore) Attempts(_ context.Context, id string) ([]*Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*Attempt, len(s.attempts[id]))
+	for i, a := range s.attempts[id] {
+		cp := *a
+		out[i] = &cp
+	}
+	return out, nil
+}
@@ -0,0 +1,367 @@
+// Package webhook 提供可靠的出站 Webhook 投递。
+//
+// Send 将投递写入 Store 后立即返回，Dispatcher 在后台发送：请求体以 HMAC-SHA256 签名（接收方使用 Verify 校验），
+// 失败时按指数退避重试，每次尝试的状态码、耗时与错误都记录在 Store 中，可通过 Attempts 查询。
+// Dispatcher 实现 ginx.Runner，通过 engine.AddRunner("webhooks", dispatcher) 接入 ginx：
+// 服务就绪后开始投递，关闭时停止取出新的投递并等待发送中的请求完成，未完成的投递在下次启动后继续。
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/metrics"
+)
+
+// 默认配置
+const (
+	DefaultMaxAttempts  = 8
+	DefaultBackoff      = 10 * time.Second
+	DefaultMaxBackoff   = 6 * time.Hour
+	DefaultTimeout      = 10 * time.Second
+	DefaultPollInterval = time.Second
+	DefaultWorkers      = 8
+)
+
+// maxLoggedBody 记录到 Attempt 的响应体长度上限
+const maxLoggedBody = 1024
+
+// ErrNotFound 投递记录不存在
+var ErrNotFound = errors.New("webhook delivery not found")
+
+// Status 投递状态
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Message 待投递的消息
+type Message struct {
+	// URL 接收方地址
+	URL string
+	// Secret 签名密钥，为空时不签名
+	Secret string
+	// Event 事件名称，通过 HeaderEvent 发送
+	Event string
+	// Payload 请求体，以 application/json 发送
+	Payload []byte
+	// Header 附加的请求头
+	Header http.Header
+}
+
+// Delivery 一次投递及其当前状态
+type Delivery struct {
+	ID          string      `json:"id"`
+	URL         string      `json:"url"`
+	Secret      string      `json:"-"`
+	Event       string      `json:"event"`
+	Payload     []byte      `json:"payload"`
+	Header      http.Header `json:"header,omitempty"`
+	Status      Status      `json:"status"`
+	Attempts    int         `json:"attempts"`
+	NextAttempt time.Time   `json:"next_attempt"`
+	LastError   string      `json:"last_error,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// Attempt 一次发送尝试的记录
+type Attempt struct {
+	DeliveryID string        `json:"delivery_id"`
+	Number     int           `json:"number"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Response   string        `json:"response,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	At         time.Time     `json:"at"`
+}
+
+// Options 投递配置
+type Options struct {
+	// Client 发送请求的客户端，可使用 ginx.NewHTTPClient 创建，默认 http.DefaultClient
+	Client *http.Client
+	// MaxAttempts 最大尝试次数，包含首次发送，默认 8
+	MaxAttempts int
+	// Backoff 首次重试前的等待时间，之后每次翻倍，默认 10s
+	Backoff time.Duration
+	// MaxBackoff 重试等待时间上限，默认 6h
+	MaxBackoff time.Duration
+	// Timeout 单次请求的超时，默认 10s
+	Timeout time.Duration
+	// PollInterval 查询到期投递的间隔，默认 1s
+	PollInterval time.Duration
+	// Workers 并发发送的请求数，默认 8
+	Workers int
+	// UserAgent 为空时使用 ginx-webhook
+	UserAgent string
+	// Logger 为空时不记录日志
+	Logger *zap.Logger
+}
+
+// Dispatcher 后台投递 Webhook
+type Dispatcher struct {
+	opts  Options
+	store Store
+	wake  chan struct{}
+
+	started atomic.Bool
+	stopped chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// New 创建 Dispatcher
+func New(store Store, opts Options) *Dispatcher {
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = "ginx-webhook"
+	}
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	return &Dispatcher{
+		opts:    opts,
+		store:   store,
+		wake:    make(chan struct{}, 1),
+		stopped: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Send 保存投递并通知后台发送，返回的投递记录可用于查询状态
+func (d *Dispatcher) Send(ctx context.Context, msg Message) (*Delivery, error) {
+	now := time.Now()
+	del := &Delivery{
+		ID:          newID(),
+		URL:         msg.URL,
+		Secret:      msg.Secret,
+		Event:       msg.Event,
+		Payload:     msg.Payload,
+		Header:      msg.Header,
+		Status:      StatusPending,
+		NextAttempt: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := d.store.Create(ctx, del); err != nil {
+		return nil, fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return del, nil
+}
+
+// Get 返回投递记录
+func (d *Dispatcher) Get(ctx context.Context, id string) (*Delivery, error) {
+	return d.store.Get(ctx, id)
+}
+
+// Attempts 返回投递的发送记录
+func (d *Dispatcher) Attempts(ctx context.Context, id string) ([]*Attempt, error) {
+	return d.store.Attempts(ctx, id)
+}
+
+// Run 持续取出到期的投递并发送，直到 Stop 被调用
+func (d *Dispatcher) Run(ctx context.Context) error {
+	if !d.started.CompareAndSwap(false, true) {
+		return errors.New("webhook dispatcher already running")
+	}
+	defer close(d.done)
+	d.opts.Logger.Info("Webhook dispatcher started", zap.Int("workers", d.opts.Workers))
+
+	sem := make(chan struct{}, d.opts.Workers)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	ticker := time.NewTicker(d.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		// 租期覆盖单次请求的超时，发送中的投递不会被其他实例重复取出
+		lease := d.opts.Timeout + time.Minute
+		var dels []*Delivery
+		if n := cap(sem) - len(sem); n > 0 {
+			var err error
+			if dels, err = d.store.Claim(ctx, time.Now(), n, lease); err != nil {
+				d.opts.Logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+			}
+		}
+		for _, del := range dels {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				d.deliver(ctx, del)
+			}()
+		}
+
+		select {
+		case <-d.stopped:
+			return nil
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// Stop 停止取出新的投递并等待发送中的请求完成，ctx 结束时返回 ctx 的错误
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	d.once.Do(func() { close(d.stopped) })
+	if !d.started.Load() {
+		return nil
+	}
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		d.opts.Logger.Warn("Webhook deliveries did not finish before shutdown timeout")
+		return fmt.Errorf("webhook: %w", ctx.Err())
+	}
+}
+
+// deliver 发送一次并保存结果
+func (d *Dispatcher) deliver(ctx context.Context, del *Delivery) {
+	logger := d.opts.Logger.With(zap.String("delivery", del.ID), zap.String("event", del.Event), zap.String("url", del.URL))
+	del.Attempts++
+	attempt, retryAfter := d.send(ctx, del)
+	metrics.WebhookDuration.Observe(attempt.Duration.Seconds())
+	if err := d.store.AddAttempt(ctx, attempt); err != nil {
+		logger.Error("Failed to save webhook attempt", zap.Error(err))
+	}
+
+	now := time.Now()
+	del.UpdatedAt = now
+	del.LastError = attempt.Error
+	switch {
+	case attempt.Error == "":
+		del.Status = StatusSucceeded
+		metrics.WebhookDeliveries.WithLabelValues("succeeded").Inc()
+		logger.Debug("Webhook delivered", zap.Int("attempt", del.Attempts), zap.Duration("latency", attempt.Duration))
+	case retryable(attempt) && del.Attempts < d.opts.MaxAttempts:
+		del.NextAttempt = now.Add(max(d.backoff(del.Attempts), retryAfter))
+		metrics.WebhookDeliveries.WithLabelValues("retried").Inc()
+		logger.Warn("Webhook delivery failed, retrying",
+			zap.Int("attempt", del.Attempts),
+			zap.Time("next_attempt", del.NextAttempt),
+			zap.String("error", attempt.Error),
+		)
+	default:
+		del.Status = StatusFailed
+		metrics.WebhookDeliveries.WithLabelValues("failed").Inc()
+		logger.Error("Webhook delivery failed", zap.Int("attempts", del.Attempts), zap.String("error", attempt.Error))
+	}
+
+	// 关闭超时后 ctx 已取消，仍需保存结果
+	if err := d.store.Update(context.WithoutCancel(ctx), del); err != nil {
+		logger.Error("Failed to save webhook delivery", zap.Error(err))
+	}
+}
+
+// send 发送一次请求，429 与 503 时同时返回 Retry-After 指定的等待时间
+func (d *Dispatcher) send(ctx context.Context, del *Delivery) (*Attempt, time.Duration) {
+	start := time.Now()
+	a := &Attempt{DeliveryID: del.ID, Number: del.Attempts, At: start}
+	defer func() { a.Duration = time.Since(start) }()
+
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, del.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		a.Error = err.Error()
+		return a, 0
+	}
+	for k, v := range del.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", d.opts.UserAgent)
+	req.Header.Set(HeaderID, del.ID)
+	req.Header.Set(HeaderEvent, del.Event)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(start.Unix(), 10))
+	if del.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(del.Secret, start, del.Payload))
+	}
+
+	resp, err := d.opts.Client.Do(req)
+	if err != nil {
+		a.Error = err.Error()
+		return a, 0
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBody))
+	a.StatusCode = resp.StatusCode
+	a.Response = string(body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return a, 0
+	}
+	a.Error = "unexpected status " + resp.Status
+
+	var retryAfter time.Duration
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if sec, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && sec > 0 {
+			retryAfter = min(time.Duration(sec)*time.Second, d.opts.MaxBackoff)
+		}
+	}
+	return a, retryAfter
+}
+
+// retryable 连接错误、408、429 与 5xx 重试，其他状态码视为接收方拒绝
+func retryable(a *Attempt) bool {
+	return a.StatusCode == 0 ||
+		a.StatusCode == http.StatusRequestTimeout ||
+		a.StatusCode == http.StatusTooManyRequests ||
+		a.StatusCode >= 500
+}
+
+// backoff 第 n 次尝试失败后的等待时间
+func (d *Dispatcher) backoff(n int) time.Duration {
+	b := float64(d.opts.Backoff) * math.Pow(2, float64(n-1))
+	if b > float64(d.opts.MaxBackoff) {
+		return d.opts.MaxBackoff
+	}
+	return time.Duration(b)
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
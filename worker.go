@@ -0,0 +1,11 @@
+package ginx
+
+import (
+	"github.com/gaoxin19/ginx/worker"
+)
+
+// UseWorkers 随服务启动任务池，关闭时在 HTTP 服务停止、不再有新任务提交之后排空队列
+func (e *Engine) UseWorkers(p *worker.Pool) {
+	e.RegisterOnStart("worker-"+p.Name(), p.Start)
+	e.RegisterOnShutdown("worker-"+p.Name(), p.Shutdown)
+}
@@ -0,0 +1,291 @@
+// Package worker 提供与服务生命周期绑定的后台任务池。
+//
+// 处理函数通过 Submit 提交任务，任务在独立的 goroutine 中执行，失败时按退避策略重试，panic 会被恢复并记录。
+// 通过 engine.UseWorkers(pool) 接入 ginx：服务启动时启动 worker，关闭时在 HTTP 服务停止之后排空队列，
+// 避免进程退出时丢失已提交的任务。
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/gaoxin19/ginx/metrics"
+)
+
+// 默认配置
+const (
+	DefaultQueueSize  = 1024
+	DefaultBackoff    = 100 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+var (
+	// ErrQueueFull 队列已满
+	ErrQueueFull = errors.New("worker queue is full")
+	// ErrClosed 任务池已关闭
+	ErrClosed = errors.New("worker pool is closed")
+)
+
+// Job 后台任务，ctx 在关闭超时后被取消
+type Job func(ctx context.Context) error
+
+// Options 任务池配置
+type Options struct {
+	// Name 任务池名称，用于日志与指标，默认 default
+	Name string
+	// Workers 并发执行的任务数，默认 CPU 核数
+	Workers int
+	// QueueSize 等待执行的任务上限，默认 1024，队列满时 Submit 返回 ErrQueueFull
+	QueueSize int
+	// MaxRetries 任务失败后的默认重试次数
+	MaxRetries int
+	// Backoff 首次重试前的等待时间，之后每次翻倍，默认 100ms
+	Backoff time.Duration
+	// MaxBackoff 重试等待时间上限，默认 30s
+	MaxBackoff time.Duration
+	// Timeout 单次执行的默认超时，为 0 时不限制
+	Timeout time.Duration
+	// Logger 为空时不记录日志
+	Logger *zap.Logger
+}
+
+// JobOption 单个任务的选项
+type JobOption func(*task)
+
+// WithRetries 设置任务的重试次数
+func WithRetries(n int) JobOption {
+	return func(t *task) {
+		t.retries = n
+	}
+}
+
+// WithTimeout 设置任务单次执行的超时
+func WithTimeout(d time.Duration) JobOption {
+	return func(t *task) {
+		t.timeout = d
+	}
+}
+
+// Stats 任务池状态
+type Stats struct {
+	Queued    int   `json:"queued"`
+	Running   int64 `json:"running"`
+	Succeeded int64 `json:"succeeded"`
+	Failed    int64 `json:"failed"`
+}
+
+type task struct {
+	name    string
+	job     Job
+	retries int
+	timeout time.Duration
+}
+
+// Pool 后台任务池
+type Pool struct {
+	opts  Options
+	queue chan *task
+
+	// mu 保护 closed，避免向已关闭的队列发送
+	mu     sync.RWMutex
+	closed bool
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	start   sync.Once
+	workers sync.WaitGroup
+
+	running, succeeded, failed atomic.Int64
+}
+
+// New 创建任务池，需调用 Start 后才会执行任务
+func New(opts Options) *Pool {
+	if opts.Name == "" {
+		opts.Name = "default"
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	p := &Pool{
+		opts:  opts,
+		queue: make(chan *task, opts.QueueSize),
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	return p
+}
+
+// Start 启动 worker，可重复调用
+func (p *Pool) Start(context.Context) error {
+	p.start.Do(func() {
+		p.workers.Add(p.opts.Workers)
+		for range p.opts.Workers {
+			go p.work()
+		}
+		p.opts.Logger.Info("Worker pool started", zap.String("pool", p.opts.Name), zap.Int("workers", p.opts.Workers))
+	})
+	return nil
+}
+
+// Submit 提交任务，不等待执行；队列满时返回 ErrQueueFull，关闭后返回 ErrClosed
+func (p *Pool) Submit(name string, job Job, opts ...JobOption) error {
+	t := &task{name: name, job: job, retries: p.opts.MaxRetries, timeout: p.opts.Timeout}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrClosed
+	}
+	select {
+	case p.queue <- t:
+		metrics.WorkerQueueDepth.WithLabelValues(p.opts.Name).Inc()
+		return nil
+	default:
+		metrics.WorkerJobs.WithLabelValues(p.opts.Name, "rejected").Inc()
+		return ErrQueueFull
+	}
+}
+
+// Shutdown 停止接收任务并等待队列中的任务执行完成；ctx 结束时取消仍在执行的任务并返回 ctx 的错误
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+	// 未启动时也需要排空队列
+	p.Start(ctx)
+
+	if n := len(p.queue) + int(p.running.Load()); n > 0 {
+		p.opts.Logger.Info("Draining worker pool", zap.String("pool", p.opts.Name), zap.Int("jobs", n))
+	}
+	done := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		p.opts.Logger.Warn("Worker pool drain timed out, cancelling jobs",
+			zap.String("pool", p.opts.Name),
+			zap.Int("queued", len(p.queue)),
+			zap.Int64("running", p.running.Load()),
+		)
+		return fmt.Errorf("worker pool %s: %w", p.opts.Name, ctx.Err())
+	}
+}
+
+// Name 返回任务池名称
+func (p *Pool) Name() string {
+	return p.opts.Name
+}
+
+// Stats 返回任务池状态
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Queued:    len(p.queue),
+		Running:   p.running.Load(),
+		Succeeded: p.succeeded.Load(),
+		Failed:    p.failed.Load(),
+	}
+}
+
+func (p *Pool) work() {
+	defer p.workers.Done()
+	for t := range p.queue {
+		metrics.WorkerQueueDepth.WithLabelValues(p.opts.Name).Dec()
+		p.running.Add(1)
+		p.run(t)
+		p.running.Add(-1)
+	}
+}
+
+// run 执行任务，失败时按退避策略重试，关闭超时后不再重试
+func (p *Pool) run(t *task) {
+	logger := p.opts.Logger.With(zap.String("pool", p.opts.Name), zap.String("job", t.name))
+	start := time.Now()
+
+	var err error
+	for attempt := 0; attempt <= t.retries; attempt++ {
+		if attempt > 0 {
+			backoff := p.opts.Backoff << (attempt - 1)
+			if backoff > p.opts.MaxBackoff || backoff <= 0 {
+				backoff = p.opts.MaxBackoff
+			}
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-p.ctx.Done():
+				timer.Stop()
+				err = errors.Join(err, p.ctx.Err())
+				attempt = t.retries + 1
+				continue
+			}
+		}
+
+		if err = p.attempt(t); err == nil {
+			p.succeeded.Add(1)
+			metrics.WorkerJobs.WithLabelValues(p.opts.Name, "succeeded").Inc()
+			logger.Debug("Job completed", zap.Int("attempt", attempt+1), zap.Duration("latency", time.Since(start)))
+			return
+		}
+		if attempt < t.retries {
+			metrics.WorkerJobs.WithLabelValues(p.opts.Name, "retried").Inc()
+			logger.Warn("Job failed, retrying", zap.Int("attempt", attempt+1), zap.Error(err))
+		}
+	}
+
+	p.failed.Add(1)
+	metrics.WorkerJobs.WithLabelValues(p.opts.Name, "failed").Inc()
+	logger.Error("Job failed", zap.Int("attempts", t.retries+1), zap.Duration("latency", time.Since(start)), zap.Error(err))
+}
+
+// attempt 执行一次任务并恢复 panic
+func (p *Pool) attempt(t *task) (err error) {
+	ctx := p.ctx
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			p.opts.Logger.Error("Job panic",
+				zap.String("pool", p.opts.Name),
+				zap.String("job", t.name),
+				zap.Any("panic", r),
+				zap.ByteString("stack", debug.Stack()),
+			)
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return t.job(ctx)
+}
@@ -0,0 +1,225 @@
+// Package ws 提供 WebSocket 连接管理，并在进程关闭或升级时优雅断开连接。
+//
+// 通过 engine.RegisterOnDrain("websocket", hub.Shutdown) 接入 ginx 的关闭流程，
+// 已有连接会收到 close 帧，并在超时前等待客户端完成关闭握手。
+package ws
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// 默认配置
+const (
+	DefaultPingInterval = 30 * time.Second
+	DefaultWriteTimeout = 10 * time.Second
+)
+
+// Options Hub 配置
+type Options struct {
+	ReadBufferSize  int
+	WriteBufferSize int
+	// Subprotocols 服务端支持的子协议
+	Subprotocols []string
+	// CheckOrigin 校验 Origin，为空时要求 Origin 与 Host 一致
+	CheckOrigin func(r *http.Request) bool
+	// EnableCompression 启用 permessage-deflate
+	EnableCompression bool
+	// PingInterval 心跳间隔，默认 30s，负数关闭心跳；超过两个间隔未收到 pong 时断开
+	PingInterval time.Duration
+	// WriteTimeout 单次写入超时，默认 10s
+	WriteTimeout time.Duration
+	// CloseReason 关闭时 close 帧携带的原因
+	CloseReason string
+}
+
+// Conn 并发安全的 WebSocket 连接，写方法可在多个 goroutine 中调用，读操作仍需在单个 goroutine 中进行
+type Conn struct {
+	*websocket.Conn
+	hub     *Hub
+	writeMu sync.Mutex
+	done    chan struct{}
+	once    sync.Once
+}
+
+// WriteMessage 写出消息
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.Conn.SetWriteDeadline(time.Now().Add(c.hub.opts.WriteTimeout))
+	return c.Conn.WriteMessage(messageType, data)
+}
+
+// WriteJSON 以 JSON 写出消息
+func (c *Conn) WriteJSON(v any) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.Conn.SetWriteDeadline(time.Now().Add(c.hub.opts.WriteTimeout))
+	return c.Conn.WriteJSON(v)
+}
+
+// Done 返回连接关闭时关闭的通道
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Conn) markDone() {
+	c.once.Do(func() { close(c.done) })
+}
+
+// Hub 管理所有 WebSocket 连接
+type Hub struct {
+	opts     Options
+	upgrader websocket.Upgrader
+
+	mu      sync.Mutex
+	conns   map[*Conn]struct{}
+	closing bool
+	wg      sync.WaitGroup
+}
+
+// NewHub 创建 Hub
+func NewHub(opts Options) *Hub {
+	if opts.PingInterval == 0 {
+		opts.PingInterval = DefaultPingInterval
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = DefaultWriteTimeout
+	}
+	return &Hub{
+		opts: opts,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:    opts.ReadBufferSize,
+			WriteBufferSize:   opts.WriteBufferSize,
+			Subprotocols:      opts.Subprotocols,
+			CheckOrigin:       opts.CheckOrigin,
+			EnableCompression: opts.EnableCompression,
+		},
+		conns: make(map[*Conn]struct{}),
+	}
+}
+
+// Handle 升级连接并调用 fn，fn 负责读取消息，返回后连接被关闭；
+// Hub 关闭后新的升级请求返回 503
+func (h *Hub) Handle(c *gin.Context, fn func(*Conn)) {
+	h.mu.Lock()
+	if h.closing {
+		h.mu.Unlock()
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+	h.wg.Add(1)
+	h.mu.Unlock()
+	defer h.wg.Done()
+
+	raw, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade 已写出错误响应
+		c.Abort()
+		return
+	}
+
+	conn := &Conn{Conn: raw, hub: h, done: make(chan struct{})}
+	h.mu.Lock()
+	h.conns[conn] = struct{}{}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.conns, conn)
+		h.mu.Unlock()
+		conn.markDone()
+		raw.Close()
+	}()
+
+	if h.opts.PingInterval > 0 {
+		h.keepalive(conn)
+	}
+	fn(conn)
+}
+
+// keepalive 定时发送 ping，并在收到 pong 时延长读超时
+func (h *Hub) keepalive(conn *Conn) {
+	wait := 2 * h.opts.PingInterval
+	conn.SetReadDeadline(time.Now().Add(wait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wait))
+	})
+
+	go func() {
+		ticker := time.NewTicker(h.opts.PingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.opts.WriteTimeout)); err != nil {
+					return
+				}
+			case <-conn.done:
+				return
+			}
+		}
+	}()
+}
+
+// Broadcast 向所有连接发送消息，返回发送失败的连接数
+func (h *Hub) Broadcast(messageType int, data []byte) int {
+	failed := 0
+	for _, conn := range h.snapshot() {
+		if err := conn.WriteMessage(messageType, data); err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Len 返回当前连接数
+func (h *Hub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.conns)
+}
+
+// Shutdown 拒绝新连接，向已有连接发送 close 帧并等待客户端完成关闭握手，
+// ctx 超时后强制断开剩余连接
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closing = true
+	h.mu.Unlock()
+
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, h.opts.CloseReason)
+	deadline := time.Now().Add(h.opts.WriteTimeout)
+	for _, conn := range h.snapshot() {
+		_ = conn.WriteControl(websocket.CloseMessage, msg, deadline)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		for _, conn := range h.snapshot() {
+			conn.Conn.Close()
+		}
+		return ctx.Err()
+	}
+}
+
+func (h *Hub) snapshot() []*Conn {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	conns := make([]*Conn, 0, len(h.conns))
+	for conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	return conns
+}